@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/service"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres"
+	"github.com/ilindan-dev/shortener/internal/storage/redis"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+	"os"
+	"os/user"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage:
+  admin workspace create -name <name>
+  admin workspace list
+  admin workspace members -id <id>
+  admin workspace settings -id <id> -reuse-existing=true|false
+  admin user create -workspace <id> -email <email> [-name <name>] [-role owner|editor|viewer]
+  admin member add -workspace <id> -user <id> [-role owner|editor|viewer]
+  admin member role -workspace <id> -user <id> -role owner|editor|viewer
+  admin apikey create -name <name> [-workspace <id>] [-user <id>] [-scope read|write]
+  admin apikey list
+  admin apikey revoke -id <id>
+  admin domain add -workspace <id> -host <host>
+  admin domain list -workspace <id>
+  admin domain remove -workspace <id> -host <host>
+  admin link flag -code <short code> [-domain <host>] [-suspicious=false]
+  admin link rehash [-batch <size>]
+`
+
+// main is the entry point for the administration CLI of the shortener service.
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var (
+		keys       *service.APIKeyService
+		workspaces *service.WorkspaceService
+		moderation *service.ModerationService
+	)
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(
+			config.NewConfig,
+			logger.NewLogger,
+			func() trace.TracerProvider { return noop.NewTracerProvider() },
+			postgres.NewPool,
+			redis.NewClient,
+			redis.NewMetrics,
+			fx.Annotate(postgres.NewAPIKeyRepository, fx.As(new(repo.APIKeyRepository))),
+			// Hosts are added and removed through the cache-aside decorator so the API sees them at once.
+			fx.Annotate(postgres.NewWorkspaceRepository, fx.As(new(repo.WorkspaceRepository)), fx.ResultTags(`name:"primaryWorkspaceRepository"`)),
+			fx.Annotate(redis.NewCachedWorkspaceRepository, fx.ParamTags(`name:"primaryWorkspaceRepository"`), fx.As(new(repo.WorkspaceRepository))),
+			fx.Annotate(postgres.NewAuditRepository, fx.As(new(repo.AuditRepository))),
+			// Links are changed through the cache-aside decorator so stale entries are evicted.
+			fx.Annotate(postgres.NewURLRepository, fx.As(new(repo.URLRepository)), fx.ResultTags(`name:"primaryURLRepository"`)),
+			fx.Annotate(redis.NewCachedURLRepository, fx.ParamTags(`name:"primaryURLRepository"`), fx.As(new(repo.URLRepository))),
+			fx.Annotate(redis.NewURLCache, fx.As(new(repo.URLCache))),
+			fx.Annotate(postgres.NewTransactor, fx.As(new(repo.Transactor))),
+			service.NewAuditService,
+			service.NewDomains,
+			service.NewAPIKeyService,
+			service.NewWorkspaceService,
+			service.NewModerationService,
+		),
+		fx.Populate(&keys, &workspaces, &moderation),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
+	defer app.Stop(ctx)
+
+	// Changes made through the CLI are audited under the account running it.
+	if account, err := user.Current(); err == nil {
+		ctx = service.ContextWithOperator(ctx, account.Username)
+	}
+
+	switch args[0] + " " + args[1] {
+	case "workspace create":
+		return createWorkspace(ctx, workspaces, args[2:])
+	case "workspace list":
+		return listWorkspaces(ctx, workspaces)
+	case "workspace members":
+		return listMembers(ctx, workspaces, args[2:])
+	case "workspace settings":
+		return updateSettings(ctx, workspaces, args[2:])
+	case "user create":
+		return createUser(ctx, workspaces, args[2:])
+	case "member add":
+		return addMember(ctx, workspaces, args[2:])
+	case "member role":
+		return setMemberRole(ctx, workspaces, args[2:])
+	case "apikey create":
+		return createKey(ctx, keys, args[2:])
+	case "apikey list":
+		return listKeys(ctx, keys)
+	case "apikey revoke":
+		return revokeKey(ctx, keys, args[2:])
+	case "domain add":
+		return addDomain(ctx, workspaces, args[2:])
+	case "domain list":
+		return listDomains(ctx, workspaces, args[2:])
+	case "domain remove":
+		return removeDomain(ctx, workspaces, args[2:])
+	case "link flag":
+		return flagLink(ctx, moderation, args[2:])
+	case "link rehash":
+		return rehashLinks(ctx, moderation, args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0]+" "+args[1])
+	}
+}
+
+func createWorkspace(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("workspace create", flag.ExitOnError)
+	name := fs.String("name", "", "name of the workspace")
+	_ = fs.Parse(args)
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	workspace, err := workspaces.CreateWorkspace(ctx, *name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created workspace %d (%s).\n", workspace.ID, workspace.Name)
+	return nil
+}
+
+func listWorkspaces(ctx context.Context, workspaces *service.WorkspaceService) error {
+	list, err := workspaces.ListWorkspaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tREUSE LINKS\tCREATED")
+	for _, ws := range list {
+		fmt.Fprintf(w, "%d\t%s\t%t\t%s\n", ws.ID, ws.Name, ws.ReuseExistingLinks, ws.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func listMembers(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("workspace members", flag.ExitOnError)
+	id := fs.Int64("id", 0, "ID of the workspace")
+	_ = fs.Parse(args)
+	if *id <= 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	list, err := workspaces.ListMembers(ctx, *id)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tCREATED")
+	for _, m := range list {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", m.ID, m.Email, m.Name, m.Role, m.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func updateSettings(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("workspace settings", flag.ExitOnError)
+	id := fs.Int64("id", 0, "ID of the workspace")
+	reuse := fs.Bool("reuse-existing", false, "return an existing link to the same destination instead of creating another")
+	_ = fs.Parse(args)
+	if *id <= 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	if err := workspaces.SetReuseExistingLinks(ctx, *id, *reuse); err != nil {
+		return err
+	}
+
+	fmt.Printf("Workspace %d now reuses existing links: %t.\n", *id, *reuse)
+	return nil
+}
+
+func createUser(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ExitOnError)
+	workspaceID := fs.Int64("workspace", 0, "ID of the workspace the user joins")
+	email := fs.String("email", "", "email address of the user")
+	name := fs.String("name", "", "display name of the user")
+	role := fs.String("role", model.RoleViewer, "role in the workspace: owner, editor or viewer")
+	_ = fs.Parse(args)
+	if *workspaceID <= 0 || *email == "" {
+		return fmt.Errorf("-workspace and -email are required")
+	}
+
+	user, err := workspaces.AddUser(ctx, *workspaceID, *email, *name, *role)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created user %d (%s) in workspace %d as %s.\n", user.ID, user.Email, *workspaceID, *role)
+	return nil
+}
+
+func addMember(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("member add", flag.ExitOnError)
+	workspaceID := fs.Int64("workspace", 0, "ID of the workspace")
+	userID := fs.Int64("user", 0, "ID of the user joining the workspace")
+	role := fs.String("role", model.RoleViewer, "role in the workspace: owner, editor or viewer")
+	_ = fs.Parse(args)
+	if *workspaceID <= 0 || *userID <= 0 {
+		return fmt.Errorf("-workspace and -user are required")
+	}
+
+	if err := workspaces.AddMember(ctx, *workspaceID, *userID, *role); err != nil {
+		return err
+	}
+
+	fmt.Printf("Added user %d to workspace %d as %s.\n", *userID, *workspaceID, *role)
+	return nil
+}
+
+func setMemberRole(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("member role", flag.ExitOnError)
+	workspaceID := fs.Int64("workspace", 0, "ID of the workspace")
+	userID := fs.Int64("user", 0, "ID of the member")
+	role := fs.String("role", "", "new role: owner, editor or viewer")
+	_ = fs.Parse(args)
+	if *workspaceID <= 0 || *userID <= 0 || *role == "" {
+		return fmt.Errorf("-workspace, -user and -role are required")
+	}
+
+	if err := workspaces.SetMemberRole(ctx, *workspaceID, *userID, *role); err != nil {
+		return err
+	}
+
+	fmt.Printf("User %d is now %s of workspace %d.\n", *userID, *role, *workspaceID)
+	return nil
+}
+
+func createKey(ctx context.Context, keys *service.APIKeyService, args []string) error {
+	fs := flag.NewFlagSet("apikey create", flag.ExitOnError)
+	name := fs.String("name", "", "human-readable name of the key")
+	workspaceID := fs.Int64("workspace", model.DefaultWorkspaceID, "ID of the workspace the key acts on")
+	userID := fs.Int64("user", 0, "ID of the member the key acts on behalf of; omit for a service key")
+	scope := fs.String("scope", model.ScopeRead, "scope of the key: read or write")
+	_ = fs.Parse(args)
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	var owner *int64
+	if *userID > 0 {
+		owner = userID
+	}
+
+	key, secret, err := keys.CreateKey(ctx, *workspaceID, owner, *name, *scope)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created API key %d (%s, %s) for workspace %d.\n", key.ID, key.Name, key.Scope, key.WorkspaceID)
+	fmt.Println("Store it now, it will not be shown again:")
+	fmt.Println(secret)
+	return nil
+}
+
+func listKeys(ctx context.Context, keys *service.APIKeyService) error {
+	list, err := keys.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tWORKSPACE\tUSER\tNAME\tPREFIX\tSCOPE\tREQUESTS\tLAST USED\tCREATED\tREVOKED")
+	for _, k := range list {
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			k.ID, k.WorkspaceID, formatID(k.UserID), k.Name, k.Prefix, k.Scope, k.RequestCount,
+			formatTime(k.LastUsedAt), k.CreatedAt.Format(time.RFC3339), formatTime(k.RevokedAt))
+	}
+	return w.Flush()
+}
+
+func revokeKey(ctx context.Context, keys *service.APIKeyService, args []string) error {
+	fs := flag.NewFlagSet("apikey revoke", flag.ExitOnError)
+	id := fs.Int64("id", 0, "ID of the key to revoke")
+	_ = fs.Parse(args)
+	if *id <= 0 {
+		return fmt.Errorf("-id is required")
+	}
+
+	if err := keys.RevokeKey(ctx, *id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked API key %d.\n", *id)
+	return nil
+}
+
+func addDomain(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("domain add", flag.ExitOnError)
+	workspaceID := fs.Int64("workspace", 0, "ID of the workspace")
+	host := fs.String("host", "", "host name serving the links of the workspace, e.g. go.example.com")
+	_ = fs.Parse(args)
+	if *workspaceID <= 0 || *host == "" {
+		return fmt.Errorf("-workspace and -host are required")
+	}
+
+	domain, err := workspaces.AddDomain(ctx, *workspaceID, *host)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Links of workspace %d can now be served on %s.\n", domain.WorkspaceID, domain.Host)
+	return nil
+}
+
+func listDomains(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("domain list", flag.ExitOnError)
+	workspaceID := fs.Int64("workspace", 0, "ID of the workspace")
+	_ = fs.Parse(args)
+	if *workspaceID <= 0 {
+		return fmt.Errorf("-workspace is required")
+	}
+
+	list, err := workspaces.ListDomains(ctx, *workspaceID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tHOST\tCREATED")
+	for _, d := range list {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", d.ID, d.Host, d.CreatedAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func removeDomain(ctx context.Context, workspaces *service.WorkspaceService, args []string) error {
+	fs := flag.NewFlagSet("domain remove", flag.ExitOnError)
+	workspaceID := fs.Int64("workspace", 0, "ID of the workspace")
+	host := fs.String("host", "", "host name to remove")
+	_ = fs.Parse(args)
+	if *workspaceID <= 0 || *host == "" {
+		return fmt.Errorf("-workspace and -host are required")
+	}
+
+	if err := workspaces.RemoveDomain(ctx, *workspaceID, *host); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %s from workspace %d.\n", *host, *workspaceID)
+	return nil
+}
+
+func flagLink(ctx context.Context, moderation *service.ModerationService, args []string) error {
+	fs := flag.NewFlagSet("link flag", flag.ExitOnError)
+	code := fs.String("code", "", "short code of the link")
+	domain := fs.String("domain", "", "host the link is served on; empty for the base URL")
+	suspicious := fs.Bool("suspicious", true, "show the preview page with a warning before redirecting")
+	_ = fs.Parse(args)
+	if *code == "" {
+		return fmt.Errorf("-code is required")
+	}
+
+	link, err := moderation.SetSuspicious(ctx, *domain, *code, *suspicious)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Link %s of workspace %d is now suspicious: %t.\n", link.ShortCode, link.WorkspaceID, link.Suspicious)
+	return nil
+}
+
+func rehashLinks(ctx context.Context, moderation *service.ModerationService, args []string) error {
+	fs := flag.NewFlagSet("link rehash", flag.ExitOnError)
+	batch := fs.Int("batch", 500, "number of links read at once")
+	_ = fs.Parse(args)
+	if *batch <= 0 {
+		return fmt.Errorf("-batch must be positive")
+	}
+
+	// Rehashing reads every link, which may well take longer than the timeout of other commands.
+	report, err := moderation.RehashLinks(context.WithoutCancel(ctx), int32(*batch))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Scanned %d links: %d rehashed, %d with an invalid destination; %d marked canonical.\n",
+		report.Scanned, report.Rehashed, report.Invalid, report.Canonical)
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatID(id *int64) string {
+	if id == nil {
+		return "-"
+	}
+	return strconv.FormatInt(*id, 10)
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"github.com/ilindan-dev/shortener/internal/config"
 	deliveryHTTP "github.com/ilindan-dev/shortener/internal/delivery/http"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
 	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/metrics"
 	"github.com/ilindan-dev/shortener/internal/service"
 	"github.com/ilindan-dev/shortener/internal/storage/postgres"
 	"github.com/ilindan-dev/shortener/internal/storage/redis"
+	"github.com/ilindan-dev/shortener/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 	"net/http"
@@ -19,31 +23,76 @@ var Module = fx.Options(
 		// Core components
 		config.NewConfig,
 		logger.NewLogger,
+		tracing.NewTracerProvider,
+
+		// Metrics - every component contributes its collectors to the metrics group.
+		metrics.NewRegistry,
+		deliveryHTTP.NewMetrics,
+		redis.NewMetrics,
+		service.NewClickMetrics,
+		metrics.AsCollector[*deliveryHTTP.Metrics](),
+		metrics.AsCollector[*deliveryHTTP.RateLimiter](),
+		metrics.AsCollector[*redis.Metrics](),
+		metrics.AsCollector[*service.ClickMetrics](),
+		fx.Annotate(postgres.NewPoolCollector, fx.As(new(prometheus.Collector)), fx.ResultTags(metrics.GroupTag)),
+		fx.Annotate(redis.NewPoolCollector, fx.As(new(prometheus.Collector)), fx.ResultTags(metrics.GroupTag)),
 
 		// Storage Layer - concrete implementations
 		postgres.NewPool,
 		redis.NewClient,
 
-		// Repositories and Caches
-		postgres.NewURLRepository,
-		postgres.NewClickRepository,
-		postgres.NewAnalyticsRepository,
-		redis.NewURLCache,
+		// Repositories and Caches - bound to their domain interfaces.
+		// The Postgres URL and workspace repositories are named so the cache-aside decorators can wrap them.
+		fx.Annotate(postgres.NewURLRepository, fx.As(new(repo.URLRepository)), fx.ResultTags(`name:"primaryURLRepository"`)),
+		fx.Annotate(redis.NewCachedURLRepository, fx.ParamTags(`name:"primaryURLRepository"`), fx.As(new(repo.URLRepository))),
+		fx.Annotate(postgres.NewClickRepository, fx.As(new(repo.ClickRepository))),
+		fx.Annotate(postgres.NewAnalyticsRepository, fx.As(new(repo.AnalyticsRepository))),
+		fx.Annotate(redis.NewURLCache, fx.As(new(repo.URLCache))),
+		fx.Annotate(redis.NewClickStream, fx.As(new(repo.ClickStream))),
+		fx.Annotate(postgres.NewWebhookRepository, fx.As(new(repo.WebhookRepository))),
+		fx.Annotate(redis.NewRateLimiter, fx.As(new(repo.RateLimiter))),
+		fx.Annotate(postgres.NewAPIKeyRepository, fx.As(new(repo.APIKeyRepository))),
+		fx.Annotate(postgres.NewWorkspaceRepository, fx.As(new(repo.WorkspaceRepository)), fx.ResultTags(`name:"primaryWorkspaceRepository"`)),
+		fx.Annotate(redis.NewCachedWorkspaceRepository, fx.ParamTags(`name:"primaryWorkspaceRepository"`), fx.As(new(repo.WorkspaceRepository))),
+		fx.Annotate(postgres.NewAuditRepository, fx.As(new(repo.AuditRepository))),
+		fx.Annotate(postgres.NewTagRepository, fx.As(new(repo.TagRepository))),
+		fx.Annotate(postgres.NewFolderRepository, fx.As(new(repo.FolderRepository))),
+		fx.Annotate(postgres.NewTransactor, fx.As(new(repo.Transactor))),
 
 		// Service Layer
+		service.NewResolver,
+		fx.Annotate(service.NewHTTPLinkExpander, fx.As(new(service.LinkExpander))),
+		service.NewDomains,
+		service.NewURLPolicy,
 		service.NewURLService,
 		service.NewAnalyticsService,
+		service.NewLiveService,
+		service.NewWebhookService,
+		service.NewWebhookDispatcher,
+		service.NewLinkExpirySweeper,
+		service.NewAPIKeyService,
+		service.NewWorkspaceService,
+		service.NewAuditService,
+		service.NewFolderService,
 
 		// Delivery Layer
 		// We need a special provider for handlers because it needs the baseURL from config.
 		func(
 			urlService *service.URLService,
 			analyticsService *service.AnalyticsService,
+			liveService *service.LiveService,
+			webhookService *service.WebhookService,
+			auditService *service.AuditService,
+			folderService *service.FolderService,
+			redirects *deliveryHTTP.RedirectPolicy,
 			logger *zerolog.Logger,
 			cfg *config.Config,
 		) *deliveryHTTP.Handlers {
-			return deliveryHTTP.NewHandlers(urlService, analyticsService, logger, cfg.HTTP.BaseURL)
+			return deliveryHTTP.NewHandlers(urlService, analyticsService, liveService, webhookService, auditService, folderService, redirects, logger, cfg.HTTP.BaseURL, cfg.HTTP.CountryHeader)
 		},
+		deliveryHTTP.NewRedirectPolicy,
+		deliveryHTTP.NewRateLimiter,
+		deliveryHTTP.NewAuthenticator,
 		deliveryHTTP.NewServer,
 	),
 	// This invoke bootstraps the HTTP server.
@@ -62,4 +111,56 @@ var Module = fx.Options(
 			},
 		})
 	}),
+	// This invoke runs the webhook dispatcher alongside the server.
+	fx.Invoke(func(dispatcher *service.WebhookDispatcher, lc fx.Lifecycle) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				dispatcher.Start()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				dispatcher.Stop()
+				return nil
+			},
+		})
+	}),
+	// This invoke announces expired links while the server runs.
+	fx.Invoke(func(sweeper *service.LinkExpirySweeper, lc fx.Lifecycle) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				sweeper.Start()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				sweeper.Stop()
+				return nil
+			},
+		})
+	}),
+	// This invoke reloads the URL blocklist while the server runs.
+	fx.Invoke(func(policy *service.URLPolicy, lc fx.Lifecycle) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				policy.Start()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				policy.Stop()
+				return nil
+			},
+		})
+	}),
+	// This invoke periodically writes API key usage and flushes the remainder on shutdown.
+	fx.Invoke(func(keys *service.APIKeyService, lc fx.Lifecycle) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				keys.Start()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				keys.Stop(ctx)
+				return nil
+			},
+		})
+	}),
 )
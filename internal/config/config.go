@@ -9,15 +9,28 @@ import (
 
 // Config is the main struct that holds all configuration for the application.
 type Config struct {
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	HTTP     HTTPConfig     `mapstructure:"http"`
-	Postgres PostgresConfig `mapstructure:"postgres"`
-	Redis    RedisConfig    `mapstructure:"redis"`
+	Logger    LoggerConfig    `mapstructure:"logger"`
+	HTTP      HTTPConfig      `mapstructure:"http"`
+	Postgres  PostgresConfig  `mapstructure:"postgres"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Analytics AnalyticsConfig `mapstructure:"analytics"`
+	Live      LiveConfig      `mapstructure:"live"`
+	Webhooks  WebhookConfig   `mapstructure:"webhooks"`
+	Tracing   TracingConfig   `mapstructure:"tracing"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	URLPolicy URLPolicyConfig `mapstructure:"url_policy"`
+	Links     LinksConfig     `mapstructure:"links"`
+	Redirect  RedirectConfig  `mapstructure:"redirect"`
 }
 
 // LoggerConfig holds logging-specific settings.
 type LoggerConfig struct {
 	Level string `mapstructure:"level"`
+	// Format is "console" for human-readable output or "json" for log shipping.
+	Format string `mapstructure:"format"`
+	// RedirectSampleEvery logs one in every N redirects; warnings and errors are always logged.
+	RedirectSampleEvery uint64 `mapstructure:"redirect_sample_every"`
 }
 
 // HTTPConfig holds HTTP server-specific settings.
@@ -25,6 +38,18 @@ type HTTPConfig struct {
 	Port    string `mapstructure:"port"`
 	GinMode string `mapstructure:"gin_mode"`
 	BaseURL string `mapstructure:"base_url"`
+	// TrustedProxies lists the addresses or CIDR ranges of the proxies in front of the service.
+	// Only their X-Forwarded-For and X-Real-IP headers are used to find the client IP; by
+	// default no proxy is trusted and the client IP is the address of the connection.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// CountryHeader is the request header set by a trusted proxy/CDN with the client's ISO country code.
+	CountryHeader string `mapstructure:"country_header"`
+	// CustomDomains lists further hosts, besides the one of BaseURL, that serve the short links of
+	// every workspace. Workspaces may register hosts of their own on top.
+	CustomDomains []string `mapstructure:"custom_domains"`
+	// DomainCacheTTL is how long the lookup of a workspace host, including a miss, is cached.
+	// Hosts added or removed evict their entry, so it only bounds staleness on Redis failures.
+	DomainCacheTTL time.Duration `mapstructure:"domain_cache_ttl"`
 }
 
 // PostgresConfig holds all settings for the PostgreSQL database connection.
@@ -47,6 +72,123 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
+// AnalyticsConfig holds settings that bound the size of analytics responses.
+type AnalyticsConfig struct {
+	RecentClicksLimit int `mapstructure:"recent_clicks_limit"`
+	DefaultClicksPage int `mapstructure:"default_clicks_page"`
+	MaxClicksPage     int `mapstructure:"max_clicks_page"`
+}
+
+// LiveConfig holds settings for the live click stream.
+type LiveConfig struct {
+	// BufferSize is the number of events queued per connection before new events are dropped.
+	BufferSize int `mapstructure:"buffer_size"`
+	// ReplaySize is the number of recent events per link kept for Last-Event-ID resume.
+	ReplaySize int64 `mapstructure:"replay_size"`
+	// ReplayTTL is how long the replay buffer of an idle link is kept.
+	ReplayTTL      time.Duration `mapstructure:"replay_ttl"`
+	Heartbeat      time.Duration `mapstructure:"heartbeat"`
+	MaxConnections int           `mapstructure:"max_connections"`
+}
+
+// WebhookConfig holds settings for the outgoing webhook dispatcher.
+type WebhookConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	// MaxAttempts is the number of attempts after which a delivery is dead-lettered.
+	MaxAttempts int           `mapstructure:"max_attempts"`
+	BackoffBase time.Duration `mapstructure:"backoff_base"`
+	BackoffMax  time.Duration `mapstructure:"backoff_max"`
+}
+
+// TracingConfig holds OpenTelemetry tracing settings.
+type TracingConfig struct {
+	// Exporter selects where spans are sent: "otlp", "stdout" or "none".
+	Exporter string `mapstructure:"exporter"`
+	// Endpoint is the OTLP gRPC collector address, e.g. "otel-collector:4317".
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+// RateLimitConfig holds the request limits of each route group.
+type RateLimitConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Groups maps a route group ("shorten", "api", "redirect") to its limits.
+	Groups map[string]RateLimitGroupConfig `mapstructure:"groups"`
+}
+
+// RateLimitGroupConfig holds the limits applied to every request of a route group.
+type RateLimitGroupConfig struct {
+	PerIP     RateLimitRule `mapstructure:"per_ip"`
+	PerAPIKey RateLimitRule `mapstructure:"per_api_key"`
+}
+
+// RateLimitRule allows Rate requests per Period with bursts of up to Burst; a zero Rate disables it.
+type RateLimitRule struct {
+	Rate   int           `mapstructure:"rate"`
+	Period time.Duration `mapstructure:"period"`
+	Burst  int           `mapstructure:"burst"`
+}
+
+// AuthConfig holds API key authentication settings.
+type AuthConfig struct {
+	// Enabled requires an API key on every /api/v1 route.
+	Enabled bool `mapstructure:"enabled"`
+	// UsageFlushInterval is how often aggregated key usage is written to the database.
+	UsageFlushInterval time.Duration `mapstructure:"usage_flush_interval"`
+}
+
+// URLPolicyConfig holds the rules a link destination must pass before it is shortened.
+type URLPolicyConfig struct {
+	// AllowedSchemes lists the accepted URL schemes, e.g. "http" and "https".
+	AllowedSchemes []string `mapstructure:"allowed_schemes"`
+	// BlockPrivateNetworks rejects loopback, private and link-local destinations.
+	BlockPrivateNetworks bool `mapstructure:"block_private_networks"`
+	// ResolveDNS also checks the addresses a destination host resolves to.
+	ResolveDNS bool          `mapstructure:"resolve_dns"`
+	DNSTimeout time.Duration `mapstructure:"dns_timeout"`
+	// BlocklistFile is a file with one blocked domain per line; empty disables the blocklist.
+	BlocklistFile string `mapstructure:"blocklist_file"`
+	// BlocklistReloadInterval is how often the blocklist file is checked for changes.
+	BlocklistReloadInterval time.Duration `mapstructure:"blocklist_reload_interval"`
+	// Shorteners lists the domains of other URL shorteners; their subdomains match too.
+	Shorteners []string `mapstructure:"shorteners"`
+	// ShortenerAction is "reject" to refuse links of other shorteners or "expand" to
+	// follow them to their destination.
+	ShortenerAction string `mapstructure:"shortener_action"`
+	// MaxExpandHops bounds the number of redirects followed when expanding a link.
+	MaxExpandHops int           `mapstructure:"max_expand_hops"`
+	ExpandTimeout time.Duration `mapstructure:"expand_timeout"`
+}
+
+// LinksConfig holds settings for how link destinations are stored.
+type LinksConfig struct {
+	// StripTrackingParams removes TrackingParams from destinations when they are normalized.
+	StripTrackingParams bool `mapstructure:"strip_tracking_params"`
+	// TrackingParams lists query parameter names; a trailing "*" matches a prefix, e.g. "utm_*".
+	TrackingParams []string `mapstructure:"tracking_params"`
+	// ExpirySweepInterval is how often links whose expiry has passed are announced with link.expired.
+	ExpirySweepInterval time.Duration `mapstructure:"expiry_sweep_interval"`
+	// ExpirySweepBatch is the number of expired links announced per transaction.
+	ExpirySweepBatch int32 `mapstructure:"expiry_sweep_batch"`
+}
+
+// RedirectConfig holds settings for the public short link redirect.
+type RedirectConfig struct {
+	// DefaultStatus is the redirect status of links without one of their own: 301, 302, 307 or 308.
+	DefaultStatus int `mapstructure:"default_status"`
+	// PermanentMaxAge is how long clients may cache permanent (301 and 308) redirects.
+	PermanentMaxAge time.Duration `mapstructure:"permanent_max_age"`
+	// PreviewSecret signs the tokens with which the preview page confirms the redirect of a
+	// suspicious link. Replicas must share it; when empty, each generates a random one.
+	PreviewSecret string `mapstructure:"preview_secret"`
+	// PreviewTokenTTL is how long a confirmation token of the preview page stays valid.
+	PreviewTokenTTL time.Duration `mapstructure:"preview_token_ttl"`
+}
+
 // NewConfig parses the YAML file and environment variables to return a configuration struct.
 func NewConfig() (*Config, error) {
 	v := viper.New()
@@ -57,10 +199,58 @@ func NewConfig() (*Config, error) {
 
 	// Set defaults
 	v.SetDefault("logger.level", "debug")
+	v.SetDefault("logger.format", "console")
+	v.SetDefault("logger.redirect_sample_every", 100)
 	v.SetDefault("http.port", ":8080")
 	v.SetDefault("http.gin_mode", "debug")
 	v.SetDefault("http.base_url", "http://localhost:8080")
+	v.SetDefault("http.trusted_proxies", []string{})
+	v.SetDefault("http.country_header", "CF-IPCountry")
+	v.SetDefault("http.domain_cache_ttl", "30s")
 	v.SetDefault("postgres.pool.max_open_conns", 10)
+	v.SetDefault("analytics.recent_clicks_limit", 20)
+	v.SetDefault("analytics.default_clicks_page", 50)
+	v.SetDefault("analytics.max_clicks_page", 500)
+	v.SetDefault("live.buffer_size", 64)
+	v.SetDefault("live.replay_size", 1000)
+	v.SetDefault("live.replay_ttl", "24h")
+	v.SetDefault("live.heartbeat", "15s")
+	v.SetDefault("live.max_connections", 1000)
+	v.SetDefault("webhooks.poll_interval", "2s")
+	v.SetDefault("webhooks.batch_size", 20)
+	v.SetDefault("webhooks.timeout", "10s")
+	v.SetDefault("webhooks.max_attempts", 8)
+	v.SetDefault("webhooks.backoff_base", "10s")
+	v.SetDefault("webhooks.backoff_max", "1h")
+	v.SetDefault("tracing.exporter", "none")
+	v.SetDefault("tracing.endpoint", "localhost:4317")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("tracing.service_name", "shortener")
+	v.SetDefault("rate_limit.enabled", true)
+	v.SetDefault("auth.enabled", true)
+	v.SetDefault("auth.usage_flush_interval", "10s")
+	v.SetDefault("url_policy.allowed_schemes", []string{"http", "https"})
+	v.SetDefault("url_policy.block_private_networks", true)
+	v.SetDefault("url_policy.resolve_dns", true)
+	v.SetDefault("url_policy.dns_timeout", "2s")
+	v.SetDefault("url_policy.blocklist_reload_interval", "30s")
+	v.SetDefault("url_policy.shorteners", []string{
+		"bit.ly", "buff.ly", "cutt.ly", "goo.gl", "is.gd", "ow.ly", "rb.gy",
+		"rebrand.ly", "shorturl.at", "t.co", "tiny.cc", "tinyurl.com",
+	})
+	v.SetDefault("url_policy.shortener_action", "reject")
+	v.SetDefault("url_policy.max_expand_hops", 5)
+	v.SetDefault("url_policy.expand_timeout", "5s")
+	v.SetDefault("redirect.default_status", 302)
+	v.SetDefault("redirect.permanent_max_age", "1h")
+	v.SetDefault("redirect.preview_token_ttl", "10m")
+	v.SetDefault("links.strip_tracking_params", false)
+	v.SetDefault("links.expiry_sweep_interval", "1m")
+	v.SetDefault("links.expiry_sweep_batch", 100)
+	v.SetDefault("links.tracking_params", []string{
+		"utm_*", "fbclid", "gclid", "dclid", "msclkid", "yclid", "igshid", "mc_cid", "mc_eid",
+	})
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
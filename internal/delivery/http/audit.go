@@ -0,0 +1,72 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/service"
+	"net/http"
+)
+
+// auditSource records the client address in the request context, so that the audit
+// events caused by the request carry it.
+func auditSource() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(service.ContextWithSourceIP(c.Request.Context(), c.ClientIP()))
+		c.Next()
+	}
+}
+
+// ListAuditEvents handles the request to page through the audit trail of the workspace.
+func (h *Handlers) ListAuditEvents(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	var params AuditQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := model.AuditFilter{
+		Action:    params.Action,
+		ShortCode: params.ShortCode,
+		Actor:     params.Actor,
+		From:      params.From,
+		To:        params.To,
+		BeforeID:  params.Before,
+		Limit:     params.Limit,
+	}
+
+	events, err := h.auditService.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed to list audit events")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list audit events"})
+		return
+	}
+
+	resp := AuditListResponse{Events: make([]AuditEventDTO, len(events))}
+	for i, e := range events {
+		resp.Events[i] = AuditEventDTO{
+			ID:            e.ID,
+			Actor:         e.Actor,
+			ActorUserID:   e.ActorUserID,
+			ActorAPIKeyID: e.ActorAPIKeyID,
+			Action:        e.Action,
+			ShortCode:     e.ShortCode,
+			Before:        e.Before,
+			After:         e.After,
+			SourceIP:      e.SourceIP,
+			RequestID:     e.RequestID,
+			CreatedAt:     e.CreatedAt,
+		}
+	}
+	if len(events) > 0 {
+		resp.NextBefore = events[len(events)-1].ID
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/service"
+	"github.com/rs/zerolog"
+	"net/http"
+	"strings"
+)
+
+// HeaderAPIKey carries the API key of a client; "Authorization: Bearer <key>" is accepted as well.
+const HeaderAPIKey = "X-API-Key"
+
+// Authenticator protects routes with API keys. Safe methods need a key with the read
+// scope, everything else the write scope.
+type Authenticator struct {
+	keys    *service.APIKeyService
+	enabled bool
+	logger  zerolog.Logger
+}
+
+// NewAuthenticator creates a new instance of Authenticator.
+func NewAuthenticator(keys *service.APIKeyService, cfg *config.Config, logger *zerolog.Logger) *Authenticator {
+	return &Authenticator{
+		keys:    keys,
+		enabled: cfg.Auth.Enabled,
+		logger:  logger.With().Str("layer", "http_auth").Logger(),
+	}
+}
+
+// Middleware returns the handler that authenticates the request and checks the key's scope.
+// The key is stored in the request context for the handlers and services that follow.
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	if !a.enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		log := logger.FromContext(c.Request.Context(), a.logger)
+		secret := apiKeyFromRequest(c)
+		if secret == "" {
+			abortUnauthorized(c, "API key required")
+			return
+		}
+
+		key, err := a.keys.Authenticate(c.Request.Context(), secret)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidAPIKey) {
+				abortUnauthorized(c, "Invalid API key")
+				return
+			}
+			log.Error().Err(err).Msg("Failed to authenticate API key")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to authenticate request"})
+			return
+		}
+
+		if scope := requiredScope(c.Request.Method); !key.Allows(scope) {
+			log.Warn().Int64("api_key_id", key.ID).Str("scope", scope).Msg("API key lacks required scope")
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: "API key does not have the " + scope + " scope"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(service.ContextWithAPIKey(c.Request.Context(), key))
+		c.Next()
+	}
+}
+
+// requiredScope maps an HTTP method to the scope needed to perform it.
+func requiredScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return model.ScopeRead
+	default:
+		return model.ScopeWrite
+	}
+}
+
+// abortUnauthorized rejects a request without valid credentials.
+func abortUnauthorized(c *gin.Context, msg string) {
+	c.Header("WWW-Authenticate", `Bearer realm="shortener"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Error: msg})
+}
+
+// apiKeyFromRequest returns the API key sent in X-API-Key or as a bearer token.
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader(HeaderAPIKey); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
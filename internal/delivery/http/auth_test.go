@@ -0,0 +1,30 @@
+package http
+
+import (
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"net/http"
+	"testing"
+)
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{method: http.MethodGet, want: model.ScopeRead},
+		{method: http.MethodHead, want: model.ScopeRead},
+		{method: http.MethodOptions, want: model.ScopeRead},
+		{method: http.MethodPost, want: model.ScopeWrite},
+		{method: http.MethodPut, want: model.ScopeWrite},
+		{method: http.MethodPatch, want: model.ScopeWrite},
+		{method: http.MethodDelete, want: model.ScopeWrite},
+		{method: "PROPFIND", want: model.ScopeWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if got := requiredScope(tt.method); got != tt.want {
+				t.Fatalf("requiredScope(%s) = %s, want %s", tt.method, got, tt.want)
+			}
+		})
+	}
+}
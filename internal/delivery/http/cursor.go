@@ -0,0 +1,84 @@
+package http
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"strings"
+	"time"
+)
+
+// errInvalidCursor is returned when a pagination cursor cannot be decoded.
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeClickCursor turns a keyset position into an opaque, URL-safe token.
+func encodeClickCursor(cursor *model.ClickCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	raw := fmt.Sprintf("%d:%d", cursor.CreatedAt.UnixMicro(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeClickCursor parses a token produced by encodeClickCursor.
+// An empty token yields a nil cursor, meaning "start from the newest click".
+func decodeClickCursor(token string) (*model.ClickCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var micros, id int64
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &micros, &id); err != nil {
+		return nil, errInvalidCursor
+	}
+
+	return &model.ClickCursor{CreatedAt: time.UnixMicro(micros), ID: id}, nil
+}
+
+// encodeLinkCursor turns a keyset position of a link listing into an opaque, URL-safe token.
+// The sort order is part of the token, as positions of different orders are not comparable.
+func encodeLinkCursor(sortBy string, cursor *model.LinkCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	value := cursor.CreatedAt.UnixMicro()
+	if sortBy == model.LinkSortClickCount {
+		value = cursor.ClickCount
+	}
+	raw := fmt.Sprintf("%s:%d:%d", sortBy, value, cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeLinkCursor parses a token produced by encodeLinkCursor for the same sort order.
+// An empty token yields a nil cursor, meaning "start from the first link".
+func decodeLinkCursor(sortBy, token string) (*model.LinkCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	tokenSort, position, ok := strings.Cut(string(raw), ":")
+	if !ok || tokenSort != sortBy {
+		return nil, errInvalidCursor
+	}
+
+	var value, id int64
+	if _, err := fmt.Sscanf(position, "%d:%d", &value, &id); err != nil {
+		return nil, errInvalidCursor
+	}
+
+	if sortBy == model.LinkSortClickCount {
+		return &model.LinkCursor{ClickCount: value, ID: id}, nil
+	}
+	return &model.LinkCursor{CreatedAt: time.UnixMicro(value), ID: id}, nil
+}
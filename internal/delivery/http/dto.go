@@ -1,16 +1,51 @@
 package http
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // CreateURLRequest defines the structure for a new URL shortening request.
+// Metadata must be a JSON object when given. ReuseExisting returns an existing link with the
+// same normalized destination instead of creating another one. RedirectStatus is 301, 302,
+// 307 or 308; when omitted the link follows the configured default. Domain is the host the
+// link is served on, one of those listed by /api/v1/domains; when omitted it is the base URL.
+// ExpiresAt is an RFC 3339 time after which the link answers 410 Gone; when omitted it never expires.
 type CreateURLRequest struct {
-	URL string `json:"url" binding:"required,url"`
+	URL            string          `json:"url" binding:"required,url"`
+	Title          string          `json:"title"`
+	Description    string          `json:"description"`
+	Metadata       json.RawMessage `json:"metadata"`
+	ReuseExisting  bool            `json:"reuse_existing"`
+	RedirectStatus int             `json:"redirect_status"`
+	Domain         string          `json:"domain"`
+	ExpiresAt      *time.Time      `json:"expires_at"`
+}
+
+// UpdateURLRequest defines the structure for changing the destination or details of a short URL.
+// Omitted fields keep their current value; a null metadata clears it, a redirect_status
+// of 0 restores the configured default and a null expires_at lets the link live forever.
+type UpdateURLRequest struct {
+	URL            *string         `json:"url" binding:"omitempty,url"`
+	Title          *string         `json:"title"`
+	Description    *string         `json:"description"`
+	Metadata       json.RawMessage `json:"metadata"`
+	RedirectStatus *int            `json:"redirect_status"`
+	ExpiresAt      json.RawMessage `json:"expires_at"`
 }
 
 // URLResponse defines the structure for a successful URL creation response.
 type URLResponse struct {
-	OriginalURL string `json:"original_url"`
-	ShortURL    string `json:"short_url"`
+	OriginalURL    string          `json:"original_url"`
+	ShortURL       string          `json:"short_url"`
+	Domain         string          `json:"domain,omitempty"`
+	Title          string          `json:"title,omitempty"`
+	Description    string          `json:"description,omitempty"`
+	Metadata       json.RawMessage `json:"metadata,omitempty"`
+	CreatedBy      string          `json:"created_by,omitempty"`
+	RedirectStatus int             `json:"redirect_status,omitempty"`
+	Suspicious     bool            `json:"suspicious,omitempty"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty"`
 }
 
 // ClickDTO defines a simplified view of a click for the analytics response.
@@ -19,14 +54,155 @@ type ClickDTO struct {
 	UserAgent string    `json:"user_agent"`
 }
 
+// AnalyticsQueryParams defines the optional query parameters of the analytics endpoint.
+type AnalyticsQueryParams struct {
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Period string    `form:"period" binding:"omitempty,oneof=hour day week month"`
+	Window int       `form:"window" binding:"omitempty,min=1,max=90"`
+}
+
+// TimeSeriesPointDTO defines a single bucket of a click time series.
+type TimeSeriesPointDTO struct {
+	Bucket        time.Time `json:"bucket"`
+	Value         int64     `json:"value"`
+	MovingAverage float64   `json:"moving_average"`
+}
+
+// TimeSeriesDTO defines a dense click time series with its derived values.
+type TimeSeriesDTO struct {
+	Period        string               `json:"period"`
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	Total         int64                `json:"total"`
+	PreviousTotal int64                `json:"previous_total"`
+	ChangePercent *float64             `json:"change_percent"`
+	Peak          *TimeSeriesPointDTO  `json:"peak"`
+	Points        []TimeSeriesPointDTO `json:"points"`
+}
+
+// ClickListParams defines the query parameters of the paginated click listing.
+type ClickListParams struct {
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Bot       *bool     `form:"bot"`
+	UserAgent string    `form:"user_agent"`
+	Cursor    string    `form:"cursor"`
+	Limit     int       `form:"limit" binding:"omitempty,min=1"`
+}
+
+// ClickExportParams defines the query parameters of the click export.
+type ClickExportParams struct {
+	Format  string    `form:"format" binding:"omitempty,oneof=csv ndjson"`
+	From    time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Bot     *bool     `form:"bot"`
+	Columns string    `form:"columns"`
+}
+
+// ClickDetailDTO defines the full view of a click in the click listing.
+type ClickDetailDTO struct {
+	ID        int64     `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	UserAgent string    `json:"user_agent"`
+	IPAddress string    `json:"ip_address"`
+	IsBot     bool      `json:"is_bot"`
+	Source    string    `json:"source,omitempty"`
+}
+
+// ClickListResponse defines a single page of the click listing.
+type ClickListResponse struct {
+	Clicks     []ClickDetailDTO `json:"clicks"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// QRQueryParams defines the optional query parameters of the QR code endpoint.
+// Size is the image width in pixels, Margin the quiet zone in modules and ECC the error
+// correction level.
+type QRQueryParams struct {
+	Format string `form:"format" binding:"omitempty,oneof=png svg"`
+	Size   int    `form:"size" binding:"omitempty,min=64,max=2048"`
+	Margin *int   `form:"margin" binding:"omitempty,min=0,max=16"`
+	ECC    string `form:"ecc" binding:"omitempty,oneof=L M Q H l m q h"`
+}
+
+// PivotQueryParams defines the query parameters of the period × dimension breakdown.
+type PivotQueryParams struct {
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Period string    `form:"period" binding:"omitempty,oneof=hour day week month"`
+	By     string    `form:"by"`
+	Top    int       `form:"top" binding:"omitempty,min=1"`
+	Layout string    `form:"layout" binding:"omitempty,oneof=matrix long"`
+}
+
+// PivotSeriesDTO defines one dimension value of the matrix layout.
+type PivotSeriesDTO struct {
+	Key    string  `json:"key"`
+	Total  int64   `json:"total"`
+	Values []int64 `json:"values"`
+}
+
+// PivotRowDTO defines one (bucket, dimension value) pair of the long layout.
+type PivotRowDTO struct {
+	Bucket time.Time `json:"bucket"`
+	Key    string    `json:"key"`
+	Value  int64     `json:"value"`
+}
+
+// PivotResponse defines a period × dimension breakdown. Depending on the requested
+// layout either Buckets and Series (matrix) or Rows (long) are populated.
+type PivotResponse struct {
+	Period    string           `json:"period"`
+	Dimension string           `json:"dimension"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Buckets   []time.Time      `json:"buckets,omitempty"`
+	Series    []PivotSeriesDTO `json:"series,omitempty"`
+	Rows      []PivotRowDTO    `json:"rows,omitempty"`
+}
+
+// TopLinksParams defines the query parameters of the top links leaderboard.
+type TopLinksParams struct {
+	From  time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To    time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort  string    `form:"sort" binding:"omitempty,oneof=total unique"`
+	Limit int       `form:"limit" binding:"omitempty,min=1"`
+}
+
+// LinkStatDTO defines a single entry of the top links leaderboard.
+type LinkStatDTO struct {
+	OriginalURL  string    `json:"original_url"`
+	ShortURL     string    `json:"short_url"`
+	CreatedAt    time.Time `json:"created_at"`
+	TotalClicks  int64     `json:"total_clicks"`
+	UniqueClicks int64     `json:"unique_clicks"`
+}
+
+// TopLinksResponse defines the top links leaderboard.
+type TopLinksResponse struct {
+	Links []LinkStatDTO `json:"links"`
+}
+
+// OverviewResponse defines the account-wide analytics summary.
+type OverviewResponse struct {
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	LinksCreated   int64         `json:"links_created"`
+	TotalLinks     int64         `json:"total_links"`
+	TotalClicks    int64         `json:"total_clicks"`
+	UniqueVisitors int64         `json:"unique_visitors"`
+	ClicksOverTime TimeSeriesDTO `json:"clicks_over_time"`
+}
+
 // AnalyticsResponse defines the structure for the full analytics report.
 type AnalyticsResponse struct {
-	OriginalURL       string     `json:"original_url"`
-	ShortURL          string     `json:"short_url"`
-	TotalClicks       int64      `json:"total_clicks"`
-	ClicksByDay       []StatItem `json:"clicks_by_day"`
-	ClicksByUserAgent []StatItem `json:"clicks_by_user_agent"`
-	RecentClicks      []ClickDTO `json:"recent_clicks"`
+	OriginalURL       string        `json:"original_url"`
+	ShortURL          string        `json:"short_url"`
+	TotalClicks       int64         `json:"total_clicks"`
+	ClicksOverTime    TimeSeriesDTO `json:"clicks_over_time"`
+	ClicksByUserAgent []StatItem    `json:"clicks_by_user_agent"`
+	RecentClicks      []ClickDTO    `json:"recent_clicks"`
 }
 
 // StatItem is a generic structure for aggregated data.
@@ -35,7 +211,191 @@ type StatItem struct {
 	Value int64  `json:"value"`
 }
 
+// CreateWebhookRequest defines the structure for a new webhook subscription.
+type CreateWebhookRequest struct {
+	URL            string   `json:"url" binding:"required,url"`
+	Events         []string `json:"events" binding:"required,min=1"`
+	ClickThreshold int64    `json:"click_threshold" binding:"omitempty,min=1"`
+	Secret         string   `json:"secret" binding:"omitempty,min=16"`
+}
+
+// WebhookResponse defines a webhook subscription. The secret is only included on creation.
+type WebhookResponse struct {
+	ID             int64     `json:"id"`
+	URL            string    `json:"url"`
+	Events         []string  `json:"events"`
+	ClickThreshold int64     `json:"click_threshold,omitempty"`
+	Active         bool      `json:"active"`
+	Secret         string    `json:"secret,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryParams defines the query parameters for the delivery history of a webhook.
+type WebhookDeliveryParams struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded dead"`
+	Before int64  `form:"before" binding:"omitempty,min=1"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1"`
+}
+
+// WebhookDeliveryDTO defines a single delivery attempt record of a webhook.
+type WebhookDeliveryDTO struct {
+	ID             int64           `json:"id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// WebhookDeliveryListResponse defines a page of the delivery history of a webhook.
+// NextBefore is passed as "before" to fetch the following page; an empty page ends the history.
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryDTO `json:"deliveries"`
+	NextBefore int64                `json:"next_before,omitempty"`
+}
+
+// LinkListParams defines the query parameters of the link listing.
+type LinkListParams struct {
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Domain   string    `form:"domain"`
+	Query    string    `form:"q"`
+	Tag      string    `form:"tag"`
+	FolderID *int64    `form:"folder_id"`
+	Status   string    `form:"status" binding:"omitempty,oneof=active suspicious"`
+	Sort     string    `form:"sort" binding:"omitempty,oneof=created_at click_count"`
+	Cursor   string    `form:"cursor"`
+	Limit    int       `form:"limit" binding:"omitempty,min=1"`
+}
+
+// LinkDTO defines a link in listings.
+type LinkDTO struct {
+	ShortCode      string          `json:"short_code"`
+	ShortURL       string          `json:"short_url"`
+	Domain         string          `json:"domain,omitempty"`
+	OriginalURL    string          `json:"original_url"`
+	ClickCount     int64           `json:"click_count"`
+	CreatedAt      time.Time       `json:"created_at"`
+	FolderID       *int64          `json:"folder_id"`
+	Tags           []string        `json:"tags"`
+	Title          string          `json:"title,omitempty"`
+	Description    string          `json:"description,omitempty"`
+	Metadata       json.RawMessage `json:"metadata,omitempty"`
+	CreatedBy      string          `json:"created_by,omitempty"`
+	RedirectStatus int             `json:"redirect_status,omitempty"`
+	Suspicious     bool            `json:"suspicious,omitempty"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty"`
+}
+
+// LinkListResponse defines a page of the link listing.
+// NextCursor is passed as "cursor" to fetch the following page; it is empty on the last page.
+type LinkListResponse struct {
+	Links      []LinkDTO `json:"links"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// SetTagsRequest defines the request body for replacing the tags of a link.
+// An empty list removes all tags.
+type SetTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// MoveLinkRequest defines the request body for moving a link between folders.
+// A null folder_id takes the link out of its folder.
+type MoveLinkRequest struct {
+	FolderID *int64 `json:"folder_id"`
+}
+
+// DomainListResponse defines the hosts the links of the workspace can be served on.
+type DomainListResponse struct {
+	Default   string      `json:"default"`
+	Shared    []string    `json:"shared"`
+	Workspace []DomainDTO `json:"workspace"`
+}
+
+// DomainDTO defines a host registered for the workspace.
+type DomainDTO struct {
+	Host      string    `json:"host"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagDTO defines a tag together with the number of links carrying it.
+type TagDTO struct {
+	Name      string    `json:"name"`
+	LinkCount int64     `json:"link_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagReportResponse defines the aggregated analytics of all links carrying a tag.
+type TagReportResponse struct {
+	Tag            string        `json:"tag"`
+	LinkCount      int64         `json:"link_count"`
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	TotalClicks    int64         `json:"total_clicks"`
+	UniqueVisitors int64         `json:"unique_visitors"`
+	ClicksOverTime TimeSeriesDTO `json:"clicks_over_time"`
+	TopLinks       []LinkStatDTO `json:"top_links"`
+}
+
+// CreateFolderRequest defines the request body for creating a folder.
+type CreateFolderRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// FolderDTO defines a folder together with the number of links in it.
+type FolderDTO struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	LinkCount int64     `json:"link_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditQueryParams defines the query parameters for the audit trail.
+type AuditQueryParams struct {
+	Action    string    `form:"action"`
+	ShortCode string    `form:"short_code"`
+	Actor     string    `form:"actor"`
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Before    int64     `form:"before" binding:"omitempty,min=1"`
+	Limit     int       `form:"limit" binding:"omitempty,min=1"`
+}
+
+// AuditEventDTO defines a single entry of the audit trail.
+type AuditEventDTO struct {
+	ID            int64           `json:"id"`
+	Actor         string          `json:"actor"`
+	ActorUserID   *int64          `json:"actor_user_id,omitempty"`
+	ActorAPIKeyID *int64          `json:"actor_api_key_id,omitempty"`
+	Action        string          `json:"action"`
+	ShortCode     string          `json:"short_code,omitempty"`
+	Before        json.RawMessage `json:"before,omitempty"`
+	After         json.RawMessage `json:"after,omitempty"`
+	SourceIP      string          `json:"source_ip,omitempty"`
+	RequestID     string          `json:"request_id,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AuditListResponse defines a page of the audit trail.
+// NextBefore is passed as "before" to fetch the following page; an empty page ends the trail.
+type AuditListResponse struct {
+	Events     []AuditEventDTO `json:"events"`
+	NextBefore int64           `json:"next_before,omitempty"`
+}
+
 // ErrorResponse defines a standard structure for API error responses.
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// URLRejectedResponse defines the 422 response for a destination refused by the URL policy.
+// Reason is a stable code such as "scheme_not_allowed" or "private_address".
+type URLRejectedResponse struct {
+	Error  string `json:"error"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	// exportFlushEvery is the number of rows written between flushes of the response.
+	exportFlushEvery = 500
+)
+
+// exportColumns lists the exportable click columns in their default order.
+var exportColumns = []string{"id", "timestamp", "user_agent", "ip_address", "is_bot", "source"}
+
+// exportValue extracts a single column of a click for export.
+func exportValue(click model.Click, column string) any {
+	switch column {
+	case "id":
+		return click.ID
+	case "timestamp":
+		return click.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "user_agent":
+		return click.UserAgent
+	case "ip_address":
+		return click.IPAddress
+	case "is_bot":
+		return click.IsBot
+	case "source":
+		return click.Source
+	default:
+		return nil
+	}
+}
+
+// parseExportColumns validates a comma-separated column selection.
+// An empty selection exports every column.
+func parseExportColumns(raw string) ([]string, error) {
+	if raw == "" {
+		return exportColumns, nil
+	}
+
+	columns := strings.Split(raw, ",")
+	for i, column := range columns {
+		column = strings.TrimSpace(column)
+		if exportValue(model.Click{}, column) == nil {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		columns[i] = column
+	}
+	return columns, nil
+}
+
+// clickExporter writes clicks to a streaming response in a specific format.
+// Headers are sent lazily, so the handler can still respond with an error
+// until the first row (or the end of an empty stream) is written.
+type clickExporter struct {
+	c       *gin.Context
+	format  string
+	columns []string
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+	rows    int
+}
+
+// newClickExporter creates an exporter for the given format and columns.
+func newClickExporter(c *gin.Context, format string, columns []string) *clickExporter {
+	return &clickExporter{c: c, format: format, columns: columns}
+}
+
+// begin sends the response headers and, for CSV, the header row.
+func (e *clickExporter) begin(filename string) error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	contentType := "application/x-ndjson"
+	if e.format == exportFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	e.c.Header("Content-Type", contentType)
+	e.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	e.c.Header("X-Content-Type-Options", "nosniff")
+	e.c.Status(http.StatusOK)
+
+	if e.format == exportFormatCSV {
+		e.csv = csv.NewWriter(e.c.Writer)
+		return e.csv.Write(e.columns)
+	}
+	e.json = json.NewEncoder(e.c.Writer)
+	return nil
+}
+
+// write encodes a single click and periodically flushes it to the client.
+func (e *clickExporter) write(click model.Click) error {
+	var err error
+	if e.format == exportFormatCSV {
+		record := make([]string, len(e.columns))
+		for i, column := range e.columns {
+			record[i] = formatCSVValue(exportValue(click, column))
+		}
+		err = e.csv.Write(record)
+	} else {
+		row := make(map[string]any, len(e.columns))
+		for _, column := range e.columns {
+			row[column] = exportValue(click, column)
+		}
+		err = e.json.Encode(row)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushEvery == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// flush pushes buffered data to the client as a chunk.
+func (e *clickExporter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	e.c.Writer.Flush()
+	return nil
+}
+
+// formatCSVValue renders an export value as a CSV field.
+func formatCSVValue(v any) string {
+	switch val := v.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case bool:
+		return strconv.FormatBool(val)
+	case string:
+		return val
+	default:
+		return ""
+	}
+}
@@ -1,106 +1,419 @@
 package http
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
 	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
 	"github.com/ilindan-dev/shortener/internal/service"
 	"github.com/rs/zerolog"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
+// redirectRoute is the public short link route.
+const redirectRoute = "/s/:short_code"
+
+// linkDomainParam is the query parameter naming the host a link of the management API is
+// served on; without it the link is looked up on the host of the base URL.
+const linkDomainParam = "domain"
+
 // Handlers encapsulates all the HTTP handlers for the shortener service.
 type Handlers struct {
 	urlService       *service.URLService
 	analyticsService *service.AnalyticsService
+	liveService      *service.LiveService
+	webhookService   *service.WebhookService
+	auditService     *service.AuditService
+	folderService    *service.FolderService
+	redirects        *RedirectPolicy
 	logger           zerolog.Logger
 	baseURL          string // Base URL for constructing short links, e.g., "http://localhost:8080"
+	baseScheme       string // Scheme of baseURL, also used for short links on custom domains
+	countryHeader    string // Header carrying the client's ISO country code, e.g., "CF-IPCountry"
 }
 
 // NewHandlers creates a new instance of Handlers.
-func NewHandlers(urlService *service.URLService, analyticsService *service.AnalyticsService, logger *zerolog.Logger, baseURL string) *Handlers {
+func NewHandlers(
+	urlService *service.URLService,
+	analyticsService *service.AnalyticsService,
+	liveService *service.LiveService,
+	webhookService *service.WebhookService,
+	auditService *service.AuditService,
+	folderService *service.FolderService,
+	redirects *RedirectPolicy,
+	logger *zerolog.Logger,
+	baseURL string,
+	countryHeader string,
+) *Handlers {
+	baseScheme := "https"
+	if base, err := url.Parse(baseURL); err == nil && base.Scheme != "" {
+		baseScheme = base.Scheme
+	}
 	return &Handlers{
 		urlService:       urlService,
 		analyticsService: analyticsService,
+		liveService:      liveService,
+		webhookService:   webhookService,
+		auditService:     auditService,
+		folderService:    folderService,
+		redirects:        redirects,
 		logger:           logger.With().Str("layer", "http_handler").Logger(),
 		baseURL:          baseURL,
+		baseScheme:       baseScheme,
+		countryHeader:    countryHeader,
 	}
 }
 
 // RegisterRoutes sets up the routing for the application.
-func (h *Handlers) RegisterRoutes(router *gin.Engine) {
-	api := router.Group("/api/v1")
+// The /api/v1 group requires an API key; the public redirect stays open. Routes of a single
+// link take the host it is served on from the "domain" query parameter, defaulting to the
+// host of the base URL, because the same short code may exist on several domains.
+func (h *Handlers) RegisterRoutes(router *gin.Engine, limiter *RateLimiter, auth *Authenticator) {
+	// Rate limiting runs first so that guessing keys is throttled as well.
+	api := router.Group("/api/v1", limiter.Middleware(RateLimitGroupAPI), auth.Middleware(), auditSource())
 	{
-		api.POST("/shorten", h.CreateShortURL)
+		api.POST("/shorten", limiter.Middleware(RateLimitGroupShorten), h.CreateShortURL)
+		api.GET("/analytics/top", h.GetTopLinks)
+		api.GET("/analytics/overview", h.GetOverview)
 		api.GET("/analytics/:short_code", h.GetAnalytics)
+		api.GET("/links/:short_code/clicks", h.ListClicks)
+		api.GET("/links/:short_code/clicks/export", h.ExportClicks)
+		api.GET("/links/:short_code/pivot", h.GetPivot)
+		api.GET("/links/:short_code/live", h.LiveClicks)
+		api.GET("/links/:short_code/qr", h.GetQRCode)
+		api.GET("/links", h.ListLinks)
+		api.PATCH("/links/:short_code", h.UpdateURL)
+		api.DELETE("/links/:short_code", h.DeleteURL)
+		api.PUT("/links/:short_code/tags", h.SetLinkTags)
+		api.PUT("/links/:short_code/folder", h.MoveLink)
+		api.GET("/domains", h.ListDomains)
+		api.GET("/tags", h.ListTags)
+		api.DELETE("/tags/:tag", h.DeleteTag)
+		api.GET("/tags/:tag/analytics", h.GetTagAnalytics)
+		api.POST("/folders", h.CreateFolder)
+		api.GET("/folders", h.ListFolders)
+		api.DELETE("/folders/:id", h.DeleteFolder)
+		api.POST("/webhooks", h.CreateWebhook)
+		api.GET("/webhooks", h.ListWebhooks)
+		api.DELETE("/webhooks/:id", h.DeleteWebhook)
+		api.GET("/webhooks/:id/deliveries", h.ListWebhookDeliveries)
+		api.GET("/audit", h.ListAuditEvents)
 	}
 
-	router.GET("/s/:short_code", h.Redirect)
+	router.GET(redirectRoute, limiter.Middleware(RateLimitGroupRedirect), h.Redirect)
+	router.HEAD(redirectRoute, limiter.Middleware(RateLimitGroupRedirect), h.Redirect)
+	router.GET(previewRoute, limiter.Middleware(RateLimitGroupRedirect), h.Preview)
 }
 
 // CreateShortURL handles the request to create a new short URL.
+// It answers 201 for a new link and 200 when an existing link to the same destination is reused.
 func (h *Handlers) CreateShortURL(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
 	var req CreateURLRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	createdURL, err := h.urlService.CreateShortURL(c.Request.Context(), req.URL)
+	createdURL, created, err := h.urlService.CreateShortURL(c.Request.Context(), model.NewLink{
+		OriginalURL:    req.URL,
+		Title:          req.Title,
+		Description:    req.Description,
+		Metadata:       req.Metadata,
+		ReuseExisting:  req.ReuseExisting,
+		RedirectStatus: req.RedirectStatus,
+		Domain:         req.Domain,
+		ExpiresAt:      req.ExpiresAt,
+	})
 	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to create short URL")
+		if errors.Is(err, service.ErrInvalidLinkDetails) || errors.Is(err, service.ErrUnknownDomain) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		var rejected *service.URLRejectedError
+		if errors.As(err, &rejected) {
+			c.JSON(http.StatusUnprocessableEntity, URLRejectedResponse{Error: rejected.Message, Reason: rejected.Reason})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed to create short URL")
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create short URL"})
 		return
 	}
 
-	shortURL, _ := url.JoinPath(h.baseURL, "s", createdURL.ShortCode)
-	c.JSON(http.StatusCreated, URLResponse{
-		OriginalURL: createdURL.OriginalURL,
-		ShortURL:    shortURL,
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	c.JSON(status, h.toURLResponse(createdURL))
+}
+
+// ListLinks handles the request to list and search the links of the workspace.
+func (h *Handlers) ListLinks(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	var params LinkListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if params.Sort == "" {
+		params.Sort = model.LinkSortCreatedAt
+	}
+
+	cursor, err := decodeLinkCursor(params.Sort, params.Cursor)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := model.LinkFilter{
+		From:     params.From,
+		To:       params.To,
+		Domain:   params.Domain,
+		Search:   strings.TrimSpace(params.Query),
+		Tag:      params.Tag,
+		FolderID: params.FolderID,
+		Status:   params.Status,
+		SortBy:   params.Sort,
+		After:    cursor,
+		Limit:    params.Limit,
+	}
+
+	page, err := h.urlService.ListLinks(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTag) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed to list links")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list links"})
+		return
+	}
+
+	links := make([]LinkDTO, len(page.Links))
+	for i := range page.Links {
+		links[i] = h.toLinkDTO(&page.Links[i])
+	}
+
+	c.JSON(http.StatusOK, LinkListResponse{
+		Links:      links,
+		NextCursor: encodeLinkCursor(params.Sort, page.Next),
 	})
 }
 
+// UpdateURL handles the request to change the destination of a short URL.
+func (h *Handlers) UpdateURL(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	var req UpdateURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if req.URL == nil && req.Title == nil && req.Description == nil && req.Metadata == nil && req.RedirectStatus == nil && req.ExpiresAt == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Nothing to update"})
+		return
+	}
+	expiresAt, err := parseExpiry(req.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	updatedURL, err := h.urlService.UpdateURL(c.Request.Context(), c.Query(linkDomainParam), shortCode, model.LinkUpdate{
+		OriginalURL:    req.URL,
+		Title:          req.Title,
+		Description:    req.Description,
+		Metadata:       req.Metadata,
+		RedirectStatus: req.RedirectStatus,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLinkDetails) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		var rejected *service.URLRejectedError
+		if errors.As(err, &rejected) {
+			c.JSON(http.StatusUnprocessableEntity, URLRejectedResponse{Error: rejected.Message, Reason: rejected.Reason})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to update short URL")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update short URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.toURLResponse(updatedURL))
+}
+
+// parseExpiry reads the expires_at of an update: nil when it was omitted, the zero time for
+// null, which removes the expiry, and the given time otherwise.
+func parseExpiry(raw json.RawMessage) (*time.Time, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var expiresAt *time.Time
+	if err := json.Unmarshal(raw, &expiresAt); err != nil {
+		return nil, errors.New("expires_at must be an RFC 3339 time or null")
+	}
+	if expiresAt == nil {
+		return &time.Time{}, nil
+	}
+	return expiresAt, nil
+}
+
+// shortURL is the public short link of link: on its custom domain, or under the base URL
+// when it has none.
+func (h *Handlers) shortURL(link *model.URL) string {
+	if link.Domain == "" {
+		shortURL, _ := url.JoinPath(h.baseURL, "s", link.ShortCode)
+		return shortURL
+	}
+	target := url.URL{Scheme: h.baseScheme, Host: link.Domain, Path: "/s/" + link.ShortCode}
+	return target.String()
+}
+
+func (h *Handlers) toURLResponse(link *model.URL) URLResponse {
+	shortURL := h.shortURL(link)
+	return URLResponse{
+		OriginalURL:    link.OriginalURL,
+		ShortURL:       shortURL,
+		Domain:         link.Domain,
+		Title:          link.Title,
+		Description:    link.Description,
+		Metadata:       link.Metadata,
+		CreatedBy:      link.CreatedBy,
+		RedirectStatus: link.RedirectStatus,
+		Suspicious:     link.Suspicious,
+		ExpiresAt:      link.ExpiresAt,
+	}
+}
+
+// DeleteURL handles the request to delete a short URL and its analytics.
+func (h *Handlers) DeleteURL(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	if err := h.urlService.DeleteURL(c.Request.Context(), c.Query(linkDomainParam), shortCode); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to delete short URL")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete short URL"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // Redirect handles the redirection from a short URL to the original URL.
 func (h *Handlers) Redirect(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
 	shortCode := c.Param("short_code")
-	userAgent := c.Request.UserAgent()
-	ipAddress := c.ClientIP()
+	visit := model.Click{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+		Referrer:  c.Request.Referer(),
+		Country:   h.clientCountry(c),
+		Source:    clickSource(c),
+	}
 
-	gotURL, err := h.urlService.ProcessRedirect(c.Request.Context(), shortCode, userAgent, ipAddress)
+	var (
+		gotURL *model.URL
+		err    error
+	)
+	confirmation := c.Query(confirmParam)
+	if c.Request.Method == http.MethodHead {
+		// HEAD requests, e.g. from link checkers, see the redirect without counting as a click.
+		gotURL, err = h.urlService.ResolveShortCode(c.Request.Context(), c.Request.Host, shortCode)
+	} else {
+		gotURL, err = h.urlService.ProcessRedirect(c.Request.Context(), c.Request.Host, shortCode, visit, confirmation)
+	}
 	if err != nil {
 		if errors.Is(err, repo.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
 			return
 		}
-		h.logger.Error().Err(err).Str("short_code", shortCode).Msg("Failed to process redirect")
+		if errors.Is(err, service.ErrLinkExpired) {
+			c.JSON(http.StatusGone, ErrorResponse{Error: "Short URL has expired"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to process redirect")
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
 		return
 	}
 
-	c.Redirect(http.StatusMovedPermanently, gotURL.OriginalURL)
+	if gotURL.Suspicious && !h.urlService.Confirmed(gotURL, confirmation) {
+		// Suspicious links go through the preview page, which warns before continuing.
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, previewPath(gotURL, visit.Source))
+		return
+	}
+
+	status, cacheControl := h.redirects.forLink(gotURL)
+	c.Header("Cache-Control", cacheControl)
+	c.Redirect(status, gotURL.OriginalURL)
 }
 
 // GetAnalytics handles the request to fetch analytics for a short URL.
 func (h *Handlers) GetAnalytics(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
 	shortCode := c.Param("short_code")
 
-	report, err := h.analyticsService.GetFullAnalyticsReport(c.Request.Context(), shortCode)
+	var params AnalyticsQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := model.AnalyticsQuery{
+		From:   params.From,
+		To:     params.To,
+		Period: params.Period,
+		Window: params.Window,
+	}
+
+	report, err := h.analyticsService.GetFullAnalyticsReport(c.Request.Context(), c.Query(linkDomainParam), shortCode, query)
 	if err != nil {
 		if errors.Is(err, repo.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Analytics not found for this URL"})
 			return
 		}
-		h.logger.Error().Err(err).Str("short_code", shortCode).Msg("Failed to get analytics")
+		if errors.Is(err, service.ErrInvalidAnalyticsQuery) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to get analytics")
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve analytics"})
 		return
 	}
 
 	// Map domain model to response DTO
-	clicksByDay := make([]StatItem, len(report.ClicksByDay))
-	for i, stat := range report.ClicksByDay {
-		clicksByDay[i] = StatItem{Key: stat.Key, Value: stat.Value}
-	}
 	clicksByUserAgent := make([]StatItem, len(report.ClicksByUserAgent))
 	for i, stat := range report.ClicksByUserAgent {
 		clicksByUserAgent[i] = StatItem{Key: stat.Key, Value: stat.Value}
@@ -109,14 +422,350 @@ func (h *Handlers) GetAnalytics(c *gin.Context) {
 	for i, click := range report.RecentClicks {
 		recentClicks[i] = ClickDTO{Timestamp: click.CreatedAt, UserAgent: click.UserAgent}
 	}
-	shortURL, _ := url.JoinPath(h.baseURL, "s", report.URL.ShortCode)
+	shortURL := h.shortURL(&report.URL)
 
 	c.JSON(http.StatusOK, AnalyticsResponse{
 		OriginalURL:       report.URL.OriginalURL,
 		ShortURL:          shortURL,
 		TotalClicks:       report.TotalClicks,
-		ClicksByDay:       clicksByDay,
+		ClicksOverTime:    toTimeSeriesDTO(report.ClicksOverTime),
 		ClicksByUserAgent: clicksByUserAgent,
 		RecentClicks:      recentClicks,
 	})
 }
+
+// ListClicks handles the request to page through the raw clicks of a short URL.
+func (h *Handlers) ListClicks(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	var params ClickListParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	cursor, err := decodeClickCursor(params.Cursor)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := model.ClickFilter{
+		From:      params.From,
+		To:        params.To,
+		IsBot:     params.Bot,
+		UserAgent: params.UserAgent,
+		After:     cursor,
+		Limit:     params.Limit,
+	}
+
+	page, err := h.analyticsService.ListClicks(c.Request.Context(), c.Query(linkDomainParam), shortCode, filter)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to list clicks")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list clicks"})
+		return
+	}
+
+	clicks := make([]ClickDetailDTO, len(page.Clicks))
+	for i, click := range page.Clicks {
+		clicks[i] = ClickDetailDTO{
+			ID:        click.ID,
+			Timestamp: click.CreatedAt,
+			UserAgent: click.UserAgent,
+			IPAddress: click.IPAddress,
+			IsBot:     click.IsBot,
+			Source:    click.Source,
+		}
+	}
+
+	c.JSON(http.StatusOK, ClickListResponse{
+		Clicks:     clicks,
+		NextCursor: encodeClickCursor(page.Next),
+	})
+}
+
+// ExportClicks handles the request to stream all clicks of a short URL as CSV or NDJSON.
+// Rows are written as they are read from the database, so memory use does not grow with
+// the size of the export.
+func (h *Handlers) ExportClicks(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	var params ClickExportParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if params.Format == "" {
+		params.Format = exportFormatCSV
+	}
+
+	columns, err := parseExportColumns(params.Columns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := model.ClickFilter{
+		From:  params.From,
+		To:    params.To,
+		IsBot: params.Bot,
+	}
+	filename := fmt.Sprintf("clicks-%s-%s.%s", shortCode, time.Now().UTC().Format("20060102"), params.Format)
+	exporter := newClickExporter(c, params.Format, columns)
+
+	err = h.analyticsService.ExportClicks(c.Request.Context(), c.Query(linkDomainParam), shortCode, filter, func(click model.Click) error {
+		if err := exporter.begin(filename); err != nil {
+			return err
+		}
+		return exporter.write(click)
+	})
+	if err != nil && !exporter.started {
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to export clicks")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export clicks"})
+		return
+	}
+	if err != nil {
+		// The status line is already sent, so the error can only be logged.
+		log.Error().Err(err).Str("short_code", shortCode).Int("rows", exporter.rows).Msg("Click export interrupted")
+		return
+	}
+
+	if err := exporter.begin(filename); err != nil {
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to write export header")
+		return
+	}
+	if err := exporter.flush(); err != nil {
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to flush export")
+	}
+}
+
+// GetPivot handles the request to break down clicks by a time period and a secondary dimension.
+func (h *Handlers) GetPivot(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	var params PivotQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := model.PivotQuery{
+		AnalyticsQuery: model.AnalyticsQuery{From: params.From, To: params.To, Period: params.Period},
+		Dimension:      params.By,
+		Top:            params.Top,
+	}
+
+	report, err := h.analyticsService.GetPivotReport(c.Request.Context(), c.Query(linkDomainParam), shortCode, query)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidAnalyticsQuery) || errors.Is(err, service.ErrUnsupportedDimension) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to get pivot report")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve analytics"})
+		return
+	}
+
+	resp := PivotResponse{
+		Period:    report.Period,
+		Dimension: report.Dimension,
+		From:      report.From,
+		To:        report.To,
+	}
+	if params.Layout == "long" {
+		resp.Rows = make([]PivotRowDTO, len(report.Rows))
+		for i, row := range report.Rows {
+			resp.Rows[i] = PivotRowDTO{Bucket: row.TimeKey, Key: row.GroupKey, Value: row.Value}
+		}
+	} else {
+		resp.Buckets = report.Buckets
+		resp.Series = make([]PivotSeriesDTO, len(report.Series))
+		for i, series := range report.Series {
+			resp.Series[i] = PivotSeriesDTO{Key: series.Key, Total: series.Total, Values: series.Values}
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetTopLinks handles the request for the links with the most clicks in a time window.
+func (h *Handlers) GetTopLinks(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	var params TopLinksParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := model.TopLinksQuery{
+		From:   params.From,
+		To:     params.To,
+		SortBy: params.Sort,
+		Limit:  params.Limit,
+	}
+
+	stats, err := h.analyticsService.GetTopLinks(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAnalyticsQuery) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed to get top links")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve analytics"})
+		return
+	}
+
+	links := make([]LinkStatDTO, len(stats))
+	for i, stat := range stats {
+		shortURL := h.shortURL(&stat.URL)
+		links[i] = LinkStatDTO{
+			OriginalURL:  stat.URL.OriginalURL,
+			ShortURL:     shortURL,
+			CreatedAt:    stat.URL.CreatedAt,
+			TotalClicks:  stat.TotalClicks,
+			UniqueClicks: stat.UniqueClicks,
+		}
+	}
+
+	c.JSON(http.StatusOK, TopLinksResponse{Links: links})
+}
+
+// GetOverview handles the request for account-wide analytics across all links.
+func (h *Handlers) GetOverview(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	var params AnalyticsQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := model.AnalyticsQuery{
+		From:   params.From,
+		To:     params.To,
+		Period: params.Period,
+		Window: params.Window,
+	}
+
+	overview, err := h.analyticsService.GetOverview(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAnalyticsQuery) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed to get analytics overview")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, OverviewResponse{
+		From:           overview.From,
+		To:             overview.To,
+		LinksCreated:   overview.LinksCreated,
+		TotalLinks:     overview.TotalLinks,
+		TotalClicks:    overview.TotalClicks,
+		UniqueVisitors: overview.UniqueVisitors,
+		ClicksOverTime: toTimeSeriesDTO(overview.ClicksOverTime),
+	})
+}
+
+// LiveClicks streams click events of a short URL as Server-Sent Events.
+// Clients reconnecting with a Last-Event-ID header receive the buffered events they missed.
+func (h *Handlers) LiveClicks(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+	ctx := c.Request.Context()
+
+	events, err := h.liveService.Subscribe(ctx, c.Query(linkDomainParam), shortCode, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		if errors.Is(err, service.ErrTooManyStreams) {
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: "Too many live streams, try again later"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to open live stream")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to open live stream"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(h.liveService.Heartbeat())
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to marshal live click event")
+				return true
+			}
+			_, err = fmt.Fprintf(w, "id: %s\nevent: click\ndata: %s\n\n", event.ID, data)
+			return err == nil
+		}
+	})
+}
+
+// clientCountry returns the upper-case ISO 3166-1 alpha-2 code from the configured header,
+// or an empty string when the header is missing or malformed.
+func (h *Handlers) clientCountry(c *gin.Context) string {
+	if h.countryHeader == "" {
+		return ""
+	}
+	country := strings.ToUpper(strings.TrimSpace(c.GetHeader(h.countryHeader)))
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return ""
+	}
+	return country
+}
+
+// toTimeSeriesDTO maps a domain time series to its response representation.
+func toTimeSeriesDTO(series model.TimeSeries) TimeSeriesDTO {
+	points := make([]TimeSeriesPointDTO, len(series.Points))
+	for i, p := range series.Points {
+		points[i] = TimeSeriesPointDTO{Bucket: p.Bucket, Value: p.Value, MovingAverage: p.MovingAverage}
+	}
+
+	dto := TimeSeriesDTO{
+		Period:        series.Period,
+		From:          series.From,
+		To:            series.To,
+		Total:         series.Total,
+		PreviousTotal: series.PreviousTotal,
+		ChangePercent: series.ChangePercent,
+		Points:        points,
+	}
+	if series.Peak != nil {
+		dto.Peak = &TimeSeriesPointDTO{Bucket: series.Peak.Bucket, Value: series.Peak.Value, MovingAverage: series.Peak.MovingAverage}
+	}
+	return dto
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sync/atomic"
+	"time"
+)
+
+// HeaderRequestID carries the correlation ID of a request, both inbound and outbound.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs so they cannot bloat the logs.
+const maxRequestIDLength = 128
+
+// RequestLogger assigns every request an ID, makes it available to all layers through the
+// request context and writes one structured access log line per request.
+// An incoming X-Request-ID is reused when it is well-formed, otherwise a new ID is generated.
+// Requests to the routes in sampleEvery are logged only once every N requests; warnings and
+// errors are always logged.
+func RequestLogger(base zerolog.Logger, sampleEvery map[string]uint64) gin.HandlerFunc {
+	counters := make(map[string]*atomic.Uint64, len(sampleEvery))
+	for route := range sampleEvery {
+		counters[route] = new(atomic.Uint64)
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(HeaderRequestID, requestID)
+
+		route := c.FullPath()
+		quiet := false
+		if n := sampleEvery[route]; n > 1 {
+			quiet = counters[route].Add(1)%n != 1
+		}
+
+		ctx := logger.ContextWithRequest(c.Request.Context(), requestID, quiet)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", requestID))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		log := logger.FromContext(ctx, base)
+		event := log.Info()
+		switch {
+		case status >= 500:
+			event = log.Error()
+		case status >= 400:
+			event = log.Warn()
+		}
+		if len(c.Errors) > 0 {
+			event = event.Str("errors", c.Errors.String())
+		}
+
+		event.
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("route", route).
+			Int("status", status).
+			Int("bytes", max(c.Writer.Size(), 0)).
+			Dur("latency", time.Since(start)).
+			Str("client_ip", c.ClientIP()).
+			Str("user_agent", c.Request.UserAgent()).
+			Msg("HTTP request")
+	}
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII characters within the length limit.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random 128-bit request ID.
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
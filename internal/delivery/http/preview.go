@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/service"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// previewRoute is the public page showing where a short link goes.
+const previewRoute = "/p/:short_code"
+
+// confirmParam carries the short-lived confirmation token of the preview page; a valid one
+// lets the redirect of a suspicious link through.
+const confirmParam = "confirm"
+
+// previewTemplate renders the preview page. html/template escapes every value and replaces
+// destinations with unsafe schemes, so the page cannot be used to inject markup.
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 3rem auto; padding: 0 1rem; color: #1f2328; }
+.warning { background: #fff1e5; border: 1px solid #d1242f; border-radius: 6px; padding: 1rem; margin-bottom: 1.5rem; }
+.destination { word-break: break-all; font-family: ui-monospace, monospace; background: #f6f8fa; padding: .75rem; border-radius: 6px; }
+.meta { color: #59636e; }
+.continue { display: inline-block; margin-top: 1.5rem; padding: .6rem 1.2rem; border-radius: 6px; background: #1f883d; color: #fff; text-decoration: none; }
+.continue.danger { background: #d1242f; }
+</style>
+</head>
+<body>
+{{if .Suspicious}}<div class="warning" role="alert"><strong>Warning:</strong> this link has been flagged as suspicious. It may lead to a deceptive or harmful site. Only continue if you trust the destination.</div>
+{{end}}<h1>{{if .Title}}{{.Title}}{{else}}Where this link goes{{end}}</h1>
+<p>The short link <strong>{{.ShortCode}}</strong> redirects to:</p>
+<div class="destination">{{.Destination}}</div>
+<p class="meta">{{if .Description}}{{.Description}}<br>{{end}}Created {{.CreatedAt.Format "January 2, 2006"}}</p>
+<a class="continue{{if .Suspicious}} danger{{end}}" href="{{.ContinueURL}}" rel="noopener noreferrer">Continue to site</a>
+</body>
+</html>
+`))
+
+// previewPage holds the values rendered by previewTemplate.
+type previewPage struct {
+	ShortCode   string
+	Title       string
+	Description string
+	Destination string
+	CreatedAt   time.Time
+	Suspicious  bool
+	ContinueURL string
+}
+
+// Preview handles the request to show where a short link goes without following it.
+// No click is recorded; the continue button leads through the regular redirect.
+func (h *Handlers) Preview(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	link, err := h.urlService.ResolveShortCode(c.Request.Context(), c.Request.Host, shortCode)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		if errors.Is(err, service.ErrLinkExpired) {
+			c.JSON(http.StatusGone, ErrorResponse{Error: "Short URL has expired"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to load link preview")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Internal server error"})
+		return
+	}
+
+	page := previewPage{
+		ShortCode:   link.ShortCode,
+		Title:       link.Title,
+		Description: link.Description,
+		Destination: link.OriginalURL,
+		CreatedAt:   link.CreatedAt,
+		Suspicious:  link.Suspicious,
+		ContinueURL: continueURL(link, h.urlService.ConfirmationToken(link), clickSource(c)),
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := previewTemplate.Execute(c.Writer, page); err != nil {
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to render link preview")
+	}
+}
+
+// continueURL is the redirect of link, confirmed with token so that suspicious links are let
+// through. The click source of the visit that led to the preview is passed on.
+func continueURL(link *model.URL, token, source string) string {
+	query := url.Values{confirmParam: {token}}
+	if source != "" {
+		query.Set(sourceParam, source)
+	}
+	target := url.URL{Path: "/s/" + link.ShortCode, RawQuery: query.Encode()}
+	return target.String()
+}
+
+// previewPath is the path of link's preview page, keeping the click source of the visit.
+func previewPath(link *model.URL, source string) string {
+	target := url.URL{Path: "/p/" + link.ShortCode}
+	if source != "" {
+		target.RawQuery = url.Values{sourceParam: {source}}.Encode()
+	}
+	return target.String()
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/service"
+	"github.com/skip2/go-qrcode"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	qrFormatPNG = "png"
+	qrFormatSVG = "svg"
+
+	defaultQRSize   = 256
+	defaultQRMargin = 4
+	defaultQRECC    = "M"
+)
+
+// sourceParam carries the click source of a short link, e.g. "src=qr" in the URL encoded
+// into QR codes. Only known sources are recorded.
+const sourceParam = "src"
+
+// qrLevels maps the ecc query parameter to a QR error correction level.
+var qrLevels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// clickSource returns the click source marked on the request, or "" for a plain link click.
+func clickSource(c *gin.Context) string {
+	if c.Query(sourceParam) == model.ClickSourceQR {
+		return model.ClickSourceQR
+	}
+	return ""
+}
+
+// GetQRCode handles the request to render the short URL of a link as a QR code.
+// The encoded URL carries the QR click source, so scans can be told apart in analytics.
+// The image only depends on the short URL and the rendering options, which the ETag covers.
+func (h *Handlers) GetQRCode(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	var params QRQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if params.Format == "" {
+		params.Format = qrFormatPNG
+	}
+	if params.Size == 0 {
+		params.Size = defaultQRSize
+	}
+	margin := defaultQRMargin
+	if params.Margin != nil {
+		margin = *params.Margin
+	}
+	ecc := strings.ToUpper(params.ECC)
+	if ecc == "" {
+		ecc = defaultQRECC
+	}
+
+	link, err := h.urlService.GetLink(c.Request.Context(), c.Query(linkDomainParam), shortCode)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to get link for QR code")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to render QR code"})
+		return
+	}
+
+	content := h.qrContent(link)
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\n%s\n%d\n%d\n%s", content, params.Format, params.Size, margin, ecc))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, max-age=86400")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	code, err := qrcode.New(content, qrLevels[ecc])
+	if err != nil {
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to encode QR code")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to render QR code"})
+		return
+	}
+	code.DisableBorder = true
+	modules := code.Bitmap()
+	if len(modules)+2*margin > params.Size {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("size must be at least %d pixels for this link", len(modules)+2*margin)})
+		return
+	}
+
+	if params.Format == qrFormatSVG {
+		c.Data(http.StatusOK, "image/svg+xml", renderQRSVG(modules, params.Size, margin))
+		return
+	}
+	body, err := renderQRPNG(modules, params.Size, margin)
+	if err != nil {
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to render QR code")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to render QR code"})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", body)
+}
+
+// qrContent is the short URL of link as encoded into its QR code.
+func (h *Handlers) qrContent(link *model.URL) string {
+	shortURL := h.shortURL(link)
+	return shortURL + "?" + url.Values{sourceParam: {model.ClickSourceQR}}.Encode()
+}
+
+// etagMatches reports whether an If-None-Match header lists etag or "*".
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// renderQRPNG draws modules, surrounded by margin light modules, on a size×size image.
+// Modules are scaled by a whole number of pixels; the leftover pixels widen the margin.
+func renderQRPNG(modules [][]bool, size, margin int) ([]byte, error) {
+	total := len(modules) + 2*margin
+	scale := size / total
+	offset := (size-scale*total)/2 + margin*scale
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for py := range scale {
+				for px := range scale {
+					img.SetColorIndex(offset+x*scale+px, offset+y*scale+py, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderQRSVG draws modules, surrounded by margin light modules, as a size×size SVG image.
+// The view box is measured in modules, so the image scales without blurring.
+func renderQRSVG(modules [][]bool, size, margin int) []byte {
+	total := len(modules) + 2*margin
+
+	var path strings.Builder
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x+margin, y+margin)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/>`, total, total)
+	fmt.Fprintf(&buf, `<path d="%s" fill="#000"/>`, path.String())
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/metrics"
+	"github.com/ilindan-dev/shortener/pkg/keybuilder"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Route groups with their own rate limits.
+const (
+	RateLimitGroupAPI      = "api"
+	RateLimitGroupShorten  = "shorten"
+	RateLimitGroupRedirect = "redirect"
+)
+
+// RateLimiter enforces the per route group limits configured in rate_limit.
+// Limits are tracked per client IP and, when the request carries one, per API key.
+// If the backing store is unavailable requests are let through (fail open), which is
+// counted in shortener_ratelimit_failures_total and logged.
+type RateLimiter struct {
+	limiter   repo.RateLimiter
+	cfg       config.RateLimitConfig
+	decisions *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+	logger    zerolog.Logger
+}
+
+// rateLimitCheck is a single limit applied to a request, e.g. the per-IP limit of the client.
+type rateLimitCheck struct {
+	scope string
+	id    string
+	rule  config.RateLimitRule
+}
+
+// NewRateLimiter creates a new instance of RateLimiter.
+func NewRateLimiter(limiter repo.RateLimiter, cfg *config.Config, logger *zerolog.Logger) *RateLimiter {
+	return &RateLimiter{
+		limiter: limiter,
+		cfg:     cfg.RateLimit,
+		decisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "ratelimit",
+			Name:      "decisions_total",
+			Help:      "Rate limit checks by route group, scope and result (allowed or limited).",
+		}, []string{"group", "scope", "result"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "ratelimit",
+			Name:      "failures_total",
+			Help:      "Rate limit checks that failed and let the request through.",
+		}, []string{"group"}),
+		// Failures happen on every request while Redis is down, so their logs are throttled.
+		logger: logger.With().Str("layer", "rate_limiter").Logger().
+			Sample(&zerolog.BurstSampler{Burst: 1, Period: 10 * time.Second}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (l *RateLimiter) Describe(ch chan<- *prometheus.Desc) {
+	l.decisions.Describe(ch)
+	l.failures.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (l *RateLimiter) Collect(ch chan<- prometheus.Metric) {
+	l.decisions.Collect(ch)
+	l.failures.Collect(ch)
+}
+
+// Middleware returns the handler enforcing the limits of a route group. Responses carry
+// X-RateLimit-* headers of the most restrictive limit; rejected requests get 429 with Retry-After.
+func (l *RateLimiter) Middleware(group string) gin.HandlerFunc {
+	rules, ok := l.cfg.Groups[group]
+	if !l.cfg.Enabled || !ok || (rules.PerIP.Rate <= 0 && rules.PerAPIKey.Rate <= 0) {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		checks := []rateLimitCheck{{scope: "ip", id: c.ClientIP(), rule: rules.PerIP}}
+		if key := apiKeyFromRequest(c); key != "" {
+			// The key itself is a secret, so only its hash ends up in Redis.
+			sum := sha256.Sum256([]byte(key))
+			checks = append(checks, rateLimitCheck{scope: "api_key", id: hex.EncodeToString(sum[:]), rule: rules.PerAPIKey})
+		}
+
+		var tightest *model.RateLimitResult
+		for _, check := range checks {
+			if check.rule.Rate <= 0 {
+				continue
+			}
+
+			limit := model.RateLimit{Rate: check.rule.Rate, Period: check.rule.Period, Burst: check.rule.Burst}
+			result, err := l.limiter.Allow(c.Request.Context(), keybuilder.RateLimitKey(group, check.scope, check.id), limit)
+			if err != nil {
+				l.failures.WithLabelValues(group).Inc()
+				logger.FromContext(c.Request.Context(), l.logger).Warn().Err(err).Str("group", group).Msg("Rate limit check failed, letting request through")
+				continue
+			}
+
+			if !result.Allowed {
+				l.decisions.WithLabelValues(group, check.scope, "limited").Inc()
+				setRateLimitHeaders(c, result)
+				c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, ErrorResponse{Error: "Rate limit exceeded"})
+				return
+			}
+
+			l.decisions.WithLabelValues(group, check.scope, "allowed").Inc()
+			if tightest == nil || result.Remaining < tightest.Remaining {
+				tightest = &result
+			}
+		}
+
+		if tightest != nil {
+			setRateLimitHeaders(c, *tightest)
+		}
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders describes the state of a limit. X-RateLimit-Limit is the configured
+// number of requests per period, which X-RateLimit-Policy spells out together with the burst
+// that X-RateLimit-Remaining counts down from; X-RateLimit-Reset is in seconds.
+func setRateLimitHeaders(c *gin.Context, result model.RateLimitResult) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Policy", fmt.Sprintf("%d;w=%d;burst=%d", result.Limit, ceilSeconds(result.Period), result.Burst))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+}
+
+// ceilSeconds rounds a duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"github.com/rs/zerolog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeRateLimiter allows every request and records the keys it was asked about.
+type fakeRateLimiter struct {
+	keys []string
+}
+
+func (l *fakeRateLimiter) Allow(_ context.Context, key string, limit model.RateLimit) (model.RateLimitResult, error) {
+	l.keys = append(l.keys, key)
+	return model.RateLimitResult{
+		Allowed:    true,
+		Limit:      limit.Rate,
+		Period:     limit.Period,
+		Burst:      limit.Burst,
+		Remaining:  limit.Burst - 1,
+		ResetAfter: time.Second,
+	}, nil
+}
+
+func newRateLimitedRouter(t *testing.T, store *fakeRateLimiter, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		Groups: map[string]config.RateLimitGroupConfig{
+			RateLimitGroupShorten: {PerIP: config.RateLimitRule{Rate: 30, Period: time.Minute, Burst: 10}},
+		},
+	}}
+	logger := zerolog.Nop()
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+	router.POST("/shorten", NewRateLimiter(store, cfg, &logger).Middleware(RateLimitGroupShorten), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	router := newRateLimitedRouter(t, &fakeRateLimiter{}, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/shorten", nil))
+
+	want := map[string]string{
+		"X-RateLimit-Limit":     "30",
+		"X-RateLimit-Policy":    "30;w=60;burst=10",
+		"X-RateLimit-Remaining": "9",
+		"X-RateLimit-Reset":     "1",
+	}
+	for header, value := range want {
+		if got := rec.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+func TestRateLimitIgnoresForwardedForFromUntrustedClients(t *testing.T) {
+	store := &fakeRateLimiter{}
+	router := newRateLimitedRouter(t, store, nil)
+	for _, forwarded := range []string{"1.1.1.1", "2.2.2.2"} {
+		req := httptest.NewRequest(http.MethodPost, "/shorten", nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if len(store.keys) != 2 || store.keys[0] != store.keys[1] {
+		t.Fatalf("keys = %v, want the same per-IP key for both requests", store.keys)
+	}
+}
+
+func TestRateLimitUsesForwardedForFromTrustedProxies(t *testing.T) {
+	store := &fakeRateLimiter{}
+	router := newRateLimitedRouter(t, store, []string{"10.0.0.0/8"})
+	for _, forwarded := range []string{"1.1.1.1", "2.2.2.2"} {
+		req := httptest.NewRequest(http.MethodPost, "/shorten", nil)
+		req.RemoteAddr = "10.0.0.2:5000"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if len(store.keys) != 2 || store.keys[0] == store.keys[1] {
+		t.Fatalf("keys = %v, want a key per forwarded client", store.keys)
+	}
+}
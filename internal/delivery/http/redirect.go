@@ -0,0 +1,39 @@
+package http
+
+import (
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"net/http"
+)
+
+// RedirectPolicy picks the status and Cache-Control header of a link's redirect.
+// Permanent redirects may be cached by clients, which then stop reaching the service, so
+// later destination changes and repeat visits go unnoticed. Temporary redirects are never cached.
+type RedirectPolicy struct {
+	defaultStatus         int
+	permanentCacheControl string
+}
+
+// NewRedirectPolicy creates a new instance of RedirectPolicy.
+func NewRedirectPolicy(cfg *config.Config) (*RedirectPolicy, error) {
+	if !model.ValidRedirectStatus(cfg.Redirect.DefaultStatus) {
+		return nil, fmt.Errorf("redirect: invalid default status %d", cfg.Redirect.DefaultStatus)
+	}
+	return &RedirectPolicy{
+		defaultStatus:         cfg.Redirect.DefaultStatus,
+		permanentCacheControl: fmt.Sprintf("public, max-age=%d", int(cfg.Redirect.PermanentMaxAge.Seconds())),
+	}, nil
+}
+
+// forLink returns the status and Cache-Control header of link's redirect.
+func (p *RedirectPolicy) forLink(link *model.URL) (int, string) {
+	status := link.RedirectStatus
+	if status == 0 {
+		status = p.defaultStatus
+	}
+	if status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect {
+		return status, p.permanentCacheControl
+	}
+	return status, "no-store"
+}
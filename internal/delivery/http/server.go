@@ -1,9 +1,14 @@
 package http
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
 )
 
@@ -14,25 +19,54 @@ type Server struct {
 }
 
 // NewServer creates and configures a new Gin server.
-func NewServer(cfg *config.Config, handlers *Handlers, logger *zerolog.Logger) *Server {
+func NewServer(
+	cfg *config.Config,
+	handlers *Handlers,
+	limiter *RateLimiter,
+	auth *Authenticator,
+	httpMetrics *Metrics,
+	registry *prometheus.Registry,
+	tp trace.TracerProvider,
+	logger *zerolog.Logger,
+) (*Server, error) {
 	log := logger.With().Str("layer", "http_server").Logger()
 	log.Info().Msg("Initializing HTTP server")
 
 	gin.SetMode(cfg.HTTP.GinMode)
 	router := gin.New()
-	router.Use(gin.Recovery())
+	// Without trusted proxies gin would take the client IP from any X-Forwarded-For header,
+	// which lets clients pick a new IP - and rate limit bucket - per request.
+	if err := router.SetTrustedProxies(cfg.HTTP.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid http.trusted_proxies: %w", err)
+	}
+	// Recovery is innermost so that tracing, access logs and metrics see panics as 500s.
+	router.Use(
+		otelgin.Middleware(cfg.Tracing.ServiceName,
+			otelgin.WithTracerProvider(tp),
+			otelgin.WithFilter(func(r *http.Request) bool {
+				return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+			}),
+		),
+		RequestLogger(
+			logger.With().Str("layer", "http_access").Logger(),
+			map[string]uint64{redirectRoute: cfg.Logger.RedirectSampleEvery},
+		),
+		httpMetrics.Middleware(),
+		gin.Recovery(),
+	)
 
 	log.Info().Msg("Registering API routes")
-	handlers.RegisterRoutes(router)
+	handlers.RegisterRoutes(router, limiter, auth)
 
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	router.GET("/metrics", gin.WrapH(metrics.Handler(registry)))
 
 	server := &http.Server{
 		Addr:    cfg.HTTP.Port,
 		Handler: router,
 	}
 
-	return &Server{server, log}
+	return &Server{server, log}, nil
 }
@@ -0,0 +1,307 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/service"
+	"net/http"
+	"strconv"
+)
+
+// SetLinkTags handles the request to replace the tags of a short URL.
+func (h *Handlers) SetLinkTags(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	var req SetTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	link, err := h.urlService.SetTags(c.Request.Context(), c.Query(linkDomainParam), shortCode, req.Tags)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTag) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL not found"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to set link tags")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to set link tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.toLinkDTO(link))
+}
+
+// MoveLink handles the request to move a short URL into a folder or out of its folder.
+func (h *Handlers) MoveLink(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	shortCode := c.Param("short_code")
+
+	var req MoveLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	link, err := h.urlService.MoveToFolder(c.Request.Context(), c.Query(linkDomainParam), shortCode, req.FolderID)
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Short URL or folder not found"})
+			return
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to move link")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to move link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.toLinkDTO(link))
+}
+
+// ListDomains handles the request to list the hosts the links of the workspace can be served on.
+func (h *Handlers) ListDomains(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	domains, err := h.urlService.ListDomains(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed to list domains")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list domains"})
+		return
+	}
+
+	resp := DomainListResponse{
+		Default:   domains.Default,
+		Shared:    append([]string{}, domains.Shared...),
+		Workspace: make([]DomainDTO, len(domains.Workspace)),
+	}
+	for i, domain := range domains.Workspace {
+		resp.Workspace[i] = DomainDTO{Host: domain.Host, CreatedAt: domain.CreatedAt}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListTags handles the request to list the tags of the workspace.
+func (h *Handlers) ListTags(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	tags, err := h.urlService.ListTags(c.Request.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list tags")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list tags"})
+		return
+	}
+
+	resp := make([]TagDTO, len(tags))
+	for i, tag := range tags {
+		resp[i] = TagDTO{Name: tag.Name, LinkCount: tag.LinkCount, CreatedAt: tag.CreatedAt}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteTag handles the request to remove a tag from the workspace and all of its links.
+func (h *Handlers) DeleteTag(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	tag := c.Param("tag")
+
+	if err := h.urlService.DeleteTag(c.Request.Context(), tag); err != nil {
+		if errors.Is(err, service.ErrInvalidTag) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Tag not found"})
+			return
+		}
+		log.Error().Err(err).Str("tag", tag).Msg("Failed to delete tag")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete tag"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTagAnalytics handles the request for the aggregated analytics of all links carrying a tag.
+func (h *Handlers) GetTagAnalytics(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	tag := c.Param("tag")
+
+	var params AnalyticsQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	query := model.AnalyticsQuery{
+		From:   params.From,
+		To:     params.To,
+		Period: params.Period,
+		Window: params.Window,
+	}
+
+	report, err := h.analyticsService.GetTagReport(c.Request.Context(), tag, query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAnalyticsQuery) || errors.Is(err, service.ErrInvalidTag) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Tag not found"})
+			return
+		}
+		log.Error().Err(err).Str("tag", tag).Msg("Failed to get tag analytics")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve analytics"})
+		return
+	}
+
+	links := make([]LinkStatDTO, len(report.TopLinks))
+	for i, stat := range report.TopLinks {
+		shortURL := h.shortURL(&stat.URL)
+		links[i] = LinkStatDTO{
+			OriginalURL:  stat.URL.OriginalURL,
+			ShortURL:     shortURL,
+			CreatedAt:    stat.URL.CreatedAt,
+			TotalClicks:  stat.TotalClicks,
+			UniqueClicks: stat.UniqueClicks,
+		}
+	}
+
+	c.JSON(http.StatusOK, TagReportResponse{
+		Tag:            report.Tag.Name,
+		LinkCount:      report.Tag.LinkCount,
+		From:           report.From,
+		To:             report.To,
+		TotalClicks:    report.TotalClicks,
+		UniqueVisitors: report.UniqueVisitors,
+		ClicksOverTime: toTimeSeriesDTO(report.ClicksOverTime),
+		TopLinks:       links,
+	})
+}
+
+// CreateFolder handles the request to create a folder in the workspace.
+func (h *Handlers) CreateFolder(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	var req CreateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(c.Request.Context(), req.Name)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFolder) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrDuplicateRecord) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "A folder with this name already exists"})
+			return
+		}
+		log.Error().Err(err).Msg("Failed to create folder")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create folder"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toFolderDTO(folder))
+}
+
+// ListFolders handles the request to list the folders of the workspace.
+func (h *Handlers) ListFolders(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	folders, err := h.folderService.ListFolders(c.Request.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list folders")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list folders"})
+		return
+	}
+
+	resp := make([]FolderDTO, len(folders))
+	for i := range folders {
+		resp[i] = toFolderDTO(&folders[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteFolder handles the request to remove a folder; its links are kept.
+func (h *Handlers) DeleteFolder(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid folder ID"})
+		return
+	}
+
+	if err := h.folderService.DeleteFolder(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Folder not found"})
+			return
+		}
+		log.Error().Err(err).Int64("folder_id", id).Msg("Failed to delete folder")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete folder"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handlers) toLinkDTO(link *model.URL) LinkDTO {
+	shortURL := h.shortURL(link)
+	tags := link.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return LinkDTO{
+		ShortCode:      link.ShortCode,
+		ShortURL:       shortURL,
+		Domain:         link.Domain,
+		OriginalURL:    link.OriginalURL,
+		ClickCount:     link.ClickCount,
+		CreatedAt:      link.CreatedAt,
+		FolderID:       link.FolderID,
+		Tags:           tags,
+		Title:          link.Title,
+		Description:    link.Description,
+		Metadata:       link.Metadata,
+		CreatedBy:      link.CreatedBy,
+		RedirectStatus: link.RedirectStatus,
+		Suspicious:     link.Suspicious,
+		ExpiresAt:      link.ExpiresAt,
+	}
+}
+
+func toFolderDTO(folder *model.Folder) FolderDTO {
+	return FolderDTO{
+		ID:        folder.ID,
+		Name:      folder.Name,
+		LinkCount: folder.LinkCount,
+		CreatedAt: folder.CreatedAt,
+	}
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/service"
+	"net/http"
+	"strconv"
+)
+
+// CreateWebhook handles the request to register a new webhook subscription.
+func (h *Handlers) CreateWebhook(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	sub, err := h.webhookService.CreateSubscription(c.Request.Context(), model.WebhookSubscription{
+		URL:            req.URL,
+		Secret:         req.Secret,
+		Events:         req.Events,
+		ClickThreshold: req.ClickThreshold,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed to create webhook")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create webhook"})
+		return
+	}
+
+	resp := toWebhookResponse(sub)
+	resp.Secret = sub.Secret
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListWebhooks handles the request to list all webhook subscriptions.
+func (h *Handlers) ListWebhooks(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	subs, err := h.webhookService.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list webhooks")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list webhooks"})
+		return
+	}
+
+	resp := make([]WebhookResponse, len(subs))
+	for i := range subs {
+		resp[i] = toWebhookResponse(&subs[i])
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteWebhook handles the request to remove a webhook subscription.
+func (h *Handlers) DeleteWebhook(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid webhook ID"})
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), id); err != nil {
+		if errors.Is(err, service.ErrForbidden) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Webhook not found"})
+			return
+		}
+		log.Error().Err(err).Int64("webhook_id", id).Msg("Failed to delete webhook")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete webhook"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles the request to page through the delivery history of a webhook.
+func (h *Handlers) ListWebhookDeliveries(c *gin.Context) {
+	log := logger.FromContext(c.Request.Context(), h.logger)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid webhook ID"})
+		return
+	}
+
+	var params WebhookDeliveryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	filter := model.WebhookDeliveryFilter{
+		Status:   params.Status,
+		BeforeID: params.Before,
+		Limit:    params.Limit,
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), id, filter)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Webhook not found"})
+			return
+		}
+		log.Error().Err(err).Int64("webhook_id", id).Msg("Failed to list webhook deliveries")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list webhook deliveries"})
+		return
+	}
+
+	resp := WebhookDeliveryListResponse{Deliveries: make([]WebhookDeliveryDTO, len(deliveries))}
+	for i, d := range deliveries {
+		dto := WebhookDeliveryDTO{
+			ID:             d.ID,
+			EventType:      d.EventType,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			CreatedAt:      d.CreatedAt,
+			UpdatedAt:      d.UpdatedAt,
+		}
+		if d.Status == model.DeliveryPending {
+			dto.NextAttemptAt = &d.NextAttemptAt
+		}
+		resp.Deliveries[i] = dto
+	}
+	if len(deliveries) > 0 {
+		resp.NextBefore = deliveries[len(deliveries)-1].ID
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// toWebhookResponse maps a subscription to its response DTO without the secret.
+func toWebhookResponse(sub *model.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:             sub.ID,
+		URL:            sub.URL,
+		Events:         sub.Events,
+		ClickThreshold: sub.ClickThreshold,
+		Active:         sub.Active,
+		CreatedAt:      sub.CreatedAt,
+	}
+}
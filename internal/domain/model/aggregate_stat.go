@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // AggregatedStat is a generic structure for holding aggregated analytics data.
 // For example: Key="Chrome", Value=150 or Key="2025-09-08", Value=25.
 type AggregatedStat struct {
@@ -8,8 +10,9 @@ type AggregatedStat struct {
 }
 
 // AggregatedStatDetailed is a structure for holding multi-key aggregated data.
+// TimeKey is the start of a time bucket and GroupKey is the value of the secondary dimension.
 type AggregatedStatDetailed struct {
-	TimeKey string
-	UAKey   string
-	Value   int64
+	TimeKey  time.Time
+	GroupKey string
+	Value    int64
 }
@@ -3,7 +3,7 @@ package model
 type FullAnalyticsReport struct {
 	URL               URL
 	TotalClicks       int64
-	ClicksByDay       []AggregatedStat
+	ClicksOverTime    TimeSeries
 	ClicksByUserAgent []AggregatedStat
 	RecentClicks      []Click
 }
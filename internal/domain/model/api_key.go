@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// API key scopes. A write key may also read.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// APIKey grants access to the management and analytics API.
+// The secret itself is never stored; Prefix identifies the key in listings.
+type APIKey struct {
+	ID          int64
+	WorkspaceID int64
+	// UserID is the member the key acts on behalf of; nil for service keys.
+	UserID *int64
+	// Role is the workspace role the key acts with. It is resolved on authentication:
+	// the role of UserID in the workspace, or for service keys the role implied by Scope.
+	Role         string
+	Name         string
+	Prefix       string
+	Scope        string
+	RequestCount int64
+	LastUsedAt   *time.Time
+	CreatedAt    time.Time
+	RevokedAt    *time.Time
+}
+
+// Allows reports whether the key may perform operations requiring scope.
+func (k *APIKey) Allows(scope string) bool {
+	return k.Scope == scope || k.Scope == ScopeWrite
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited actions.
+const (
+	AuditLinkCreated       = "link.created"
+	AuditLinkUpdated       = "link.updated"
+	AuditLinkDeleted       = "link.deleted"
+	AuditTagDeleted        = "tag.deleted"
+	AuditFolderDeleted     = "folder.deleted"
+	AuditMemberAdded       = "member.added"
+	AuditMemberRoleChanged = "member.role_changed"
+	AuditAPIKeyCreated     = "api_key.created"
+	AuditAPIKeyRevoked     = "api_key.revoked"
+)
+
+// AuditEvent records who changed what in a workspace. Before and After hold JSON
+// snapshots of the changed record and are nil for creations and deletions respectively.
+type AuditEvent struct {
+	ID          int64
+	WorkspaceID int64
+	// Actor identifies the caller, e.g. "api_key:12" or "operator:alice".
+	Actor         string
+	ActorUserID   *int64
+	ActorAPIKeyID *int64
+	Action        string
+	ShortCode     string
+	Before        json.RawMessage
+	After         json.RawMessage
+	SourceIP      string
+	RequestID     string
+	CreatedAt     time.Time
+}
+
+// AuditFilter narrows down the audit trail of a workspace.
+type AuditFilter struct {
+	Action    string
+	ShortCode string
+	Actor     string
+	From      time.Time
+	To        time.Time
+	BeforeID  int64
+	Limit     int
+}
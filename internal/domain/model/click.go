@@ -2,11 +2,19 @@ package model
 
 import "time"
 
+// ClickSourceQR marks clicks that came from scanning a link's QR code.
+const ClickSourceQR = "qr"
+
 // Click is the domain model for a single redirect event.
 type Click struct {
 	ID        int64
 	URLID     int64
 	UserAgent string
 	IPAddress string
+	IsBot     bool
+	Referrer  string
+	Country   string
+	// Source tells where the visitor found the link, e.g. ClickSourceQR; empty for plain link clicks.
+	Source    string
 	CreatedAt time.Time
 }
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ClickEvent is the live notification published for every recorded redirect.
+// ID is assigned by the event stream and orders events of the same link.
+// Domain is empty for links on the base URL.
+type ClickEvent struct {
+	ID        string    `json:"id"`
+	ShortCode string    `json:"short_code"`
+	Domain    string    `json:"domain,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Browser   string    `json:"browser"`
+	OS        string    `json:"os"`
+	Device    string    `json:"device"`
+	IsBot     bool      `json:"is_bot"`
+	Country   string    `json:"country,omitempty"`
+	Referrer  string    `json:"referrer,omitempty"`
+	Source    string    `json:"source,omitempty"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// ClickCursor identifies the position of a click in a (created_at, id) keyset.
+type ClickCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// ClickFilter narrows down a paginated click listing.
+// Zero values disable the corresponding filter.
+type ClickFilter struct {
+	From      time.Time
+	To        time.Time
+	IsBot     *bool
+	UserAgent string
+	After     *ClickCursor
+	Limit     int
+}
+
+// ClickPage is a single page of a click listing.
+type ClickPage struct {
+	Clicks []Click
+	// Next is the cursor of the following page; nil on the last page.
+	Next *ClickCursor
+}
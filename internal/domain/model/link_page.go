@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// Link listing sort orders, both descending.
+const (
+	LinkSortCreatedAt  = "created_at"
+	LinkSortClickCount = "click_count"
+)
+
+// Link statuses of the link listing filter. Suspicious links were flagged by moderation and
+// are only followed through the preview page; active links are all others.
+const (
+	LinkStatusActive     = "active"
+	LinkStatusSuspicious = "suspicious"
+)
+
+// LinkCursor identifies the position of a link in the keyset of its sort order:
+// (created_at, id) or (click_count, id).
+type LinkCursor struct {
+	CreatedAt  time.Time
+	ClickCount int64
+	ID         int64
+}
+
+// LinkFilter narrows down a paginated link listing.
+// Zero values disable the corresponding filter.
+type LinkFilter struct {
+	From time.Time
+	To   time.Time
+	// Domain matches links whose destination host is the domain or one of its subdomains.
+	Domain string
+	// Search is a case-insensitive substring of the destination, short code, title or description.
+	Search string
+	// Tag keeps the links carrying the named tag.
+	Tag string
+	// FolderID keeps the links in the folder.
+	FolderID *int64
+	// Status keeps the links of a LinkStatus.
+	Status string
+	SortBy string
+	After  *LinkCursor
+	Limit  int
+}
+
+// LinkPage is a single page of a link listing.
+type LinkPage struct {
+	Links []URL
+	// Next is the cursor of the following page; nil on the last page.
+	Next *LinkCursor
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+const (
+	// SortByTotal ranks links by their total number of clicks.
+	SortByTotal = "total"
+	// SortByUnique ranks links by the number of distinct visitor IPs.
+	SortByUnique = "unique"
+)
+
+// LinkStat is a URL together with its click counts over a time window.
+type LinkStat struct {
+	URL          URL
+	TotalClicks  int64
+	UniqueClicks int64
+}
+
+// TopLinksQuery describes a leaderboard request across all links.
+type TopLinksQuery struct {
+	From   time.Time
+	To     time.Time
+	SortBy string
+	Limit  int
+}
+
+// ClickTotals holds account-wide click counts over a time window.
+type ClickTotals struct {
+	TotalClicks    int64
+	UniqueVisitors int64
+}
+
+// URLTotals holds link counts: those created within a time window and all links overall.
+type URLTotals struct {
+	CreatedInRange int64
+	Total          int64
+}
+
+// AnalyticsOverview is the account-wide summary across all links.
+type AnalyticsOverview struct {
+	From           time.Time
+	To             time.Time
+	LinksCreated   int64
+	TotalLinks     int64
+	TotalClicks    int64
+	UniqueVisitors int64
+	ClicksOverTime TimeSeries
+}
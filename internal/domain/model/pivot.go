@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Dimensions a pivot can break clicks down by.
+const (
+	// DimensionUserAgent groups clicks by their raw User-Agent string.
+	DimensionUserAgent = "user_agent"
+	// DimensionSource groups clicks by their source, e.g. QR code scans apart from link clicks.
+	DimensionSource = "source"
+)
+
+// PivotQuery describes a breakdown of clicks by a time period and a secondary dimension.
+type PivotQuery struct {
+	AnalyticsQuery
+	Dimension string
+	// Top is the number of dimension values kept as separate series; the rest are merged.
+	Top int
+}
+
+// PivotSeries holds the counts of one dimension value, aligned with PivotReport.Buckets.
+type PivotSeries struct {
+	Key    string
+	Total  int64
+	Values []int64
+}
+
+// PivotReport is a period × dimension breakdown in both matrix and long format.
+type PivotReport struct {
+	Period    string
+	Dimension string
+	From      time.Time
+	To        time.Time
+	// Buckets is the dense list of bucket starts covering [From, To).
+	Buckets []time.Time
+	// Series is the matrix form: one zero-filled row per dimension value, ordered by total.
+	Series []PivotSeries
+	// Rows is the long form: one entry per non-empty (bucket, dimension value) pair.
+	Rows []AggregatedStatDetailed
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// RateLimit allows Rate requests per Period on average, with bursts of up to Burst requests.
+type RateLimit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// RateLimitResult is the outcome of a rate limit check.
+type RateLimitResult struct {
+	Allowed bool
+	// Limit is the number of requests allowed per Period of the checked limit.
+	Limit  int
+	Period time.Duration
+	// Burst is the number of requests that may be made at once; Remaining counts down from it.
+	Burst     int
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed; zero if allowed.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the limit is fully replenished.
+	ResetAfter time.Duration
+}
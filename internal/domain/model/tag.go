@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Tag labels links of a workspace; a link can carry any number of tags.
+// Tag names are lowercase and unique per workspace.
+type Tag struct {
+	ID          int64
+	WorkspaceID int64
+	Name        string
+	CreatedAt   time.Time
+	// LinkCount is the number of links carrying the tag.
+	LinkCount int64
+}
+
+// Folder groups links of a workspace; a link belongs to at most one folder.
+type Folder struct {
+	ID          int64
+	WorkspaceID int64
+	Name        string
+	CreatedAt   time.Time
+	// LinkCount is the number of links in the folder.
+	LinkCount int64
+}
+
+// TagReport aggregates the analytics of every link carrying a tag.
+type TagReport struct {
+	Tag            Tag
+	From           time.Time
+	To             time.Time
+	TotalClicks    int64
+	UniqueVisitors int64
+	ClicksOverTime TimeSeries
+	// TopLinks ranks the tagged links by clicks within the window.
+	TopLinks []LinkStat
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// TimeSeriesPoint is a single bucket of a click time series.
+type TimeSeriesPoint struct {
+	Bucket        time.Time
+	Value         int64
+	MovingAverage float64
+}
+
+// TimeSeries is a dense, zero-filled click series over a time range,
+// enriched with derived values for charting.
+type TimeSeries struct {
+	Period string
+	From   time.Time
+	To     time.Time
+	Points []TimeSeriesPoint
+
+	// Total is the number of clicks in [From, To).
+	Total int64
+	// PreviousTotal is the number of clicks in the window of equal length right before From.
+	PreviousTotal int64
+	// ChangePercent is the period-over-period change; nil when PreviousTotal is zero.
+	ChangePercent *float64
+	// Peak is the bucket with the highest value; nil when the series has no clicks.
+	Peak *TimeSeriesPoint
+}
+
+// AnalyticsQuery defines the time range and granularity of an analytics report.
+type AnalyticsQuery struct {
+	From   time.Time
+	To     time.Time
+	Period string
+	// Window is the number of buckets used for the trailing moving average.
+	Window int
+}
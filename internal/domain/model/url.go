@@ -1,11 +1,105 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // URL is the domain model for a shortened link.
 type URL struct {
 	ID          int64
+	WorkspaceID int64
 	OriginalURL string
-	ShortCode   string
-	CreatedAt   time.Time
+	// ShortCode is unique within Domain; the same code may exist on several domains.
+	ShortCode string
+	CreatedAt time.Time
+	// ClickCount is maintained on every recorded click; cached copies may lag behind.
+	ClickCount int64
+	// FolderID is the folder the link belongs to; nil when it is in none.
+	FolderID *int64
+	// Tags are the names of the link's tags. They are only loaded by listings and tag changes.
+	Tags        []string
+	Title       string
+	Description string
+	// Metadata is free-form JSON attached by clients; nil when there is none.
+	// omitempty keeps a missing value nil across the cache's JSON round trip.
+	Metadata json.RawMessage `json:",omitempty"`
+	// CreatedBy names the caller that created the link, e.g. "api_key:42" or "operator:alice".
+	CreatedBy string
+	// URLHash is the SHA-256 of the normalized destination; nil for links created before it was stored.
+	URLHash []byte `json:",omitempty"`
+	// RedirectStatus is the HTTP status of the link's redirect; 0 follows the configured default.
+	RedirectStatus int
+	// Suspicious links were flagged by an operator; visitors see the preview page with a
+	// warning before they are redirected.
+	Suspicious bool
+	// Domain is the host the link is served on; "" is the host of the base URL.
+	Domain string
+	// ExpiresAt is when the link stops redirecting; nil when it never expires.
+	ExpiresAt *time.Time `json:",omitempty"`
+}
+
+// Expired reports whether the link has expired at now.
+func (u *URL) Expired(now time.Time) bool {
+	return u.ExpiresAt != nil && !now.Before(*u.ExpiresAt)
+}
+
+// LinkRef names a link by its short code and domain, e.g. among the links a change touched.
+type LinkRef struct {
+	ShortCode string
+	Domain    string
+}
+
+// ValidRedirectStatus reports whether status is one of the redirect statuses a link may use:
+// 301 or 308 for permanent and 302 or 307 for temporary redirects.
+func ValidRedirectStatus(status int) bool {
+	switch status {
+	case 301, 302, 307, 308:
+		return true
+	}
+	return false
+}
+
+// NewLink describes a link to be created.
+type NewLink struct {
+	OriginalURL string
+	Title       string
+	Description string
+	// Metadata is free-form JSON; nil or JSON null when there is none.
+	Metadata json.RawMessage
+	// ReuseExisting returns an existing link of the workspace with the same normalized
+	// destination instead of creating a new one.
+	ReuseExisting bool
+	// RedirectStatus is the HTTP status of the link's redirect; 0 follows the configured default.
+	RedirectStatus int
+	// Domain is the host to serve the link on; "" uses the host of the base URL.
+	Domain string
+	// ExpiresAt is when the link stops redirecting; nil keeps it forever.
+	ExpiresAt *time.Time
+}
+
+// LinkUpdate describes a partial update of a link; nil fields keep their current value.
+type LinkUpdate struct {
+	OriginalURL *string
+	// URLHash is the hash of the normalized OriginalURL and is set together with it.
+	URLHash     []byte
+	Title       *string
+	Description *string
+	// Metadata replaces the link's metadata when non-nil; JSON null clears it.
+	Metadata json.RawMessage
+	// RedirectStatus replaces the link's redirect status when non-nil; 0 restores the default.
+	RedirectStatus *int
+	// ExpiresAt replaces the link's expiry when non-nil; the zero time removes it.
+	ExpiresAt *time.Time
+}
+
+// LinkRehash reports a run rehashing the destinations of all links.
+type LinkRehash struct {
+	// Scanned counts the links read; Rehashed those whose stored hash changed.
+	Scanned  int
+	Rehashed int
+	// Invalid counts the links whose destination could not be normalized; their hash is kept.
+	Invalid int
+	// Canonical counts the links marked as canonical for their destination afterwards.
+	Canonical int64
 }
@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// Webhook event types.
+const (
+	EventLinkCreated        = "link.created"
+	EventLinkUpdated        = "link.updated"
+	EventLinkDeleted        = "link.deleted"
+	EventLinkExpired        = "link.expired"
+	EventLinkClickThreshold = "link.click_threshold"
+)
+
+// WebhookEventTypes lists every event type a subscription may listen to.
+var WebhookEventTypes = []string{
+	EventLinkCreated,
+	EventLinkUpdated,
+	EventLinkDeleted,
+	EventLinkExpired,
+	EventLinkClickThreshold,
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	// DeliveryDead marks a delivery that exhausted its retries (dead letter).
+	DeliveryDead = "dead"
+)
+
+// WebhookSubscription is an external endpoint notified about link events.
+type WebhookSubscription struct {
+	ID          int64
+	WorkspaceID int64
+	URL         string
+	Secret      string
+	Events      []string
+	// ClickThreshold is the click count that triggers link.click_threshold; zero disables it.
+	ClickThreshold int64
+	Active         bool
+	CreatedAt      time.Time
+}
+
+// WebhookDelivery is a single event sent, or to be sent, to a subscription.
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventType      string
+	Payload        []byte
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// TargetURL and Secret are populated for deliveries claimed by the dispatcher.
+	TargetURL string
+	Secret    string
+}
+
+// WebhookDeliveryFilter narrows down the delivery history of a subscription.
+type WebhookDeliveryFilter struct {
+	Status   string
+	BeforeID int64
+	Limit    int
+}
+
+// WebhookEvent is the JSON body delivered to webhook endpoints.
+type WebhookEvent struct {
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Link       WebhookLinkData `json:"link"`
+}
+
+// WebhookLinkData describes the link an event refers to.
+type WebhookLinkData struct {
+	ShortCode   string    `json:"short_code"`
+	OriginalURL string    `json:"original_url"`
+	PreviousURL string    `json:"previous_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	ClickCount  int64     `json:"click_count"`
+	// ExpiresAt is set for links that expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
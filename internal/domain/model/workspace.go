@@ -0,0 +1,78 @@
+package model
+
+import "time"
+
+// DefaultWorkspaceID owns everything created before workspaces were introduced.
+// Requests act on it while API key authentication is disabled.
+const DefaultWorkspaceID int64 = 1
+
+// Workspace is a tenant; every link, its clicks, API keys and webhooks belong to one.
+type Workspace struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+	// ReuseExistingLinks makes creating a link return an existing link of the workspace
+	// with the same normalized destination.
+	ReuseExistingLinks bool
+}
+
+// Domain is a host registered for a workspace, on which it serves its links in addition to
+// the domains every workspace can use.
+type Domain struct {
+	ID          int64
+	WorkspaceID int64
+	Host        string
+	CreatedAt   time.Time
+}
+
+// AvailableDomains lists the hosts the links of a workspace can be served on.
+type AvailableDomains struct {
+	// Default is the host of the base URL, used when a link names no domain.
+	Default string
+	// Shared are the configured hosts every workspace may use.
+	Shared []string
+	// Workspace are the hosts registered for the workspace alone.
+	Workspace []Domain
+}
+
+// Workspace roles, from most to least privileged. Owners manage members and API keys,
+// editors manage links and webhooks, viewers read links and analytics.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// roleRanks orders the roles; a role grants everything the lower-ranked roles do.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// ValidRole reports whether role is one of the workspace roles.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAtLeast reports whether role grants the permissions of required.
+// Unknown roles grant nothing.
+func RoleAtLeast(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
+
+// User is a person who can be a member of one or more workspaces.
+type User struct {
+	ID        int64
+	Email     string
+	Name      string
+	CreatedAt time.Time
+}
+
+// Member is a user together with their role in a workspace.
+type Member struct {
+	User
+	Role string
+}
@@ -3,19 +3,64 @@ package repository
 import (
 	"context"
 	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"time"
 )
 
 // AnalyticsRepository defines the contract for retrieving aggregated analytics data.
+// Every query is restricted to the clicks of one workspace.
 type AnalyticsRepository interface {
-	// GetRawClicks
-	GetRawClicks(ctx context.Context, urlID int64) ([]model.Click, error)
+	// GetRecentClicks returns at most limit of the newest clicks for a URL.
+	GetRecentClicks(ctx context.Context, workspaceID, urlID int64, limit int) ([]model.Click, error)
+
+	// CountClicks returns the total number of clicks recorded for a URL.
+	CountClicks(ctx context.Context, workspaceID, urlID int64) (int64, error)
+
+	// ListClicks returns clicks matching the filter, newest first, starting after filter.After.
+	ListClicks(ctx context.Context, workspaceID, urlID int64, filter model.ClickFilter) ([]model.Click, error)
+
+	// StreamClicks calls fn for every click matching the filter, newest first, without
+	// buffering the result set. filter.After and filter.Limit are ignored.
+	StreamClicks(ctx context.Context, workspaceID, urlID int64, filter model.ClickFilter, fn func(model.Click) error) error
 
 	// GetClicksByPeriod
-	GetClicksByPeriod(ctx context.Context, urlID int64, period string) ([]model.AggregatedStat, error)
+	GetClicksByPeriod(ctx context.Context, workspaceID, urlID int64, period string) ([]model.AggregatedStat, error)
 
 	// GetClicksByUserAgent
-	GetClicksByUserAgent(ctx context.Context, urlID int64) ([]model.AggregatedStat, error)
+	GetClicksByUserAgent(ctx context.Context, workspaceID, urlID int64) ([]model.AggregatedStat, error)
 
 	// GetClicksByPeriodAndUserAgent
-	GetClicksByPeriodAndUserAgent(ctx context.Context, urlID int64, period string) ([]model.AggregatedStatDetailed, error)
+	GetClicksByPeriodAndUserAgent(ctx context.Context, workspaceID, urlID int64, period string, from, to time.Time) ([]model.AggregatedStatDetailed, error)
+
+	// GetClicksByPeriodAndSource counts clicks in [from, to) per period bucket and click source.
+	GetClicksByPeriodAndSource(ctx context.Context, workspaceID, urlID int64, period string, from, to time.Time) ([]model.AggregatedStatDetailed, error)
+
+	// GetClicksTimeSeries returns one bucket per period overlapping [from, to), including empty
+	// buckets. Buckets are aligned in UTC; the first and last ones only count clicks within the range.
+	GetClicksTimeSeries(ctx context.Context, workspaceID, urlID int64, period string, from, to time.Time) ([]model.TimeSeriesPoint, error)
+
+	// CountClicksInRange counts clicks in the half-open interval [from, to).
+	CountClicksInRange(ctx context.Context, workspaceID, urlID int64, from, to time.Time) (int64, error)
+
+	// GetTopURLs ranks the URLs of the workspace by clicks within [from, to), sorted by model.SortByTotal or model.SortByUnique.
+	GetTopURLs(ctx context.Context, workspaceID int64, from, to time.Time, sortBy string, limit int) ([]model.LinkStat, error)
+
+	// GetGlobalClicksTimeSeries returns a dense click series across all URLs of the workspace,
+	// bucketed like GetClicksTimeSeries.
+	GetGlobalClicksTimeSeries(ctx context.Context, workspaceID int64, period string, from, to time.Time) ([]model.TimeSeriesPoint, error)
+
+	// GetGlobalClickTotals counts clicks and distinct visitors across all URLs of the workspace within [from, to).
+	GetGlobalClickTotals(ctx context.Context, workspaceID int64, from, to time.Time) (model.ClickTotals, error)
+
+	// GetTagClicksTimeSeries returns a dense click series across the URLs carrying a tag,
+	// bucketed like GetClicksTimeSeries.
+	GetTagClicksTimeSeries(ctx context.Context, workspaceID, tagID int64, period string, from, to time.Time) ([]model.TimeSeriesPoint, error)
+
+	// GetTagClickTotals counts clicks and distinct visitors across the URLs carrying a tag within [from, to).
+	GetTagClickTotals(ctx context.Context, workspaceID, tagID int64, from, to time.Time) (model.ClickTotals, error)
+
+	// GetTopURLsByTag ranks the URLs carrying a tag by clicks within [from, to), like GetTopURLs.
+	GetTopURLsByTag(ctx context.Context, workspaceID, tagID int64, from, to time.Time, sortBy string, limit int) ([]model.LinkStat, error)
+
+	// GetURLTotals counts URLs created within [from, to) and all its URLs overall.
+	GetURLTotals(ctx context.Context, workspaceID int64, from, to time.Time) (model.URLTotals, error)
 }
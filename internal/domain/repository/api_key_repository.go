@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"time"
+)
+
+// APIKeyRepository defines the contract for API key persistence.
+type APIKeyRepository interface {
+	// Create persists a new key under the hash of its secret and returns the created record.
+	Create(ctx context.Context, key *model.APIKey, keyHash string) (*model.APIKey, error)
+
+	// GetActiveByHash retrieves a key that has not been revoked by the hash of its secret.
+	// Role is set to the current role of the key's user, if it has one and they are still a member.
+	GetActiveByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+
+	// Get retrieves a key by its ID, including revoked ones.
+	Get(ctx context.Context, id int64) (*model.APIKey, error)
+
+	// List retrieves keys, including revoked ones: those of a workspace, or all of them
+	// when workspaceID is nil.
+	List(ctx context.Context, workspaceID *int64) ([]model.APIKey, error)
+
+	// Revoke marks a key as revoked.
+	Revoke(ctx context.Context, id int64) error
+
+	// RecordUsage adds requests to the request count of a key and advances its last use.
+	RecordUsage(ctx context.Context, id int64, requests int64, lastUsedAt time.Time) error
+}
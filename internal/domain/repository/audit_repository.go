@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+)
+
+// AuditRepository defines the contract for the append-only audit trail.
+type AuditRepository interface {
+	// Create appends an event to the audit trail.
+	Create(ctx context.Context, event *model.AuditEvent) error
+
+	// List retrieves the audit trail of a workspace, newest first.
+	List(ctx context.Context, workspaceID int64, filter model.AuditFilter) ([]model.AuditEvent, error)
+}
@@ -8,12 +8,12 @@ import (
 
 // URLCache defines the contract for a caching layer.
 type URLCache interface {
-	// Get retrieves an item from the cache.
-	Get(ctx context.Context, shortCode string) (*model.URL, error)
+	// Get retrieves the item of a short code on domain from the cache.
+	Get(ctx context.Context, domain, shortCode string) (*model.URL, error)
 
 	// Set adds an item to the cache for a specified duration
 	Set(ctx context.Context, url *model.URL, expiration time.Duration) error
 
-	// Delete removes an item from the cache.
-	Delete(ctx context.Context, shortCode string) error
+	// Delete removes the item of a short code on domain from the cache.
+	Delete(ctx context.Context, domain, shortCode string) error
 }
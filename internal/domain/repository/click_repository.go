@@ -7,6 +7,6 @@ import (
 
 // ClickRepository defines the contract for storing click events.
 type ClickRepository interface {
-	// Create persists a new click event.
-	Create(ctx context.Context, click *model.Click) error
+	// Create persists a new click event and returns the URL's click count including it.
+	Create(ctx context.Context, click *model.Click) (int64, error)
 }
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+)
+
+// ClickStream defines the contract for fanning out live click events across API replicas.
+type ClickStream interface {
+	// Publish assigns an ID to the event and delivers it to every subscriber of its link.
+	Publish(ctx context.Context, event *model.ClickEvent) error
+
+	// Subscribe delivers events of a short code on domain to the returned channel until ctx is done.
+	// If lastEventID is set, buffered events published after it are replayed first.
+	// The channel is closed when the subscription ends.
+	Subscribe(ctx context.Context, domain, shortCode, lastEventID string) (<-chan model.ClickEvent, error)
+}
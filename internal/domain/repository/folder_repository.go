@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+)
+
+// FolderRepository defines the contract for the folders of a workspace.
+type FolderRepository interface {
+	// Create persists a new folder and returns the created record.
+	Create(ctx context.Context, workspaceID int64, name string) (*model.Folder, error)
+	// List retrieves the folders of a workspace with their link counts, by name.
+	List(ctx context.Context, workspaceID int64) ([]model.Folder, error)
+	// Delete removes a folder and returns it with the links it held; the links are kept outside
+	// of any folder.
+	Delete(ctx context.Context, workspaceID, id int64) (*model.Folder, []model.LinkRef, error)
+}
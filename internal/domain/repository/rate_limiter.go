@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+)
+
+// RateLimiter defines the contract for a rate limiter shared by all API replicas.
+type RateLimiter interface {
+	// Allow records a request against key and reports whether it is within the limit.
+	Allow(ctx context.Context, key string, limit model.RateLimit) (model.RateLimitResult, error)
+}
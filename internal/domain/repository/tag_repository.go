@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+)
+
+// TagRepository defines the contract for the tags of a workspace and their links.
+type TagRepository interface {
+	// SetURLTags replaces the tags of a URL with the named tags, creating the missing ones.
+	SetURLTags(ctx context.Context, workspaceID, urlID int64, names []string) error
+	// ListByURLs retrieves the tag names of the given URLs, keyed by URL ID.
+	ListByURLs(ctx context.Context, workspaceID int64, urlIDs []int64) (map[int64][]string, error)
+	// List retrieves the tags of a workspace with their link counts, by name.
+	List(ctx context.Context, workspaceID int64) ([]model.Tag, error)
+	// GetByName retrieves a tag of a workspace with its link count.
+	GetByName(ctx context.Context, workspaceID int64, name string) (*model.Tag, error)
+	// Delete removes a tag from the workspace and from all its links, and returns those links.
+	Delete(ctx context.Context, workspaceID int64, name string) ([]model.LinkRef, error)
+}
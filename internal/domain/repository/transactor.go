@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// Transactor runs a unit of work in a single database transaction.
+type Transactor interface {
+	// WithinTx calls fn in a transaction that is committed when fn returns nil and rolled back
+	// otherwise. Repositories called with the context passed to fn take part in the transaction;
+	// nested calls join the outer transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type commitHooksKey struct{}
+
+// commitHooks collects the functions to run once a transaction has committed.
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// AfterCommit runs fn once the transaction carried by ctx has committed, and not at all when
+// it rolls back. Outside a transaction fn runs at once. Side effects on other stores, such as
+// cache evictions, use it so they cannot be undone by a reader of the not yet committed state.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks)
+	if !ok {
+		fn()
+		return
+	}
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+}
+
+// WithCommitHooks returns a context collecting AfterCommit functions, and a function running
+// them. Transactor implementations call it when they begin a transaction and run the hooks
+// after it commits.
+func WithCommitHooks(ctx context.Context) (context.Context, func()) {
+	hooks := &commitHooks{}
+	return context.WithValue(ctx, commitHooksKey{}, hooks), func() {
+		hooks.mu.Lock()
+		fns := hooks.fns
+		hooks.fns = nil
+		hooks.mu.Unlock()
+		for _, fn := range fns {
+			fn()
+		}
+	}
+}
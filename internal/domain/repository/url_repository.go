@@ -6,13 +6,63 @@ import (
 )
 
 // URLRepository defines the contract for URL persistence.
+// Every method except Resolve is restricted to the URLs of one workspace.
 type URLRepository interface {
-	// Create persists a new URL with just the original URL and returns the created record.
-	Create(ctx context.Context, originalURL string) (*model.URL, error)
+	// Create persists a new URL of url.WorkspaceID with its original URL and details,
+	// but without a short code, and returns the created record.
+	Create(ctx context.Context, url *model.URL) (*model.URL, error)
+
+	// CreateCanonical persists a new URL like Create, marked as the canonical URL of its
+	// destination that creating with reuse settles on. When the workspace already has a
+	// canonical URL with the same hash on the domain, nothing is created and
+	// ErrDuplicateRecord is returned.
+	CreateCanonical(ctx context.Context, url *model.URL) (*model.URL, error)
 
 	// UpdateShortCode updates an existing URL record with its generated short URL.
-	UpdateShortCode(ctx context.Context, id int64, shortCode string) error
+	UpdateShortCode(ctx context.Context, workspaceID, id int64, shortCode string) error
+
+	// GetByShortCode retrieves a URL of the workspace by its shortened URL string on domain;
+	// "" is the domain of the base URL. URLs of other workspaces yield ErrNotFound.
+	GetByShortCode(ctx context.Context, workspaceID int64, domain, shortCode string) (*model.URL, error)
+
+	// GetByURLHash retrieves the oldest URL of the workspace on domain whose normalized
+	// destination has the given hash. No such URL yields ErrNotFound.
+	GetByURLHash(ctx context.Context, workspaceID int64, domain string, hash []byte) (*model.URL, error)
+
+	// Resolve retrieves a URL by its shortened URL string on domain regardless of its workspace;
+	// "" is the domain of the base URL. It serves the public redirect only.
+	Resolve(ctx context.Context, domain, shortCode string) (*model.URL, error)
+
+	// List retrieves a page of the workspace's URLs matching the filter, in the filter's sort order.
+	List(ctx context.Context, workspaceID int64, filter model.LinkFilter) ([]model.URL, error)
+
+	// Update changes the destination and details of a URL on domain and returns the updated record.
+	Update(ctx context.Context, workspaceID int64, domain, shortCode string, update model.LinkUpdate) (*model.URL, error)
+
+	// SetFolder moves a URL on domain into a folder of the same workspace, or out of any folder
+	// when folderID is nil, and returns the updated record. An unknown folder yields ErrNotFound.
+	SetFolder(ctx context.Context, workspaceID int64, domain, shortCode string, folderID *int64) (*model.URL, error)
+
+	// SetSuspicious flags or unflags a URL on domain as suspicious regardless of its workspace
+	// and returns the updated record. It serves operators only.
+	SetSuspicious(ctx context.Context, domain, shortCode string, suspicious bool) (*model.URL, error)
+
+	// Delete removes a URL on domain together with its clicks and returns the deleted record.
+	Delete(ctx context.Context, workspaceID int64, domain, shortCode string) (*model.URL, error)
+
+	// ListAfter retrieves up to limit URLs of any workspace with an ID above afterID, in ID
+	// order. It serves maintenance by operators only.
+	ListAfter(ctx context.Context, afterID int64, limit int32) ([]model.URL, error)
+
+	// SetURLHash replaces the destination hash of a URL of any workspace; the URL is no longer
+	// canonical. It serves maintenance by operators only.
+	SetURLHash(ctx context.Context, id int64, hash []byte) error
+
+	// MarkCanonical marks the oldest URL of every destination without a canonical URL as
+	// canonical and returns how many were marked. It serves maintenance by operators only.
+	MarkCanonical(ctx context.Context) (int64, error)
 
-	// GetByShortCode retrieves a URL by its unique shortened URL string.
-	GetByShortCode(ctx context.Context, shortCode string) (*model.URL, error)
+	// ClaimExpired marks up to limit URLs of any workspace whose expiry passed unannounced as
+	// announced and returns them. Called within a transaction, the claim is undone with it.
+	ClaimExpired(ctx context.Context, limit int32) ([]model.URL, error)
 }
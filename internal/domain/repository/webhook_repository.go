@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"time"
+)
+
+// WebhookRepository defines the contract for webhook subscriptions and their delivery queue.
+type WebhookRepository interface {
+	// CreateSubscription persists a new subscription and returns the created record.
+	CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error)
+
+	// ListSubscriptions retrieves all subscriptions of a workspace.
+	ListSubscriptions(ctx context.Context, workspaceID int64) ([]model.WebhookSubscription, error)
+
+	// GetSubscription retrieves a subscription of a workspace by its ID.
+	GetSubscription(ctx context.Context, workspaceID, id int64) (*model.WebhookSubscription, error)
+
+	// DeleteSubscription removes a subscription and its delivery history.
+	DeleteSubscription(ctx context.Context, workspaceID, id int64) error
+
+	// Enqueue creates a pending delivery of the payload for every subscriber of the event type in a workspace.
+	Enqueue(ctx context.Context, workspaceID int64, eventType string, payload []byte) (int64, error)
+
+	// EnqueueClickThreshold creates a pending delivery for subscriptions of a workspace whose threshold equals clickCount.
+	EnqueueClickThreshold(ctx context.Context, workspaceID, clickCount int64, payload []byte) (int64, error)
+
+	// ClaimDue leases up to limit due deliveries for the given duration and returns them.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+
+	// MarkSucceeded records a successful delivery.
+	MarkSucceeded(ctx context.Context, id int64, statusCode int) error
+
+	// MarkFailed records a failed attempt with its new status and next attempt time.
+	MarkFailed(ctx context.Context, id int64, status string, statusCode int, lastErr string, nextAttempt time.Time) error
+
+	// ListDeliveries returns the delivery history of a subscription, newest first.
+	ListDeliveries(ctx context.Context, subscriptionID int64, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error)
+}
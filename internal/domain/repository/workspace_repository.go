@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+)
+
+// WorkspaceRepository defines the contract for workspaces, users and their memberships.
+type WorkspaceRepository interface {
+	// CreateWorkspace persists a new workspace and returns the created record.
+	CreateWorkspace(ctx context.Context, name string) (*model.Workspace, error)
+	// ListWorkspaces retrieves all workspaces.
+	ListWorkspaces(ctx context.Context) ([]model.Workspace, error)
+	// GetWorkspace retrieves a workspace by its ID.
+	GetWorkspace(ctx context.Context, id int64) (*model.Workspace, error)
+	// SetReuseExistingLinks changes whether creating a link in the workspace reuses an existing one.
+	SetReuseExistingLinks(ctx context.Context, id int64, reuse bool) error
+	// AddDomain registers a host for a workspace and returns the created record.
+	AddDomain(ctx context.Context, workspaceID int64, host string) (*model.Domain, error)
+	// GetDomain retrieves the registration of a host regardless of its workspace.
+	GetDomain(ctx context.Context, host string) (*model.Domain, error)
+	// ListDomains retrieves the hosts registered for a workspace.
+	ListDomains(ctx context.Context, workspaceID int64) ([]model.Domain, error)
+	// RemoveDomain removes a host from a workspace.
+	RemoveDomain(ctx context.Context, workspaceID int64, host string) error
+	// CreateUser persists a new user and returns the created record.
+	CreateUser(ctx context.Context, email, name string) (*model.User, error)
+	// AddMember adds a user to a workspace with a role.
+	AddMember(ctx context.Context, workspaceID, userID int64, role string) error
+	// GetMemberRole retrieves the role of a user in a workspace.
+	GetMemberRole(ctx context.Context, workspaceID, userID int64) (string, error)
+	// UpdateMemberRole changes the role of a member.
+	UpdateMemberRole(ctx context.Context, workspaceID, userID int64, role string) error
+	// ListMembers retrieves the members of a workspace with their roles.
+	ListMembers(ctx context.Context, workspaceID int64) ([]model.Member, error)
+}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"github.com/rs/zerolog"
+)
+
+type requestKey struct{}
+
+// request holds the logging details of the request being served.
+type request struct {
+	id string
+	// quiet requests only log warnings and errors; used to sample high-volume routes.
+	quiet bool
+}
+
+// ContextWithRequest returns a copy of ctx carrying the request ID. If quiet is set,
+// loggers derived from the context drop messages below the warning level.
+func ContextWithRequest(ctx context.Context, requestID string, quiet bool) context.Context {
+	return context.WithValue(ctx, requestKey{}, request{id: requestID, quiet: quiet})
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	req, _ := ctx.Value(requestKey{}).(request)
+	return req.id
+}
+
+// FromContext returns a request-scoped copy of l: it is tagged with the request ID carried
+// by ctx and honours the request's sampling decision. Without a request l is returned as is.
+func FromContext(ctx context.Context, l zerolog.Logger) *zerolog.Logger {
+	req, ok := ctx.Value(requestKey{}).(request)
+	if !ok {
+		return &l
+	}
+
+	scoped := l.With().Str("request_id", req.id).Logger()
+	if req.quiet && scoped.GetLevel() < zerolog.WarnLevel {
+		scoped = scoped.Level(zerolog.WarnLevel)
+	}
+	return &scoped
+}
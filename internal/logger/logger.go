@@ -3,10 +3,21 @@ package logger
 import (
 	"github.com/ilindan-dev/shortener/internal/config"
 	"github.com/rs/zerolog"
+	"io"
 	"os"
 )
 
+// Supported output formats.
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// serviceName identifies this service in shipped logs.
+const serviceName = "shortener"
+
 // NewLogger creates a new configured instance of zerolog.Logger.
+// The "json" format writes one JSON object per line for log shipping; "console" is meant for humans.
 func NewLogger(cfg *config.Config) (*zerolog.Logger, error) {
 	level, err := zerolog.ParseLevel(cfg.Logger.Level)
 	if err != nil {
@@ -14,11 +25,14 @@ func NewLogger(cfg *config.Config) (*zerolog.Logger, error) {
 		level = zerolog.InfoLevel
 	}
 
-	consoleWriter := zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "2006-01-02T15:04:05Z07:00"}
+	var out io.Writer = os.Stderr
+	if cfg.Logger.Format != FormatJSON {
+		out = zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: "2006-01-02T15:04:05Z07:00"}
+	}
 
-	logger := zerolog.New(consoleWriter).With().
+	logger := zerolog.New(out).With().
 		Timestamp().
-		Str("service", "delayed-notifier").
+		Str("service", serviceName).
 		Caller().
 		Logger().
 		Level(level)
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/fx"
+	"net/http"
+)
+
+// Namespace prefixes every metric exported by the service.
+const Namespace = "shortener"
+
+// GroupTag is the Fx value group through which components contribute their collectors.
+const GroupTag = `group:"metrics"`
+
+// AsCollector returns an Fx constructor that adds an already provided component to the
+// metrics group, e.g. fx.Provide(metrics.AsCollector[*redis.Metrics]()).
+func AsCollector[T prometheus.Collector]() any {
+	return fx.Annotate(
+		func(c T) prometheus.Collector { return c },
+		fx.ResultTags(GroupTag),
+	)
+}
+
+// RegistryParams collects every Prometheus collector contributed to the metrics group.
+type RegistryParams struct {
+	fx.In
+
+	Collectors []prometheus.Collector `group:"metrics"`
+}
+
+// NewRegistry creates a Prometheus registry holding the Go runtime and process collectors
+// together with all collectors contributed by the application components.
+func NewRegistry(p RegistryParams) (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	for _, collector := range p.Collectors {
+		if err := registry.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register metrics collector: %w", err)
+		}
+	}
+
+	return registry, nil
+}
+
+// Handler returns the HTTP handler that exposes the registry in the Prometheus text format.
+func Handler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
@@ -3,39 +3,71 @@ package service
 import (
 	"context"
 	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
 	"github.com/ilindan-dev/shortener/internal/domain/model"
 	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
+	"time"
 )
 
+// tagTopLinks is the number of tagged links ranked in a tag report.
+const tagTopLinks = 10
+
 // AnalyticsService provides business logic for URL analytics.
 type AnalyticsService struct {
 	urlRepo       repo.URLRepository
+	tagRepo       repo.TagRepository
 	analyticsRepo repo.AnalyticsRepository
+	domains       *Domains
+	cfg           config.AnalyticsConfig
 	logger        zerolog.Logger
 }
 
 // NewAnalyticsService creates a new instance of AnalyticsService.
 func NewAnalyticsService(
 	urlRepo repo.URLRepository,
+	tagRepo repo.TagRepository,
 	analyticsRepo repo.AnalyticsRepository,
+	domains *Domains,
+	cfg *config.Config,
 	logger *zerolog.Logger,
 ) *AnalyticsService {
 	return &AnalyticsService{
 		urlRepo:       urlRepo,
+		tagRepo:       tagRepo,
 		analyticsRepo: analyticsRepo,
+		domains:       domains,
+		cfg:           cfg.Analytics,
 		logger:        logger.With().Str("layer", "analytics_service").Logger(),
 	}
 }
 
-// GetFullAnalyticsReport fetches and aggregates all analytics data for a given short code.
-func (s *AnalyticsService) GetFullAnalyticsReport(ctx context.Context, shortCode string) (*model.FullAnalyticsReport, error) {
-	s.logger.Info().Str("short_code", shortCode).Msg("Fetching full analytics report")
+// GetFullAnalyticsReport fetches and aggregates all analytics data for a given short code on host;
+// an empty host names the base URL, as for the other reports of a short code.
+// The click series covers the range described by q with explicit zero buckets.
+func (s *AnalyticsService) GetFullAnalyticsReport(ctx context.Context, host, shortCode string, q model.AnalyticsQuery) (*model.FullAnalyticsReport, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "AnalyticsService.GetFullAnalyticsReport", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	log.Info().Str("short_code", shortCode).Msg("Fetching full analytics report")
 
-	url, err := s.urlRepo.GetByShortCode(ctx, shortCode)
+	q, err := normalizeAnalyticsQuery(q, time.Now())
 	if err != nil {
-		return nil, err
+		return nil, spanError(span, err)
+	}
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	url, err := s.urlRepo.GetByShortCode(ctx, ws, s.domains.stored(host), shortCode)
+	if err != nil {
+		return nil, spanError(span, err)
 	}
 
 	report := &model.FullAnalyticsReport{
@@ -44,41 +76,340 @@ func (s *AnalyticsService) GetFullAnalyticsReport(ctx context.Context, shortCode
 
 	g, gCtx := errgroup.WithContext(ctx)
 
-	g.Go(func() error {
-		stats, err := s.analyticsRepo.GetClicksByPeriod(gCtx, url.ID, "day")
+	goTraced(g, gCtx, "AnalyticsService.timeSeries", func(ctx context.Context) error {
+		points, err := s.analyticsRepo.GetClicksTimeSeries(ctx, url.WorkspaceID, url.ID, q.Period, q.From, q.To)
+		if err != nil {
+			log.Error().Err(err).Int64("url_id", url.ID).Str("period", q.Period).Msg("Failed to fetch clicks time series")
+			return fmt.Errorf("could not fetch time series: %w", err)
+		}
+		series := buildTimeSeries(points, q)
+
+		previous, err := s.analyticsRepo.CountClicksInRange(ctx, url.WorkspaceID, url.ID, series.From.Add(-series.To.Sub(series.From)), series.From)
 		if err != nil {
-			s.logger.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to fetch clicks by day")
-			return fmt.Errorf("could not fetch daily stats: %w", err)
+			log.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to count clicks in previous period")
+			return fmt.Errorf("could not fetch previous period total: %w", err)
 		}
-		report.ClicksByDay = stats
+		report.ClicksOverTime = withPreviousTotal(series, previous)
 		return nil
 	})
 
-	g.Go(func() error {
-		stats, err := s.analyticsRepo.GetClicksByUserAgent(gCtx, url.ID)
+	goTraced(g, gCtx, "AnalyticsService.clicksByUserAgent", func(ctx context.Context) error {
+		stats, err := s.analyticsRepo.GetClicksByUserAgent(ctx, url.WorkspaceID, url.ID)
 		if err != nil {
-			s.logger.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to fetch clicks by user agent")
+			log.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to fetch clicks by user agent")
 			return fmt.Errorf("could not fetch user agent stats: %w", err)
 		}
 		report.ClicksByUserAgent = stats
 		return nil
 	})
 
-	g.Go(func() error {
-		clicks, err := s.analyticsRepo.GetRawClicks(gCtx, url.ID)
+	goTraced(g, gCtx, "AnalyticsService.recentClicks", func(ctx context.Context) error {
+		clicks, err := s.analyticsRepo.GetRecentClicks(ctx, url.WorkspaceID, url.ID, s.cfg.RecentClicksLimit)
 		if err != nil {
-			s.logger.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to fetch raw clicks")
-			return fmt.Errorf("could not fetch raw clicks: %w", err)
+			log.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to fetch recent clicks")
+			return fmt.Errorf("could not fetch recent clicks: %w", err)
 		}
 		report.RecentClicks = clicks
-		report.TotalClicks = int64(len(clicks))
+		return nil
+	})
+
+	goTraced(g, gCtx, "AnalyticsService.countClicks", func(ctx context.Context) error {
+		total, err := s.analyticsRepo.CountClicks(ctx, url.WorkspaceID, url.ID)
+		if err != nil {
+			log.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to count clicks")
+			return fmt.Errorf("could not count clicks: %w", err)
+		}
+		report.TotalClicks = total
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	log.Info().Str("short_code", shortCode).Msg("Successfully fetched analytics report")
+	return report, nil
+}
+
+// ListClicks returns a single page of raw clicks for a short code, newest first.
+func (s *AnalyticsService) ListClicks(ctx context.Context, host, shortCode string, filter model.ClickFilter) (*model.ClickPage, error) {
+	ctx, span := tracer.Start(ctx, "AnalyticsService.ListClicks", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	url, err := s.urlRepo.GetByShortCode(ctx, ws, s.domains.stored(host), shortCode)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = s.cfg.DefaultClicksPage
+	}
+	filter.Limit = min(filter.Limit, s.cfg.MaxClicksPage)
+	pageSize := filter.Limit
+
+	// Fetch one extra row to find out whether another page follows.
+	filter.Limit++
+	clicks, err := s.analyticsRepo.ListClicks(ctx, url.WorkspaceID, url.ID, filter)
+	if err != nil {
+		return nil, spanError(span, fmt.Errorf("could not list clicks: %w", err))
+	}
+
+	page := &model.ClickPage{Clicks: clicks}
+	if len(clicks) > pageSize {
+		page.Clicks = clicks[:pageSize]
+		last := page.Clicks[pageSize-1]
+		page.Next = &model.ClickCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
+
+// ExportClicks streams every click of a short code matching the filter to fn, newest first.
+// The URL is resolved before the first call to fn, so a missing short code surfaces as
+// repo.ErrNotFound before any output has been produced.
+func (s *AnalyticsService) ExportClicks(ctx context.Context, host, shortCode string, filter model.ClickFilter, fn func(model.Click) error) error {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "AnalyticsService.ExportClicks", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return spanError(span, err)
+	}
+	url, err := s.urlRepo.GetByShortCode(ctx, ws, s.domains.stored(host), shortCode)
+	if err != nil {
+		return spanError(span, err)
+	}
+
+	log.Info().Str("short_code", shortCode).Msg("Exporting clicks")
+	if err := s.analyticsRepo.StreamClicks(ctx, url.WorkspaceID, url.ID, filter, fn); err != nil {
+		return spanError(span, err)
+	}
+	return nil
+}
+
+// GetPivotReport breaks down clicks of a short code by a time period and a secondary dimension.
+func (s *AnalyticsService) GetPivotReport(ctx context.Context, host, shortCode string, q model.PivotQuery) (*model.PivotReport, error) {
+	ctx, span := tracer.Start(ctx, "AnalyticsService.GetPivotReport", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	aq, err := normalizeAnalyticsQuery(q.AnalyticsQuery, time.Now())
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if q.Dimension == "" {
+		q.Dimension = model.DimensionUserAgent
+	}
+	var breakdown func(ctx context.Context, workspaceID, urlID int64, period string, from, to time.Time) ([]model.AggregatedStatDetailed, error)
+	switch q.Dimension {
+	case model.DimensionUserAgent:
+		breakdown = s.analyticsRepo.GetClicksByPeriodAndUserAgent
+	case model.DimensionSource:
+		breakdown = s.analyticsRepo.GetClicksByPeriodAndSource
+	default:
+		return nil, spanError(span, fmt.Errorf("%w: %q", ErrUnsupportedDimension, q.Dimension))
+	}
+	if q.Top <= 0 {
+		q.Top = defaultPivotTop
+	}
+	q.Top = min(q.Top, maxPivotTop)
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	url, err := s.urlRepo.GetByShortCode(ctx, ws, s.domains.stored(host), shortCode)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	// The dense series provides the bucket axis; the breakdown covers the same range.
+	points, err := s.analyticsRepo.GetClicksTimeSeries(ctx, url.WorkspaceID, url.ID, aq.Period, aq.From, aq.To)
+	if err != nil {
+		return nil, spanError(span, fmt.Errorf("could not fetch time series: %w", err))
+	}
+	series := buildTimeSeries(points, aq)
+
+	rows, err := breakdown(ctx, url.WorkspaceID, url.ID, aq.Period, series.From, series.To)
+	if err != nil {
+		return nil, spanError(span, fmt.Errorf("could not fetch %s breakdown: %w", q.Dimension, err))
+	}
+
+	buckets := make([]time.Time, len(points))
+	for i, p := range points {
+		buckets[i] = p.Bucket
+	}
+
+	return &model.PivotReport{
+		Period:    aq.Period,
+		Dimension: q.Dimension,
+		From:      series.From,
+		To:        series.To,
+		Buckets:   buckets,
+		Series:    buildPivotMatrix(buckets, rows, q.Top),
+		Rows:      rows,
+	}, nil
+}
+
+// Defaults and bounds of the top links leaderboard.
+const (
+	defaultTopRange = 7 * 24 * time.Hour
+	defaultTopLimit = 10
+	maxTopLimit     = 100
+)
+
+// GetTopLinks ranks all links by clicks within the requested window.
+func (s *AnalyticsService) GetTopLinks(ctx context.Context, q model.TopLinksQuery) ([]model.LinkStat, error) {
+	ctx, span := tracer.Start(ctx, "AnalyticsService.GetTopLinks")
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	q, err = normalizeTopLinksQuery(q, time.Now())
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	stats, err := s.analyticsRepo.GetTopURLs(ctx, ws, q.From, q.To, q.SortBy, q.Limit)
+	if err != nil {
+		return nil, spanError(span, fmt.Errorf("could not fetch top links: %w", err))
+	}
+	return stats, nil
+}
+
+// GetOverview builds the account-wide summary: links created, click totals and the click trend.
+func (s *AnalyticsService) GetOverview(ctx context.Context, q model.AnalyticsQuery) (*model.AnalyticsOverview, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "AnalyticsService.GetOverview")
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	q, err = normalizeAnalyticsQuery(q, time.Now())
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	overview := &model.AnalyticsOverview{From: q.From, To: q.To}
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	goTraced(g, gCtx, "AnalyticsService.globalTimeSeries", func(ctx context.Context) error {
+		points, err := s.analyticsRepo.GetGlobalClicksTimeSeries(ctx, ws, q.Period, q.From, q.To)
+		if err != nil {
+			return fmt.Errorf("could not fetch global time series: %w", err)
+		}
+		series := buildTimeSeries(points, q)
+
+		previous, err := s.analyticsRepo.GetGlobalClickTotals(ctx, ws, series.From.Add(-series.To.Sub(series.From)), series.From)
+		if err != nil {
+			return fmt.Errorf("could not fetch previous period totals: %w", err)
+		}
+		overview.ClicksOverTime = withPreviousTotal(series, previous.TotalClicks)
+		return nil
+	})
+
+	goTraced(g, gCtx, "AnalyticsService.clickTotals", func(ctx context.Context) error {
+		totals, err := s.analyticsRepo.GetGlobalClickTotals(ctx, ws, q.From, q.To)
+		if err != nil {
+			return fmt.Errorf("could not fetch click totals: %w", err)
+		}
+		overview.TotalClicks = totals.TotalClicks
+		overview.UniqueVisitors = totals.UniqueVisitors
+		return nil
+	})
+
+	goTraced(g, gCtx, "AnalyticsService.linkTotals", func(ctx context.Context) error {
+		totals, err := s.analyticsRepo.GetURLTotals(ctx, ws, q.From, q.To)
+		if err != nil {
+			return fmt.Errorf("could not fetch link totals: %w", err)
+		}
+		overview.LinksCreated = totals.CreatedInRange
+		overview.TotalLinks = totals.Total
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		log.Error().Err(err).Msg("Failed to build analytics overview")
+		return nil, spanError(span, err)
+	}
+
+	return overview, nil
+}
+
+// GetTagReport aggregates the analytics of every link carrying a tag: click totals, the click
+// trend and the tagged links ranked by clicks, all within the range described by q.
+func (s *AnalyticsService) GetTagReport(ctx context.Context, name string, q model.AnalyticsQuery) (*model.TagReport, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "AnalyticsService.GetTagReport", trace.WithAttributes(attribute.String("tag", name)))
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	q, err = normalizeAnalyticsQuery(q, time.Now())
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if name, err = normalizeTag(name); err != nil {
+		return nil, spanError(span, err)
+	}
+	tag, err := s.tagRepo.GetByName(ctx, ws, name)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	report := &model.TagReport{Tag: *tag, From: q.From, To: q.To}
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	goTraced(g, gCtx, "AnalyticsService.tagTimeSeries", func(ctx context.Context) error {
+		points, err := s.analyticsRepo.GetTagClicksTimeSeries(ctx, ws, tag.ID, q.Period, q.From, q.To)
+		if err != nil {
+			return fmt.Errorf("could not fetch tag time series: %w", err)
+		}
+		series := buildTimeSeries(points, q)
+
+		previous, err := s.analyticsRepo.GetTagClickTotals(ctx, ws, tag.ID, series.From.Add(-series.To.Sub(series.From)), series.From)
+		if err != nil {
+			return fmt.Errorf("could not fetch previous period totals: %w", err)
+		}
+		report.ClicksOverTime = withPreviousTotal(series, previous.TotalClicks)
+		return nil
+	})
+
+	goTraced(g, gCtx, "AnalyticsService.tagClickTotals", func(ctx context.Context) error {
+		totals, err := s.analyticsRepo.GetTagClickTotals(ctx, ws, tag.ID, q.From, q.To)
+		if err != nil {
+			return fmt.Errorf("could not fetch tag click totals: %w", err)
+		}
+		report.TotalClicks = totals.TotalClicks
+		report.UniqueVisitors = totals.UniqueVisitors
+		return nil
+	})
+
+	goTraced(g, gCtx, "AnalyticsService.tagTopLinks", func(ctx context.Context) error {
+		stats, err := s.analyticsRepo.GetTopURLsByTag(ctx, ws, tag.ID, q.From, q.To, model.SortByTotal, tagTopLinks)
+		if err != nil {
+			return fmt.Errorf("could not fetch top tagged links: %w", err)
+		}
+		report.TopLinks = stats
 		return nil
 	})
 
 	if err := g.Wait(); err != nil {
-		return nil, err
+		log.Error().Err(err).Str("tag", name).Msg("Failed to build tag report")
+		return nil, spanError(span, err)
 	}
 
-	s.logger.Info().Str("short_code", shortCode).Msg("Successfully fetched analytics report")
 	return report, nil
 }
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/rs/zerolog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// apiKeyPrefix marks secrets issued by this service, which makes leaked keys easy to scan for.
+	apiKeyPrefix = "shk_"
+	// apiKeyDisplayLength is the number of leading characters kept to identify a key in listings.
+	apiKeyDisplayLength = 12
+)
+
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey returns a copy of ctx carrying the API key the request was authenticated with.
+func ContextWithAPIKey(ctx context.Context, key *model.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the API key the request was authenticated with, or nil.
+func APIKeyFromContext(ctx context.Context) *model.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*model.APIKey)
+	return key
+}
+
+// apiKeyUsage accumulates the requests of a key between two flushes.
+type apiKeyUsage struct {
+	requests int64
+	lastUsed time.Time
+}
+
+// APIKeyService issues, revokes and authenticates API keys.
+// Keys are looked up on every request, so a revocation takes effect immediately.
+// Usage is aggregated in memory and written in batches every auth.usage_flush_interval,
+// which keeps authenticated requests from contending on the key's row.
+type APIKeyService struct {
+	keyRepo       repo.APIKeyRepository
+	workspaceRepo repo.WorkspaceRepository
+	tx            repo.Transactor
+	audit         *AuditService
+	flushInterval time.Duration
+	logger        zerolog.Logger
+
+	mu     sync.Mutex
+	usage  map[int64]*apiKeyUsage
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService.
+func NewAPIKeyService(
+	keyRepo repo.APIKeyRepository,
+	workspaceRepo repo.WorkspaceRepository,
+	tx repo.Transactor,
+	audit *AuditService,
+	cfg *config.Config,
+	logger *zerolog.Logger,
+) *APIKeyService {
+	return &APIKeyService{
+		keyRepo:       keyRepo,
+		workspaceRepo: workspaceRepo,
+		tx:            tx,
+		audit:         audit,
+		flushInterval: cfg.Auth.UsageFlushInterval,
+		logger:        logger.With().Str("layer", "api_key_service").Logger(),
+		usage:         make(map[int64]*apiKeyUsage),
+	}
+}
+
+// CreateKey issues a new API key acting on behalf of a workspace. The returned secret is shown once and cannot be recovered.
+// A key with a userID acts with that member's role; a service key without one acts as an editor, or as a viewer
+// with the read scope. Only owners manage keys.
+func (s *APIKeyService) CreateKey(ctx context.Context, workspaceID int64, userID *int64, name, scope string) (*model.APIKey, string, error) {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeWorkspace(ctx, workspaceID, model.RoleOwner); err != nil {
+		return nil, "", err
+	}
+	if scope != model.ScopeRead && scope != model.ScopeWrite {
+		return nil, "", fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+	}
+	if userID != nil {
+		if _, err := s.workspaceRepo.GetMemberRole(ctx, workspaceID, *userID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(buf)
+
+	var key *model.APIKey
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		key, err = s.keyRepo.Create(ctx, &model.APIKey{
+			WorkspaceID: workspaceID,
+			UserID:      userID,
+			Name:        name,
+			Prefix:      secret[:apiKeyDisplayLength],
+			Scope:       scope,
+		}, hashAPIKey(secret))
+		if err != nil {
+			return err
+		}
+		return s.audit.record(ctx, workspaceID, model.AuditAPIKeyCreated, "", nil, snapshotAPIKey(key))
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	log.Info().Int64("api_key_id", key.ID).Int64("workspace_id", workspaceID).Str("scope", scope).Msg("API key created")
+	return key, secret, nil
+}
+
+// ListKeys returns API keys, including revoked ones: all of them for operators,
+// those of the caller's workspace for owners.
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]model.APIKey, error) {
+	ws, err := authorize(ctx, model.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if APIKeyFromContext(ctx) == nil {
+		return s.keyRepo.List(ctx, nil)
+	}
+	return s.keyRepo.List(ctx, &ws)
+}
+
+// RevokeKey revokes an API key. Only owners of the key's workspace may revoke it.
+func (s *APIKeyService) RevokeKey(ctx context.Context, id int64) error {
+	log := logger.FromContext(ctx, s.logger)
+	key, err := s.keyRepo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeWorkspace(ctx, key.WorkspaceID, model.RoleOwner); err != nil {
+		return err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.keyRepo.Revoke(ctx, id); err != nil {
+			return err
+		}
+		revokedAt := time.Now()
+		revoked := *key
+		revoked.RevokedAt = &revokedAt
+		return s.audit.record(ctx, key.WorkspaceID, model.AuditAPIKeyRevoked, "", snapshotAPIKey(key), snapshotAPIKey(&revoked))
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int64("api_key_id", id).Msg("API key revoked")
+	return nil
+}
+
+// Authenticate resolves the key belonging to secret, determines the role it acts with
+// and records its use. Unknown and revoked keys, and keys whose user has left the
+// workspace, yield ErrInvalidAPIKey.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*model.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.keyRepo.GetActiveByHash(ctx, hashAPIKey(secret))
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	switch {
+	case key.UserID == nil && key.Scope == model.ScopeWrite:
+		key.Role = model.RoleEditor
+	case key.UserID == nil:
+		key.Role = model.RoleViewer
+	case key.Role == "":
+		return nil, ErrInvalidAPIKey
+	}
+	// A read key never acts with more than read access, whatever its user's role.
+	if key.Scope == model.ScopeRead {
+		key.Role = model.RoleViewer
+	}
+
+	s.mu.Lock()
+	usage, ok := s.usage[key.ID]
+	if !ok {
+		usage = &apiKeyUsage{}
+		s.usage[key.ID] = usage
+	}
+	usage.requests++
+	usage.lastUsed = time.Now()
+	s.mu.Unlock()
+
+	return key, nil
+}
+
+// Start launches the loop that writes aggregated key usage.
+func (s *APIKeyService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.flushUsage(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the flush loop and writes the usage collected since the last flush.
+func (s *APIKeyService) Stop(ctx context.Context) {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	s.flushUsage(ctx)
+}
+
+// flushUsage writes the aggregated usage. Failed batches are merged back to be retried.
+func (s *APIKeyService) flushUsage(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.usage
+	s.usage = make(map[int64]*apiKeyUsage, len(pending))
+	s.mu.Unlock()
+
+	for id, usage := range pending {
+		if err := s.keyRepo.RecordUsage(ctx, id, usage.requests, usage.lastUsed); err != nil {
+			s.logger.Error().Err(err).Int64("api_key_id", id).Msg("Failed to flush API key usage")
+
+			s.mu.Lock()
+			if current, ok := s.usage[id]; ok {
+				current.requests += usage.requests
+				if usage.lastUsed.After(current.lastUsed) {
+					current.lastUsed = usage.lastUsed
+				}
+			} else {
+				s.usage[id] = usage
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// hashAPIKey returns the hex SHA-256 of a key. Keys carry 256 bits of entropy,
+// so a fast unsalted hash is sufficient and allows lookup by hash.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+// storedKey returns a copy of key for every hash; other methods are not used.
+type storedKey struct {
+	repo.APIKeyRepository
+	key model.APIKey
+}
+
+func (r storedKey) GetActiveByHash(_ context.Context, _ string) (*model.APIKey, error) {
+	key := r.key
+	return &key, nil
+}
+
+func TestAuthenticateResolvesRole(t *testing.T) {
+	member := int64(5)
+	tests := []struct {
+		name     string
+		key      model.APIKey
+		wantRole string
+		wantErr  error
+	}{
+		{name: "service key with write scope", key: model.APIKey{Scope: model.ScopeWrite}, wantRole: model.RoleEditor},
+		{name: "service key with read scope", key: model.APIKey{Scope: model.ScopeRead}, wantRole: model.RoleViewer},
+		{name: "owner key with write scope", key: model.APIKey{Scope: model.ScopeWrite, UserID: &member, Role: model.RoleOwner}, wantRole: model.RoleOwner},
+		{name: "viewer key with write scope", key: model.APIKey{Scope: model.ScopeWrite, UserID: &member, Role: model.RoleViewer}, wantRole: model.RoleViewer},
+		{name: "read scope caps the member role", key: model.APIKey{Scope: model.ScopeRead, UserID: &member, Role: model.RoleOwner}, wantRole: model.RoleViewer},
+		{name: "user left the workspace", key: model.APIKey{Scope: model.ScopeWrite, UserID: &member}, wantErr: ErrInvalidAPIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			s := NewAPIKeyService(storedKey{key: tt.key}, nil, nil, nil, &config.Config{}, &logger)
+
+			key, err := s.Authenticate(context.Background(), apiKeyPrefix+"secret")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || key.Role != tt.wantRole {
+				t.Fatalf("Authenticate() = %+v, %v, want role %s", key, err, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRejectsForeignSecrets(t *testing.T) {
+	logger := zerolog.Nop()
+	s := NewAPIKeyService(storedKey{key: model.APIKey{Scope: model.ScopeWrite}}, nil, nil, nil, &config.Config{}, &logger)
+
+	if _, err := s.Authenticate(context.Background(), "ghp_secret"); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Authenticate() error = %v, want ErrInvalidAPIKey", err)
+	}
+}
+
+// listedKeys records the workspace keys were listed for; other methods are not used.
+type listedKeys struct {
+	repo.APIKeyRepository
+	listed []*int64
+}
+
+func (r *listedKeys) List(_ context.Context, workspaceID *int64) ([]model.APIKey, error) {
+	r.listed = append(r.listed, workspaceID)
+	return nil, nil
+}
+
+func TestListKeysFiltersByWorkspace(t *testing.T) {
+	keys := &listedKeys{}
+	logger := zerolog.Nop()
+	s := NewAPIKeyService(keys, nil, nil, nil, &config.Config{}, &logger)
+
+	if _, err := s.ListKeys(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ListKeys(ContextWithAPIKey(context.Background(), &model.APIKey{WorkspaceID: 3, Role: model.RoleOwner})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ListKeys(ContextWithAPIKey(context.Background(), &model.APIKey{WorkspaceID: 3, Role: model.RoleEditor})); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("ListKeys() as editor error = %v, want ErrForbidden", err)
+	}
+	if len(keys.listed) != 2 || keys.listed[0] != nil || keys.listed[1] == nil || *keys.listed[1] != 3 {
+		t.Fatalf("listed workspaces = %v, want all for operators and 3 for its owner", keys.listed)
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/rs/zerolog"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAuditPage = 50
+	maxAuditPage     = 200
+)
+
+type sourceIPContextKey struct{}
+
+type operatorContextKey struct{}
+
+// ContextWithSourceIP returns a copy of ctx carrying the client address of the request,
+// which is recorded with the audit events the request causes.
+func ContextWithSourceIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, sourceIPContextKey{}, ip)
+}
+
+// ContextWithOperator returns a copy of ctx naming the operator behind calls made without
+// an API key, such as the account running the admin CLI.
+func ContextWithOperator(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operatorContextKey{}, name)
+}
+
+// AuditService writes and reads the audit trail of link and permission changes.
+// Events are written with the same transaction as the change they describe, so the
+// trail cannot miss a committed change nor record one that was rolled back.
+type AuditService struct {
+	auditRepo repo.AuditRepository
+	logger    zerolog.Logger
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(auditRepo repo.AuditRepository, logger *zerolog.Logger) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		logger:    logger.With().Str("layer", "audit_service").Logger(),
+	}
+}
+
+// ListEvents returns a page of the audit trail of the caller's workspace. Only owners may read it.
+func (s *AuditService) ListEvents(ctx context.Context, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	ws, err := authorize(ctx, model.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditPage
+	}
+	filter.Limit = min(filter.Limit, maxAuditPage)
+
+	return s.auditRepo.List(ctx, ws, filter)
+}
+
+// record appends an event attributed to the caller. before and after are snapshots of the
+// changed record and are marshalled to JSON; pass nil where there is none. It must be called
+// with the context of the transaction performing the change.
+func (s *AuditService) record(ctx context.Context, workspaceID int64, action, shortCode string, before, after any) error {
+	event := &model.AuditEvent{
+		WorkspaceID: workspaceID,
+		Action:      action,
+		ShortCode:   shortCode,
+		RequestID:   logger.RequestID(ctx),
+	}
+	event.SourceIP, _ = ctx.Value(sourceIPContextKey{}).(string)
+
+	event.Actor = actor(ctx)
+	if key := APIKeyFromContext(ctx); key != nil {
+		event.ActorAPIKeyID = &key.ID
+		event.ActorUserID = key.UserID
+	}
+
+	var err error
+	if event.Before, err = marshalSnapshot(before); err != nil {
+		return err
+	}
+	if event.After, err = marshalSnapshot(after); err != nil {
+		return err
+	}
+
+	return s.auditRepo.Create(ctx, event)
+}
+
+// actor names the caller: "api_key:<id>" for requests authenticated with an API key,
+// "operator:<name>" or just "operator" for trusted calls made without one.
+func actor(ctx context.Context) string {
+	if key := APIKeyFromContext(ctx); key != nil {
+		return "api_key:" + strconv.FormatInt(key.ID, 10)
+	}
+	if name, _ := ctx.Value(operatorContextKey{}).(string); name != "" {
+		return "operator:" + name
+	}
+	return "operator"
+}
+
+// marshalSnapshot encodes a record snapshot; nil stays nil.
+func marshalSnapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// linkSnapshot is the audited state of a link. Tags are only part of it when they were loaded.
+type linkSnapshot struct {
+	ShortCode      string          `json:"short_code"`
+	Domain         string          `json:"domain,omitempty"`
+	OriginalURL    string          `json:"original_url"`
+	Title          string          `json:"title,omitempty"`
+	Description    string          `json:"description,omitempty"`
+	Metadata       json.RawMessage `json:"metadata,omitempty"`
+	FolderID       *int64          `json:"folder_id,omitempty"`
+	Tags           []string        `json:"tags,omitempty"`
+	RedirectStatus int             `json:"redirect_status,omitempty"`
+	Suspicious     bool            `json:"suspicious,omitempty"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty"`
+}
+
+func snapshotLink(url *model.URL) linkSnapshot {
+	return linkSnapshot{
+		ShortCode:      url.ShortCode,
+		Domain:         url.Domain,
+		OriginalURL:    url.OriginalURL,
+		Title:          url.Title,
+		Description:    url.Description,
+		Metadata:       url.Metadata,
+		FolderID:       url.FolderID,
+		Tags:           url.Tags,
+		RedirectStatus: url.RedirectStatus,
+		Suspicious:     url.Suspicious,
+		ExpiresAt:      url.ExpiresAt,
+	}
+}
+
+// linkRefSnapshot names a link touched by a change of a tag or folder.
+type linkRefSnapshot struct {
+	ShortCode string `json:"short_code"`
+	Domain    string `json:"domain,omitempty"`
+}
+
+func snapshotLinkRefs(links []model.LinkRef) []linkRefSnapshot {
+	refs := make([]linkRefSnapshot, len(links))
+	for i, link := range links {
+		refs[i] = linkRefSnapshot{ShortCode: link.ShortCode, Domain: link.Domain}
+	}
+	return refs
+}
+
+// tagSnapshot is the audited state of a tag with the links carrying it.
+type tagSnapshot struct {
+	Name  string            `json:"name"`
+	Links []linkRefSnapshot `json:"links"`
+}
+
+// folderSnapshot is the audited state of a folder with the links it holds.
+type folderSnapshot struct {
+	ID    int64             `json:"id"`
+	Name  string            `json:"name"`
+	Links []linkRefSnapshot `json:"links"`
+}
+
+// memberSnapshot is the audited state of a workspace membership.
+type memberSnapshot struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// apiKeySnapshot is the audited state of an API key; the secret is never part of it.
+type apiKeySnapshot struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+	Scope  string `json:"scope"`
+	UserID *int64 `json:"user_id,omitempty"`
+	Active bool   `json:"active"`
+}
+
+func snapshotAPIKey(key *model.APIKey) apiKeySnapshot {
+	return apiKeySnapshot{
+		ID:     key.ID,
+		Name:   key.Name,
+		Prefix: key.Prefix,
+		Scope:  key.Scope,
+		UserID: key.UserID,
+		Active: key.RevokedAt == nil,
+	}
+}
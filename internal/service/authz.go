@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+)
+
+// Authorization lives in the service layer so that every entry point enforces the same rules.
+// The caller is the API key stored in the context by the transport. Calls without a key come
+// from trusted operators, i.e. the admin CLI or a server running with authentication disabled,
+// and act as owner of the default workspace.
+
+// authorize checks that the caller's role is at least required and returns the workspace
+// the call acts on.
+func authorize(ctx context.Context, required string) (int64, error) {
+	key := APIKeyFromContext(ctx)
+	if key == nil {
+		return model.DefaultWorkspaceID, nil
+	}
+	if !model.RoleAtLeast(key.Role, required) {
+		return 0, fmt.Errorf("%w: requires the %s role", ErrForbidden, required)
+	}
+	return key.WorkspaceID, nil
+}
+
+// authorizeWorkspace checks that the caller may act on workspaceID with at least the required
+// role. Workspaces of other tenants yield repo.ErrNotFound so their existence is not disclosed.
+func authorizeWorkspace(ctx context.Context, workspaceID int64, required string) error {
+	key := APIKeyFromContext(ctx)
+	if key == nil {
+		return nil
+	}
+	if key.WorkspaceID != workspaceID {
+		return repo.ErrNotFound
+	}
+	_, err := authorize(ctx, required)
+	return err
+}
+
+// authorizeOperator checks that the call comes from a trusted operator rather than an API key.
+// It guards operations spanning all workspaces, such as creating one.
+func authorizeOperator(ctx context.Context) error {
+	if APIKeyFromContext(ctx) != nil {
+		return fmt.Errorf("%w: operator access required", ErrForbidden)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"testing"
+)
+
+func TestAuthorizeWorkspace(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         *model.APIKey
+		workspaceID int64
+		required    string
+		wantErr     error
+	}{
+		{name: "operator", workspaceID: 9, required: model.RoleOwner},
+		{name: "own workspace", key: &model.APIKey{WorkspaceID: 3, Role: model.RoleEditor}, workspaceID: 3, required: model.RoleEditor},
+		{name: "higher role", key: &model.APIKey{WorkspaceID: 3, Role: model.RoleOwner}, workspaceID: 3, required: model.RoleViewer},
+		{name: "lower role", key: &model.APIKey{WorkspaceID: 3, Role: model.RoleEditor}, workspaceID: 3, required: model.RoleOwner, wantErr: ErrForbidden},
+		{name: "other workspace", key: &model.APIKey{WorkspaceID: 3, Role: model.RoleOwner}, workspaceID: 4, required: model.RoleViewer, wantErr: repo.ErrNotFound},
+		{name: "other workspace with lower role", key: &model.APIKey{WorkspaceID: 3, Role: model.RoleViewer}, workspaceID: 4, required: model.RoleOwner, wantErr: repo.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.key != nil {
+				ctx = ContextWithAPIKey(ctx, tt.key)
+			}
+
+			err := authorizeWorkspace(ctx, tt.workspaceID, tt.required)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("authorizeWorkspace() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// hostnamePattern matches a lower-case DNS name of at least two labels.
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Domains knows the hosts links are served on: the host of the base URL, which links store
+// as "", the custom domains of the configuration, which every workspace may use, and the
+// hosts registered for a single workspace.
+type Domains struct {
+	workspaceRepo repo.WorkspaceRepository
+	baseHost      string
+	shared        []string
+	sharedSet     map[string]struct{}
+}
+
+// NewDomains creates a new instance of Domains.
+func NewDomains(workspaceRepo repo.WorkspaceRepository, cfg *config.Config) *Domains {
+	d := &Domains{
+		workspaceRepo: workspaceRepo,
+		sharedSet:     make(map[string]struct{}, len(cfg.HTTP.CustomDomains)),
+	}
+	if base, err := url.Parse(cfg.HTTP.BaseURL); err == nil {
+		d.baseHost = normalizeHost(base.Hostname())
+	}
+	for _, domain := range cfg.HTTP.CustomDomains {
+		host := normalizeHost(domain)
+		if _, ok := d.sharedSet[host]; ok || host == "" || host == d.baseHost {
+			continue
+		}
+		d.sharedSet[host] = struct{}{}
+		d.shared = append(d.shared, host)
+	}
+	return d
+}
+
+// ForRequest returns the domain of the links served on the Host of a request, which may
+// carry a port. The host of the base URL and hosts that are neither configured nor registered
+// map to "", so links of the base URL keep working behind any other name.
+func (d *Domains) ForRequest(ctx context.Context, hostport string) (string, error) {
+	host := requestHostname(hostport)
+	if host == "" || host == d.baseHost {
+		return "", nil
+	}
+	if _, ok := d.sharedSet[host]; ok {
+		return host, nil
+	}
+	if _, err := d.workspaceRepo.GetDomain(ctx, host); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return host, nil
+}
+
+// ForLink returns the domain to store for a new link of the workspace served on host.
+// An empty host picks the base URL; other hosts must be configured or registered for the
+// workspace, or ErrUnknownDomain is returned.
+func (d *Domains) ForLink(ctx context.Context, workspaceID int64, host string) (string, error) {
+	host = d.stored(host)
+	if host == "" {
+		return "", nil
+	}
+	if _, ok := d.sharedSet[host]; ok {
+		return host, nil
+	}
+	registered, err := d.workspaceRepo.GetDomain(ctx, host)
+	if errors.Is(err, repo.ErrNotFound) || (err == nil && registered.WorkspaceID != workspaceID) {
+		return "", fmt.Errorf("%w: %q", ErrUnknownDomain, host)
+	}
+	if err != nil {
+		return "", err
+	}
+	return host, nil
+}
+
+// Available lists the hosts links of the workspace can be served on.
+func (d *Domains) Available(ctx context.Context, workspaceID int64) (*model.AvailableDomains, error) {
+	registered, err := d.workspaceRepo.ListDomains(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AvailableDomains{
+		Default:   d.baseHost,
+		Shared:    d.shared,
+		Workspace: registered,
+	}, nil
+}
+
+// IsOwn reports whether host serves links of this service, for any workspace.
+func (d *Domains) IsOwn(ctx context.Context, host string) (bool, error) {
+	host = normalizeHost(host)
+	if _, ok := d.sharedSet[host]; ok || host == d.baseHost {
+		return true, nil
+	}
+	if _, err := d.workspaceRepo.GetDomain(ctx, host); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// validateRegistration checks that host may be registered for a single workspace.
+func (d *Domains) validateRegistration(host string) error {
+	if !hostnamePattern.MatchString(host) || len(host) > 253 {
+		return fmt.Errorf("%w: %q is not a valid host name", ErrInvalidDomain, host)
+	}
+	if _, ok := d.sharedSet[host]; ok || host == d.baseHost {
+		return fmt.Errorf("%w: %q already serves links of every workspace", ErrInvalidDomain, host)
+	}
+	return nil
+}
+
+// stored returns the value links store for host: the normalized host, or "" for the base URL.
+func (d *Domains) stored(host string) string {
+	host = normalizeHost(host)
+	if host == d.baseHost {
+		return ""
+	}
+	return host
+}
+
+// requestHostname strips the port and IPv6 brackets from a Host header and normalizes it.
+func requestHostname(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		hostport = host
+	}
+	return normalizeHost(strings.Trim(hostport, "[]"))
+}
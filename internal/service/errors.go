@@ -0,0 +1,49 @@
+package service
+
+import "errors"
+
+// ErrInvalidAnalyticsQuery is returned when an analytics time range or period cannot be served.
+var ErrInvalidAnalyticsQuery = errors.New("invalid analytics query")
+
+// ErrUnsupportedDimension is returned when a pivot is requested for a dimension that is not tracked.
+var ErrUnsupportedDimension = errors.New("unsupported dimension")
+
+// ErrTooManyStreams is returned when the live stream connection limit is reached.
+var ErrTooManyStreams = errors.New("too many live streams")
+
+// ErrInvalidWebhook is returned when a webhook subscription request is malformed.
+var ErrInvalidWebhook = errors.New("invalid webhook subscription")
+
+// ErrInvalidAPIKey is returned when an API key is unknown or revoked.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// ErrInvalidScope is returned when an API key is requested with an unknown scope.
+var ErrInvalidScope = errors.New("invalid API key scope")
+
+// ErrForbidden is returned when the caller's workspace role does not permit an operation.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrInvalidRole is returned when a workspace role is unknown.
+var ErrInvalidRole = errors.New("invalid workspace role")
+
+// ErrInvalidTag is returned when a tag name is empty, too long or a link carries too many tags.
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ErrInvalidFolder is returned when a folder name is empty or too long.
+var ErrInvalidFolder = errors.New("invalid folder")
+
+// ErrInvalidLinkDetails is returned when a link title, description or metadata is malformed or too long.
+var ErrInvalidLinkDetails = errors.New("invalid link details")
+
+// ErrUnknownDomain is returned when a link is created on a host the workspace cannot use.
+var ErrUnknownDomain = errors.New("unknown domain")
+
+// ErrInvalidDomain is returned when a host cannot be registered for a workspace.
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// ErrLinkExpired is returned when a short code is followed after its link has expired.
+var ErrLinkExpired = errors.New("link expired")
+
+// ErrURLRejected is returned when a link destination is refused by the URL policy.
+// The *URLRejectedError in the chain carries the reason.
+var ErrURLRejected = errors.New("destination rejected")
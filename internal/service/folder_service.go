@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/rs/zerolog"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxFolderNameLength = 100
+
+// FolderService manages the folders links are organized in.
+// Moving links between folders is part of URLService.
+type FolderService struct {
+	folderRepo repo.FolderRepository
+	audit      *AuditService
+	tx         repo.Transactor
+	logger     zerolog.Logger
+}
+
+// NewFolderService creates a new instance of FolderService.
+func NewFolderService(folderRepo repo.FolderRepository, audit *AuditService, tx repo.Transactor, logger *zerolog.Logger) *FolderService {
+	return &FolderService{
+		folderRepo: folderRepo,
+		audit:      audit,
+		tx:         tx,
+		logger:     logger.With().Str("layer", "folder_service").Logger(),
+	}
+}
+
+// CreateFolder creates a folder in the caller's workspace. A taken name yields repo.ErrDuplicateRecord.
+func (s *FolderService) CreateFolder(ctx context.Context, name string) (*model.Folder, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxFolderNameLength {
+		return nil, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidFolder, maxFolderNameLength)
+	}
+
+	folder, err := s.folderRepo.Create(ctx, ws, name)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Int64("folder_id", folder.ID).Str("name", name).Msg("Folder created")
+	return folder, nil
+}
+
+// ListFolders returns the folders of the caller's workspace.
+func (s *FolderService) ListFolders(ctx context.Context) ([]model.Folder, error) {
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.folderRepo.List(ctx, ws)
+}
+
+// DeleteFolder removes a folder of the caller's workspace; its links stay, outside of any folder.
+// The audit event lists the links the folder held.
+func (s *FolderService) DeleteFolder(ctx context.Context, id int64) error {
+	log := logger.FromContext(ctx, s.logger)
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	var links []model.LinkRef
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		folder, held, err := s.folderRepo.Delete(ctx, ws, id)
+		if err != nil {
+			return err
+		}
+		links = held
+		return s.audit.record(ctx, ws, model.AuditFolderDeleted, "", folderSnapshot{ID: folder.ID, Name: folder.Name, Links: snapshotLinkRefs(links)}, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int64("folder_id", id).Int("links", len(links)).Msg("Folder deleted")
+	return nil
+}
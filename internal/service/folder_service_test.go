@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+// recordedAudit keeps the audit events written to it.
+type recordedAudit struct {
+	repo.AuditRepository
+	events []model.AuditEvent
+}
+
+func (a *recordedAudit) Create(_ context.Context, event *model.AuditEvent) error {
+	a.events = append(a.events, *event)
+	return nil
+}
+
+// heldFolders deletes folder 7 of workspace 3, which holds two links; other folders are unknown.
+type heldFolders struct {
+	repo.FolderRepository
+}
+
+func (heldFolders) Delete(_ context.Context, workspaceID, id int64) (*model.Folder, []model.LinkRef, error) {
+	if workspaceID != 3 || id != 7 {
+		return nil, nil, repo.ErrNotFound
+	}
+	links := []model.LinkRef{{ShortCode: "a"}, {ShortCode: "b", Domain: "go.brand.com"}}
+	return &model.Folder{ID: id, WorkspaceID: workspaceID, Name: "Spring"}, links, nil
+}
+
+func TestDeleteFolderAuditsHeldLinks(t *testing.T) {
+	audit := &recordedAudit{}
+	tx := &inlineTx{}
+	logger := zerolog.Nop()
+	s := NewFolderService(heldFolders{}, NewAuditService(audit, &logger), tx, &logger)
+	ctx := ContextWithAPIKey(context.Background(), &model.APIKey{ID: 1, WorkspaceID: 3, Role: model.RoleEditor})
+
+	if err := s.DeleteFolder(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+	if tx.committed != 1 || len(audit.events) != 1 {
+		t.Fatalf("committed %d transactions with %d audit events, want one of each", tx.committed, len(audit.events))
+	}
+	event := audit.events[0]
+	if event.Action != model.AuditFolderDeleted || event.WorkspaceID != 3 || event.After != nil {
+		t.Fatalf("audit event = %+v", event)
+	}
+	var before folderSnapshot
+	if err := json.Unmarshal(event.Before, &before); err != nil {
+		t.Fatal(err)
+	}
+	if before.Name != "Spring" || len(before.Links) != 2 || before.Links[1] != (linkRefSnapshot{ShortCode: "b", Domain: "go.brand.com"}) {
+		t.Fatalf("audited folder = %+v", before)
+	}
+
+	if err := s.DeleteFolder(ctx, 8); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("DeleteFolder(8) error = %v, want repo.ErrNotFound", err)
+	}
+	if len(audit.events) != 1 {
+		t.Fatal("a failed deletion was audited")
+	}
+}
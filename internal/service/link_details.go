@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 1000
+	maxMetadataSize      = 4096
+)
+
+// validateTitle checks the length of a link title.
+func validateTitle(title string) error {
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return fmt.Errorf("%w: title exceeds %d characters", ErrInvalidLinkDetails, maxTitleLength)
+	}
+	return nil
+}
+
+// validateDescription checks the length of a link description.
+func validateDescription(description string) error {
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description exceeds %d characters", ErrInvalidLinkDetails, maxDescriptionLength)
+	}
+	return nil
+}
+
+// validateRedirectStatus checks that a link's redirect status is a redirect; 0 is the default.
+func validateRedirectStatus(status int) error {
+	if status != 0 && !model.ValidRedirectStatus(status) {
+		return fmt.Errorf("%w: redirect status must be 301, 302, 307 or 308", ErrInvalidLinkDetails)
+	}
+	return nil
+}
+
+// validateExpiry checks that a link's expiry lies after now.
+func validateExpiry(expiresAt time.Time, now time.Time) error {
+	if !expiresAt.After(now) {
+		return fmt.Errorf("%w: expires_at must be in the future", ErrInvalidLinkDetails)
+	}
+	return nil
+}
+
+// normalizeMetadata checks that metadata is a JSON object of bounded size and compacts it.
+// JSON null is returned unchanged, as it clears the metadata of a link.
+func normalizeMetadata(metadata json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(metadata)
+	if string(trimmed) == "null" {
+		return json.RawMessage("null"), nil
+	}
+	if len(trimmed) == 0 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return nil, fmt.Errorf("%w: metadata must be a JSON object", ErrInvalidLinkDetails)
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, trimmed); err != nil {
+		return nil, fmt.Errorf("%w: metadata must be a JSON object", ErrInvalidLinkDetails)
+	}
+	if compacted.Len() > maxMetadataSize {
+		return nil, fmt.Errorf("%w: metadata exceeds %d bytes", ErrInvalidLinkDetails, maxMetadataSize)
+	}
+	return compacted.Bytes(), nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// errNoRedirect is returned by a LinkExpander when a link does not redirect anywhere.
+var errNoRedirect = errors.New("link does not redirect")
+
+// LinkExpander follows a single redirect of a link to another shortener.
+type LinkExpander interface {
+	// Expand returns the absolute URL rawURL redirects to.
+	Expand(ctx context.Context, rawURL string) (string, error)
+}
+
+// HTTPLinkExpander expands links by requesting them without following redirects.
+type HTTPLinkExpander struct {
+	client *http.Client
+}
+
+// NewHTTPLinkExpander creates a new instance of HTTPLinkExpander. The URL policy checks every
+// link before it is expanded; when private networks are blocked, the address is checked again
+// when connecting, so a host resolving differently by then cannot reach internal services.
+func NewHTTPLinkExpander(cfg *config.Config) *HTTPLinkExpander {
+	dialer := &net.Dialer{Timeout: cfg.URLPolicy.ExpandTimeout}
+	if cfg.URLPolicy.BlockPrivateNetworks {
+		dialer.Control = publicDialControl
+	}
+	return &HTTPLinkExpander{
+		client: &http.Client{
+			Timeout: cfg.URLPolicy.ExpandTimeout,
+			// No proxy, so the dialed address is the shortener itself.
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: cfg.URLPolicy.ExpandTimeout,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Expand issues a HEAD request, falling back to GET for shorteners that refuse HEAD,
+// and returns the Location of the redirect.
+func (e *HTTPLinkExpander) Expand(ctx context.Context, rawURL string) (string, error) {
+	resp, err := e.do(ctx, http.MethodHead, rawURL)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		if resp, err = e.do(ctx, http.MethodGet, rawURL); err != nil {
+			return "", err
+		}
+	}
+
+	location := resp.Header.Get("Location")
+	if resp.StatusCode < 300 || resp.StatusCode > 399 || location == "" {
+		return "", fmt.Errorf("%w: status %d", errNoRedirect, resp.StatusCode)
+	}
+	next, err := resp.Request.URL.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("expander: invalid Location %q: %w", location, err)
+	}
+	return next.String(), nil
+}
+
+func (e *HTTPLinkExpander) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("expander: failed to build request: %w", err)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("expander: request to %s failed: %w", (&url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host}).String(), err)
+	}
+	resp.Body.Close()
+	return resp, nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeExpander redirects the links of its map and records the links it was asked to expand.
+type fakeExpander struct {
+	redirects map[string]string
+	expanded  []string
+}
+
+func (e *fakeExpander) Expand(_ context.Context, rawURL string) (string, error) {
+	e.expanded = append(e.expanded, rawURL)
+	next, ok := e.redirects[rawURL]
+	if !ok {
+		return "", errNoRedirect
+	}
+	return next, nil
+}
+
+// expandResolver resolves the hosts used by the expansion tests.
+var expandResolver = fakeResolver{
+	"example.com":        {"93.184.216.34"},
+	"bit.ly":             {"67.199.248.10"},
+	"tinyurl.com":        {"104.20.139.65"},
+	"internal.bit.ly":    {"10.0.0.8"},
+	"rebind.example.com": {"192.168.1.20"},
+}
+
+func TestURLPolicyExpand(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		redirects map[string]string
+		want      string
+		wantURL   string
+		expanded  int
+	}{
+		{
+			name:      "single hop",
+			url:       "https://bit.ly/a",
+			redirects: map[string]string{"https://bit.ly/a": "https://example.com/pricing"},
+			wantURL:   "https://example.com/pricing",
+			expanded:  1,
+		},
+		{
+			name: "chain of shorteners",
+			url:  "https://bit.ly/a",
+			redirects: map[string]string{
+				"https://bit.ly/a":      "https://tinyurl.com/b",
+				"https://tinyurl.com/b": "https://example.com/",
+			},
+			wantURL:  "https://example.com/",
+			expanded: 2,
+		},
+		{
+			name: "hop limit",
+			url:  "https://bit.ly/1",
+			redirects: map[string]string{
+				"https://bit.ly/1": "https://bit.ly/2",
+				"https://bit.ly/2": "https://bit.ly/3",
+				"https://bit.ly/3": "https://bit.ly/4",
+				"https://bit.ly/4": "https://example.com/",
+			},
+			want:     ReasonTooManyRedirects,
+			expanded: 3,
+		},
+		{
+			name: "loop between shorteners",
+			url:  "https://bit.ly/a",
+			redirects: map[string]string{
+				"https://bit.ly/a":      "https://tinyurl.com/b",
+				"https://tinyurl.com/b": "https://bit.ly/a",
+			},
+			want:     ReasonTooManyRedirects,
+			expanded: 3,
+		},
+		{
+			name:      "loop back to this service",
+			url:       "https://bit.ly/a",
+			redirects: map[string]string{"https://bit.ly/a": "https://sho.rt/s/abc"},
+			want:      ReasonOwnDomain,
+			expanded:  1,
+		},
+		{
+			name:      "loop back to a workspace domain",
+			url:       "https://bit.ly/a",
+			redirects: map[string]string{"https://bit.ly/a": "https://go.brand.com/s/abc"},
+			want:      ReasonOwnDomain,
+			expanded:  1,
+		},
+		{
+			name:      "ends on a private address",
+			url:       "https://bit.ly/a",
+			redirects: map[string]string{"https://bit.ly/a": "http://169.254.169.254/latest/meta-data/"},
+			want:      ReasonPrivateAddress,
+			expanded:  1,
+		},
+		{
+			name:      "ends on a host resolving to a private address",
+			url:       "https://bit.ly/a",
+			redirects: map[string]string{"https://bit.ly/a": "https://rebind.example.com/"},
+			want:      ReasonPrivateAddress,
+			expanded:  1,
+		},
+		{
+			// The private hop is refused before it is requested, so the expander never sees it.
+			name: "intermediate hop on a private address",
+			url:  "https://bit.ly/a",
+			redirects: map[string]string{
+				"https://bit.ly/a":          "https://internal.bit.ly/b",
+				"https://internal.bit.ly/b": "https://example.com/",
+			},
+			want:     ReasonPrivateAddress,
+			expanded: 1,
+		},
+		{
+			name:      "intermediate hop with a disallowed scheme",
+			url:       "https://bit.ly/a",
+			redirects: map[string]string{"https://bit.ly/a": "file:///etc/passwd"},
+			want:      ReasonSchemeNotAllowed,
+			expanded:  1,
+		},
+		{
+			name:     "does not redirect",
+			url:      "https://bit.ly/gone",
+			want:     ReasonUnexpandableLink,
+			expanded: 1,
+		},
+		{
+			name:     "not a shortener",
+			url:      "https://example.com/",
+			wantURL:  "https://example.com/",
+			expanded: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expander := &fakeExpander{redirects: tt.redirects}
+			policy := newTestPolicy(t, expandResolver, expander, func(cfg *config.Config) {
+				cfg.URLPolicy.ShortenerAction = ShortenerActionExpand
+			})
+
+			got, err := policy.Check(context.Background(), tt.url)
+			if reason := rejection(err); reason != tt.want {
+				t.Fatalf("Check(%q) = %q (%v), want %q", tt.url, reason, err, tt.want)
+			}
+			if err == nil && got != tt.wantURL {
+				t.Errorf("Check(%q) = %q, want %q", tt.url, got, tt.wantURL)
+			}
+			if len(expander.expanded) != tt.expanded {
+				t.Errorf("expanded %v, want %d links", expander.expanded, tt.expanded)
+			}
+		})
+	}
+}
+
+func TestURLPolicyRejectsShortenersWithoutExpanding(t *testing.T) {
+	expander := &fakeExpander{redirects: map[string]string{"https://bit.ly/a": "https://example.com/"}}
+	policy := newTestPolicy(t, expandResolver, expander, nil)
+
+	_, err := policy.Check(context.Background(), "https://bit.ly/a")
+	if rejection(err) != ReasonShortenerLink {
+		t.Fatalf("Check = %v, want %s", err, ReasonShortenerLink)
+	}
+	if len(expander.expanded) != 0 {
+		t.Errorf("expanded %v in reject mode", expander.expanded)
+	}
+}
+
+func TestHTTPLinkExpander(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/head":
+			http.Redirect(w, r, "https://example.com/dest", http.StatusMovedPermanently)
+		case "/get-only":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			http.Redirect(w, r, "/relative", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	expander := NewHTTPLinkExpander(&config.Config{})
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/head", "https://example.com/dest"},
+		{"/get-only", server.URL + "/relative"},
+	}
+	for _, tt := range tests {
+		got, err := expander.Expand(context.Background(), server.URL+tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("Expand(%s) = (%q, %v), want %q", tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := expander.Expand(context.Background(), server.URL+"/final"); !errors.Is(err, errNoRedirect) {
+		t.Errorf("Expand of a link without redirect = %v, want errNoRedirect", err)
+	}
+}
+
+func TestHTTPLinkExpanderRefusesPrivateAddresses(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	expander := NewHTTPLinkExpander(&config.Config{URLPolicy: config.URLPolicyConfig{BlockPrivateNetworks: true}})
+	_, err := expander.Expand(context.Background(), server.URL)
+	if err == nil || !strings.Contains(err.Error(), "not publicly routable") {
+		t.Fatalf("Expand = %v, want a refused dial", err)
+	}
+	if called {
+		t.Error("the loopback shortener was reached")
+	}
+}
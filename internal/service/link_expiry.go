@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/rs/zerolog"
+	"sync"
+	"time"
+)
+
+// LinkExpirySweeper announces links whose expiry has passed with link.expired in the background.
+// Redirects stop at the expiry on their own; the sweeper only queues the webhook events, in the
+// transaction that marks the links as announced, so each expiry is announced once.
+type LinkExpirySweeper struct {
+	urlRepo  repo.URLRepository
+	webhooks *WebhookService
+	tx       repo.Transactor
+	interval time.Duration
+	batch    int32
+	logger   zerolog.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLinkExpirySweeper creates a new instance of LinkExpirySweeper.
+func NewLinkExpirySweeper(
+	urlRepo repo.URLRepository,
+	webhooks *WebhookService,
+	tx repo.Transactor,
+	cfg *config.Config,
+	logger *zerolog.Logger,
+) *LinkExpirySweeper {
+	return &LinkExpirySweeper{
+		urlRepo:  urlRepo,
+		webhooks: webhooks,
+		tx:       tx,
+		interval: cfg.Links.ExpirySweepInterval,
+		batch:    cfg.Links.ExpirySweepBatch,
+		logger:   logger.With().Str("layer", "link_expiry_sweeper").Logger(),
+	}
+}
+
+// Start launches the sweep loop.
+func (s *LinkExpirySweeper) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+	s.logger.Info().Dur("interval", s.interval).Msg("Link expiry sweeper started")
+}
+
+// Stop stops the sweep loop and waits for a running sweep to finish.
+func (s *LinkExpirySweeper) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	s.logger.Info().Msg("Link expiry sweeper stopped")
+}
+
+func (s *LinkExpirySweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep announces expired links batch by batch until none is left or a batch fails.
+func (s *LinkExpirySweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := s.announceBatch(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error().Err(err).Msg("Failed to announce expired links")
+			}
+			return
+		}
+		if n < int(s.batch) {
+			return
+		}
+	}
+}
+
+// announceBatch claims a batch of expired links and queues link.expired for each. A failure
+// rolls the claim back, so the links are announced by a later sweep.
+func (s *LinkExpirySweeper) announceBatch(ctx context.Context) (int, error) {
+	var expired []model.URL
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if expired, err = s.urlRepo.ClaimExpired(ctx, s.batch); err != nil {
+			return err
+		}
+		for i := range expired {
+			if err := s.webhooks.queueLinkEvent(ctx, model.EventLinkExpired, &expired[i], ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(expired) > 0 {
+		s.logger.Info().Int("links", len(expired)).Msg("Expired links announced")
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+// inlineTx runs the unit of work without a database and reports its outcome; committed
+// counts the transactions whose work succeeded.
+type inlineTx struct {
+	committed int
+}
+
+func (t *inlineTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, commit := repo.WithCommitHooks(ctx)
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	t.committed++
+	commit()
+	return nil
+}
+
+// expiredRepository hands out the queued batches of expired links; other methods are not used.
+type expiredRepository struct {
+	repo.URLRepository
+	batches [][]model.URL
+}
+
+func (r *expiredRepository) ClaimExpired(_ context.Context, limit int32) ([]model.URL, error) {
+	if len(r.batches) == 0 {
+		return nil, nil
+	}
+	batch := r.batches[0]
+	if len(batch) > int(limit) {
+		panic("batch exceeds the limit")
+	}
+	r.batches = r.batches[1:]
+	return batch, nil
+}
+
+// queuedEvents records the payloads queued for delivery, or fails when err is set.
+type queuedEvents struct {
+	repo.WebhookRepository
+	events []model.WebhookEvent
+	err    error
+}
+
+func (q *queuedEvents) Enqueue(_ context.Context, _ int64, _ string, payload []byte) (int64, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
+	var event model.WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return 0, err
+	}
+	q.events = append(q.events, event)
+	return 1, nil
+}
+
+func newTestSweeper(urlRepo repo.URLRepository, webhookRepo repo.WebhookRepository, tx repo.Transactor) *LinkExpirySweeper {
+	logger := zerolog.Nop()
+	cfg := &config.Config{Links: config.LinksConfig{ExpirySweepBatch: 2}}
+	return NewLinkExpirySweeper(urlRepo, NewWebhookService(webhookRepo, nil, &logger), tx, cfg, &logger)
+}
+
+func TestLinkExpirySweeperAnnouncesEveryBatch(t *testing.T) {
+	urlRepo := &expiredRepository{batches: [][]model.URL{
+		{{ShortCode: "a"}, {ShortCode: "b"}},
+		{{ShortCode: "c"}},
+		{{ShortCode: "never"}},
+	}}
+	webhooks := &queuedEvents{}
+	tx := &inlineTx{}
+
+	newTestSweeper(urlRepo, webhooks, tx).sweep(context.Background())
+
+	var got []string
+	for _, event := range webhooks.events {
+		if event.Type != model.EventLinkExpired {
+			t.Errorf("event type = %q, want %q", event.Type, model.EventLinkExpired)
+		}
+		got = append(got, event.Link.ShortCode)
+	}
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Fatalf("announced %v, want [a b c]; a short batch ends the sweep", got)
+	}
+	if tx.committed != 2 {
+		t.Fatalf("committed %d transactions, want one per batch", tx.committed)
+	}
+}
+
+func TestLinkExpirySweeperRollsBackFailedBatch(t *testing.T) {
+	urlRepo := &expiredRepository{batches: [][]model.URL{{{ShortCode: "a"}}}}
+	tx := &inlineTx{}
+	sweeper := newTestSweeper(urlRepo, &queuedEvents{err: errors.New("database is down")}, tx)
+
+	if _, err := sweeper.announceBatch(context.Background()); err == nil {
+		t.Fatal("announceBatch() succeeded although the event could not be queued")
+	}
+	if tx.committed != 0 {
+		t.Fatal("the claim was committed without its event")
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/rs/zerolog"
+	"sync/atomic"
+	"time"
+)
+
+// LiveService provides live click streams for individual short codes.
+type LiveService struct {
+	urlRepo        repo.URLRepository
+	stream         repo.ClickStream
+	domains        *Domains
+	heartbeat      time.Duration
+	maxConnections int64
+	active         atomic.Int64
+	logger         zerolog.Logger
+}
+
+// NewLiveService creates a new instance of LiveService.
+func NewLiveService(
+	urlRepo repo.URLRepository,
+	stream repo.ClickStream,
+	domains *Domains,
+	cfg *config.Config,
+	logger *zerolog.Logger,
+) *LiveService {
+	return &LiveService{
+		urlRepo:        urlRepo,
+		stream:         stream,
+		domains:        domains,
+		heartbeat:      cfg.Live.Heartbeat,
+		maxConnections: int64(cfg.Live.MaxConnections),
+		logger:         logger.With().Str("layer", "live_service").Logger(),
+	}
+}
+
+// Heartbeat returns the interval at which idle streams should send a keep-alive.
+func (s *LiveService) Heartbeat() time.Duration {
+	return s.heartbeat
+}
+
+// Subscribe opens a live click stream for a short code on host; an empty host names the base URL. The stream ends, and the returned
+// channel is closed, when ctx is cancelled.
+func (s *LiveService) Subscribe(ctx context.Context, host, shortCode, lastEventID string) (<-chan model.ClickEvent, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	url, err := s.urlRepo.GetByShortCode(ctx, ws, s.domains.stored(host), shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.active.Add(1) > s.maxConnections {
+		s.active.Add(-1)
+		log.Warn().Str("short_code", shortCode).Msg("Live stream limit reached")
+		return nil, ErrTooManyStreams
+	}
+
+	events, err := s.stream.Subscribe(ctx, url.Domain, url.ShortCode, lastEventID)
+	if err != nil {
+		s.active.Add(-1)
+		return nil, err
+	}
+
+	log.Info().Str("short_code", shortCode).Int64("active", s.active.Load()).Msg("Live stream opened")
+
+	out := make(chan model.ClickEvent)
+	go func() {
+		defer close(out)
+		defer s.active.Add(-1)
+		for event := range events {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
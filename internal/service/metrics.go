@@ -0,0 +1,42 @@
+package service
+
+import (
+	"github.com/ilindan-dev/shortener/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ClickMetrics describes the background click recording pipeline of redirects.
+type ClickMetrics struct {
+	inFlight prometheus.Gauge
+	writes   *prometheus.CounterVec
+}
+
+// NewClickMetrics creates a new instance of ClickMetrics.
+func NewClickMetrics() *ClickMetrics {
+	return &ClickMetrics{
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "clicks",
+			Name:      "pending_writes",
+			Help:      "Number of clicks accepted by redirects that are not yet stored.",
+		}),
+		writes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "clicks",
+			Name:      "writes_total",
+			Help:      "Click writes by result; failed writes are dropped clicks.",
+		}, []string{"result"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *ClickMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.inFlight.Describe(ch)
+	m.writes.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *ClickMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.inFlight.Collect(ch)
+	m.writes.Collect(ch)
+}
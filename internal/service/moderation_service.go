@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/pkg/urlnorm"
+	"github.com/rs/zerolog"
+)
+
+// defaultRehashBatch is the number of links RehashLinks reads at once unless told otherwise.
+const defaultRehashBatch = 500
+
+// ModerationService lets operators act on links of any workspace.
+type ModerationService struct {
+	urlRepo repo.URLRepository
+	domains *Domains
+	tx      repo.Transactor
+	audit   *AuditService
+	// stripParams are the query parameters removed from destinations before hashing them.
+	stripParams []string
+	logger      zerolog.Logger
+}
+
+// NewModerationService creates a new instance of ModerationService.
+func NewModerationService(
+	urlRepo repo.URLRepository,
+	domains *Domains,
+	tx repo.Transactor,
+	audit *AuditService,
+	cfg *config.Config,
+	logger *zerolog.Logger,
+) *ModerationService {
+	return &ModerationService{
+		urlRepo:     urlRepo,
+		domains:     domains,
+		tx:          tx,
+		audit:       audit,
+		stripParams: strippedParams(cfg),
+		logger:      logger.With().Str("layer", "moderation_service").Logger(),
+	}
+}
+
+// SetSuspicious flags or unflags the link of a short code on host as suspicious; an empty
+// host names the base URL. Visitors of a flagged link see the preview page with a warning
+// before they are redirected. Only operators may flag links; the change is audited in the
+// link's workspace.
+func (s *ModerationService) SetSuspicious(ctx context.Context, host, shortCode string, suspicious bool) (*model.URL, error) {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	domain := s.domains.stored(host)
+	var url *model.URL
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.urlRepo.Resolve(ctx, domain, shortCode)
+		if err != nil {
+			return err
+		}
+		if url, err = s.urlRepo.SetSuspicious(ctx, domain, shortCode, suspicious); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, url.WorkspaceID, model.AuditLinkUpdated, shortCode, snapshotLink(current), snapshotLink(url))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("domain", domain).Str("short_code", shortCode).Bool("suspicious", suspicious).Msg("Link moderation changed")
+	return url, nil
+}
+
+// RehashLinks recomputes the hash of every link's normalized destination in batches of
+// batchSize links (defaultRehashBatch when not positive), then marks the oldest link of each destination as canonical. Links
+// created before destinations were normalized carry the hash of their stored destination,
+// so creating a link with reuse would not find them. Only operators may rehash links; it is
+// safe to run again, e.g. after the tracking parameters changed.
+func (s *ModerationService) RehashLinks(ctx context.Context, batchSize int32) (*model.LinkRehash, error) {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		batchSize = defaultRehashBatch
+	}
+
+	report := &model.LinkRehash{}
+	var afterID int64
+	for {
+		links, err := s.urlRepo.ListAfter(ctx, afterID, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, link := range links {
+			report.Scanned++
+			normalized, err := urlnorm.Normalize(link.OriginalURL, s.stripParams)
+			if err != nil {
+				report.Invalid++
+				log.Warn().Err(err).Int64("url_id", link.ID).Msg("Destination cannot be normalized, keeping its hash")
+				continue
+			}
+			hash := urlnorm.Hash(normalized)
+			if bytes.Equal(hash, link.URLHash) {
+				continue
+			}
+			if err := s.urlRepo.SetURLHash(ctx, link.ID, hash); err != nil {
+				return nil, err
+			}
+			report.Rehashed++
+		}
+		if len(links) < int(batchSize) {
+			break
+		}
+		afterID = links[len(links)-1].ID
+	}
+
+	canonical, err := s.urlRepo.MarkCanonical(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Canonical = canonical
+
+	log.Info().Int("scanned", report.Scanned).Int("rehashed", report.Rehashed).Int("invalid", report.Invalid).
+		Int64("canonical", report.Canonical).Msg("Links rehashed")
+	return report, nil
+}
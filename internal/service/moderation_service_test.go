@@ -0,0 +1,89 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/pkg/urlnorm"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+// rehashRepository keeps links in ID order and records the hashes set on them.
+type rehashRepository struct {
+	repo.URLRepository
+	links  []model.URL
+	marked int64
+}
+
+func (r *rehashRepository) ListAfter(_ context.Context, afterID int64, limit int32) ([]model.URL, error) {
+	var page []model.URL
+	for _, link := range r.links {
+		if link.ID > afterID && len(page) < int(limit) {
+			page = append(page, link)
+		}
+	}
+	return page, nil
+}
+
+func (r *rehashRepository) SetURLHash(_ context.Context, id int64, hash []byte) error {
+	for i := range r.links {
+		if r.links[i].ID == id {
+			r.links[i].URLHash = hash
+			return nil
+		}
+	}
+	return repo.ErrNotFound
+}
+
+func (r *rehashRepository) MarkCanonical(context.Context) (int64, error) {
+	return r.marked, nil
+}
+
+func newTestModeration(urlRepo repo.URLRepository) *ModerationService {
+	cfg := &config.Config{Links: config.LinksConfig{StripTrackingParams: true, TrackingParams: []string{"utm_*"}}}
+	logger := zerolog.Nop()
+	return NewModerationService(urlRepo, nil, nil, nil, cfg, &logger)
+}
+
+func TestRehashLinks(t *testing.T) {
+	rawHash := func(s string) []byte { return urlnorm.Hash(s) }
+	normalized := urlnorm.Hash("https://example.com/?a=1&b=2")
+	links := &rehashRepository{
+		links: []model.URL{
+			{ID: 1, OriginalURL: "HTTPS://Example.com?b=2&a=1&utm_source=x", URLHash: rawHash("HTTPS://Example.com?b=2&a=1&utm_source=x")},
+			{ID: 2, OriginalURL: "https://example.com/?a=1&b=2", URLHash: normalized},
+			{ID: 4, OriginalURL: "https://example.com:443/?b=2&a=1"},
+			{ID: 7, OriginalURL: "http://exa mple.com/%zz", URLHash: []byte("legacy")},
+			{ID: 9, OriginalURL: "https://other.example/x", URLHash: rawHash("https://other.example/x")},
+		},
+		marked: 2,
+	}
+
+	report, err := newTestModeration(links).RehashLinks(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := model.LinkRehash{Scanned: 5, Rehashed: 2, Invalid: 1, Canonical: 2}
+	if *report != want {
+		t.Errorf("report = %+v, want %+v", *report, want)
+	}
+	for _, id := range []int{0, 1, 2} {
+		if !bytes.Equal(links.links[id].URLHash, normalized) {
+			t.Errorf("link %d has not the hash of its normalized destination", links.links[id].ID)
+		}
+	}
+	if string(links.links[3].URLHash) != "legacy" {
+		t.Errorf("hash of the invalid destination changed to %x", links.links[3].URLHash)
+	}
+}
+
+func TestRehashLinksRequiresOperator(t *testing.T) {
+	ctx := ContextWithAPIKey(context.Background(), &model.APIKey{WorkspaceID: 1, Role: model.RoleOwner})
+	if _, err := newTestModeration(&rehashRepository{}).RehashLinks(ctx, 10); !errors.Is(err, ErrForbidden) {
+		t.Errorf("RehashLinks with an API key = %v, want ErrForbidden", err)
+	}
+}
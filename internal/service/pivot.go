@@ -0,0 +1,63 @@
+package service
+
+import (
+	"cmp"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"slices"
+	"time"
+)
+
+const (
+	defaultPivotTop = 10
+	maxPivotTop     = 50
+	// pivotOtherKey is the series that collects dimension values outside the top N.
+	pivotOtherKey = "Other"
+)
+
+// buildPivotMatrix lays rows out on the dense bucket axis, keeping the top series by total
+// and folding the remainder into a single "Other" series.
+func buildPivotMatrix(buckets []time.Time, rows []model.AggregatedStatDetailed, top int) []model.PivotSeries {
+	index := make(map[int64]int, len(buckets))
+	for i, b := range buckets {
+		index[b.UnixMicro()] = i
+	}
+
+	byKey := make(map[string]*model.PivotSeries)
+	for _, row := range rows {
+		i, ok := index[row.TimeKey.UnixMicro()]
+		if !ok {
+			continue
+		}
+		series, ok := byKey[row.GroupKey]
+		if !ok {
+			series = &model.PivotSeries{Key: row.GroupKey, Values: make([]int64, len(buckets))}
+			byKey[row.GroupKey] = series
+		}
+		series.Values[i] += row.Value
+		series.Total += row.Value
+	}
+
+	all := make([]model.PivotSeries, 0, len(byKey))
+	for _, series := range byKey {
+		all = append(all, *series)
+	}
+	slices.SortFunc(all, func(a, b model.PivotSeries) int {
+		if c := cmp.Compare(b.Total, a.Total); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	if len(all) <= top {
+		return all
+	}
+
+	other := model.PivotSeries{Key: pivotOtherKey, Values: make([]int64, len(buckets))}
+	for _, series := range all[top:] {
+		for i, v := range series.Values {
+			other.Values[i] += v
+		}
+		other.Total += series.Total
+	}
+	return append(all[:top], other)
+}
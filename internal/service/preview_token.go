@@ -0,0 +1,64 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// previewTokens issues and checks the tokens the preview page adds to the redirect of a
+// suspicious link. A token is "expiry.signature", where the signature is the HMAC-SHA256 of
+// the link's domain, short code and expiry, so it cannot be forged or moved to another link.
+type previewTokens struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// newPreviewTokens creates the token issuer of the configured secret. Without one, a random
+// secret is generated, which only holds for a single replica until it restarts.
+func newPreviewTokens(cfg config.RedirectConfig) (*previewTokens, error) {
+	secret := []byte(cfg.PreviewSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate preview secret: %w", err)
+		}
+	}
+	return &previewTokens{secret: secret, ttl: cfg.PreviewTokenTTL}, nil
+}
+
+// issue returns a token confirming the redirect of link until the configured TTL elapses.
+func (t *previewTokens) issue(link *model.URL, now time.Time) string {
+	expiry := strconv.FormatInt(now.Add(t.ttl).Unix(), 10)
+	return expiry + "." + t.sign(link, expiry)
+}
+
+// valid reports whether token was issued for link and has not expired.
+func (t *previewTokens) valid(link *model.URL, token string, now time.Time) bool {
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(t.sign(link, expiry)))
+}
+
+func (t *previewTokens) sign(link *model.URL, expiry string) string {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte(link.Domain))
+	mac.Write([]byte{0})
+	mac.Write([]byte(link.ShortCode))
+	mac.Write([]byte{0})
+	mac.Write([]byte(expiry))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"testing"
+	"time"
+)
+
+func TestPreviewTokens(t *testing.T) {
+	tokens, err := newPreviewTokens(config.RedirectConfig{PreviewSecret: "secret", PreviewTokenTTL: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	link := &model.URL{ShortCode: "abc"}
+	token := tokens.issue(link, now)
+
+	tests := []struct {
+		name  string
+		link  *model.URL
+		token string
+		at    time.Time
+		want  bool
+	}{
+		{"issued token", link, token, now, true},
+		{"before expiry", link, token, now.Add(10 * time.Minute), true},
+		{"expired", link, token, now.Add(10*time.Minute + time.Second), false},
+		{"other short code", &model.URL{ShortCode: "abd"}, token, now, false},
+		{"other domain", &model.URL{ShortCode: "abc", Domain: "b.link"}, token, now, false},
+		{"unsigned flag", link, "1", now, false},
+		{"empty", link, "", now, false},
+		{"extended expiry", link, "9999999999" + token[len("1700000600"):], now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokens.valid(tt.link, tt.token, tt.at); got != tt.want {
+				t.Errorf("valid(%q) = %t, want %t", tt.token, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewTokensOtherSecret(t *testing.T) {
+	issuer, _ := newPreviewTokens(config.RedirectConfig{PreviewSecret: "a", PreviewTokenTTL: time.Minute})
+	verifier, _ := newPreviewTokens(config.RedirectConfig{PreviewSecret: "b", PreviewTokenTTL: time.Minute})
+	link := &model.URL{ShortCode: "abc"}
+	now := time.Now()
+	if verifier.valid(link, issuer.issue(link, now), now) {
+		t.Error("token signed with another secret was accepted")
+	}
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTagLength   = 64
+	maxTagsPerLink = 20
+)
+
+// normalizeTag trims and lowercases a tag name, so "Summer " and "summer" are the same tag.
+func normalizeTag(name string) (string, error) {
+	tag := strings.ToLower(strings.TrimSpace(name))
+	if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+		return "", fmt.Errorf("%w: %q must be 1 to %d characters", ErrInvalidTag, name, maxTagLength)
+	}
+	return tag, nil
+}
+
+// normalizeTags normalizes a set of tag names, dropping duplicates and sorting them.
+func normalizeTags(names []string) ([]string, error) {
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag, err := normalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+
+	if len(tags) > maxTagsPerLink {
+		return nil, fmt.Errorf("%w: a link carries at most %d tags", ErrInvalidTag, maxTagsPerLink)
+	}
+	return tags, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"time"
+)
+
+const (
+	defaultPeriod      = "day"
+	defaultRange       = 30 * 24 * time.Hour
+	defaultWindow      = 7
+	maxSeriesBuckets   = 1000
+	maxMovingAvgWindow = 90
+)
+
+// periodSteps maps the supported date_trunc periods to their approximate length.
+// The length is only used to bound the number of buckets a query may produce.
+var periodSteps = map[string]time.Duration{
+	"hour":  time.Hour,
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+// normalizeAnalyticsQuery fills in defaults and validates the requested range.
+func normalizeAnalyticsQuery(q model.AnalyticsQuery, now time.Time) (model.AnalyticsQuery, error) {
+	if q.Period == "" {
+		q.Period = defaultPeriod
+	}
+	step, ok := periodSteps[q.Period]
+	if !ok {
+		return q, fmt.Errorf("%w: unsupported period %q", ErrInvalidAnalyticsQuery, q.Period)
+	}
+
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultRange)
+	}
+	if !q.From.Before(q.To) {
+		return q, fmt.Errorf("%w: from must be before to", ErrInvalidAnalyticsQuery)
+	}
+	if q.To.Sub(q.From)/step > maxSeriesBuckets {
+		return q, fmt.Errorf("%w: range exceeds %d %s buckets", ErrInvalidAnalyticsQuery, maxSeriesBuckets, q.Period)
+	}
+
+	if q.Window == 0 {
+		q.Window = defaultWindow
+	}
+	if q.Window < 1 || q.Window > maxMovingAvgWindow {
+		return q, fmt.Errorf("%w: window must be between 1 and %d", ErrInvalidAnalyticsQuery, maxMovingAvgWindow)
+	}
+
+	return q, nil
+}
+
+// normalizeTopLinksQuery fills in defaults and validates a leaderboard request.
+func normalizeTopLinksQuery(q model.TopLinksQuery, now time.Time) (model.TopLinksQuery, error) {
+	if q.To.IsZero() {
+		q.To = now
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultTopRange)
+	}
+	if !q.From.Before(q.To) {
+		return q, fmt.Errorf("%w: from must be before to", ErrInvalidAnalyticsQuery)
+	}
+
+	switch q.SortBy {
+	case "":
+		q.SortBy = model.SortByTotal
+	case model.SortByTotal, model.SortByUnique:
+	default:
+		return q, fmt.Errorf("%w: unsupported sort %q", ErrInvalidAnalyticsQuery, q.SortBy)
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultTopLimit
+	}
+	q.Limit = min(q.Limit, maxTopLimit)
+
+	return q, nil
+}
+
+// buildTimeSeries derives totals, the peak bucket and trailing moving averages from dense points.
+// The series keeps the requested range: its first and last buckets may start before q.From or
+// end after q.To, but only hold the clicks within it, so Total counts exactly [q.From, q.To).
+func buildTimeSeries(points []model.TimeSeriesPoint, q model.AnalyticsQuery) model.TimeSeries {
+	series := model.TimeSeries{
+		Period: q.Period,
+		From:   q.From,
+		To:     q.To,
+		Points: points,
+	}
+
+	var windowSum int64
+	peak := -1
+	for i := range points {
+		series.Total += points[i].Value
+
+		windowSum += points[i].Value
+		if i >= q.Window {
+			windowSum -= points[i-q.Window].Value
+		}
+		size := min(i+1, q.Window)
+		points[i].MovingAverage = float64(windowSum) / float64(size)
+
+		if points[i].Value > 0 && (peak < 0 || points[i].Value > points[peak].Value) {
+			peak = i
+		}
+	}
+
+	if peak >= 0 {
+		p := points[peak]
+		series.Peak = &p
+	}
+
+	return series
+}
+
+// withPreviousTotal sets the previous-window total and the resulting period-over-period change.
+func withPreviousTotal(series model.TimeSeries, previous int64) model.TimeSeries {
+	series.PreviousTotal = previous
+	if previous > 0 {
+		change := float64(series.Total-previous) / float64(previous) * 100
+		series.ChangePercent = &change
+	}
+	return series
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBuildTimeSeriesKeepsRequestedRange(t *testing.T) {
+	// The range starts and ends mid-day: the first and last buckets are partial.
+	q := model.AnalyticsQuery{
+		From:   time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC),
+		To:     time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC),
+		Period: "day",
+		Window: 2,
+	}
+	points := []model.TimeSeriesPoint{
+		{Bucket: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Value: 2},
+		{Bucket: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Value: 0},
+		{Bucket: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), Value: 6},
+		{Bucket: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), Value: 1},
+	}
+
+	series := buildTimeSeries(points, q)
+	if !series.From.Equal(q.From) || !series.To.Equal(q.To) {
+		t.Errorf("range = [%s, %s), want [%s, %s)", series.From, series.To, q.From, q.To)
+	}
+	if series.Total != 9 {
+		t.Errorf("Total = %d, want 9", series.Total)
+	}
+	if series.Peak == nil || !series.Peak.Bucket.Equal(points[2].Bucket) || series.Peak.Value != 6 {
+		t.Errorf("Peak = %+v, want the bucket of %s with 6 clicks", series.Peak, points[2].Bucket)
+	}
+	for i, want := range []float64{2, 1, 3, 3.5} {
+		if got := series.Points[i].MovingAverage; math.Abs(got-want) > 1e-9 {
+			t.Errorf("MovingAverage[%d] = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestBuildTimeSeriesWithoutClicks(t *testing.T) {
+	q := model.AnalyticsQuery{
+		From:   time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC),
+		Period: "hour",
+		Window: 7,
+	}
+	series := buildTimeSeries([]model.TimeSeriesPoint{{Bucket: q.From}, {Bucket: q.From.Add(time.Hour)}, {Bucket: q.From.Add(2 * time.Hour)}}, q)
+	if series.Total != 0 || series.Peak != nil {
+		t.Errorf("empty series has Total %d and Peak %+v", series.Total, series.Peak)
+	}
+	if !series.From.Equal(q.From) || !series.To.Equal(q.To) {
+		t.Errorf("range = [%s, %s), want [%s, %s)", series.From, series.To, q.From, q.To)
+	}
+}
+
+func TestWithPreviousTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		previous int64
+		want     *float64
+	}{
+		{"growth", 15, 10, ptr(50.0)},
+		{"decline", 5, 20, ptr(-75.0)},
+		{"unchanged", 8, 8, ptr(0.0)},
+		{"no previous clicks", 8, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := withPreviousTotal(model.TimeSeries{Total: tt.total}, tt.previous)
+			if series.PreviousTotal != tt.previous {
+				t.Errorf("PreviousTotal = %d, want %d", series.PreviousTotal, tt.previous)
+			}
+			switch {
+			case tt.want == nil && series.ChangePercent != nil:
+				t.Errorf("ChangePercent = %v, want nil", *series.ChangePercent)
+			case tt.want != nil && (series.ChangePercent == nil || math.Abs(*series.ChangePercent-*tt.want) > 1e-9):
+				t.Errorf("ChangePercent = %v, want %v", series.ChangePercent, *tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAnalyticsQuery(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 30, 0, 0, time.UTC)
+
+	q, err := normalizeAnalyticsQuery(model.AnalyticsQuery{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Period != defaultPeriod || q.Window != defaultWindow || !q.To.Equal(now) || !q.From.Equal(now.Add(-defaultRange)) {
+		t.Errorf("defaults = %+v", q)
+	}
+
+	invalid := []model.AnalyticsQuery{
+		{Period: "minute"},
+		{From: now, To: now},
+		{From: now.Add(time.Hour), To: now},
+		{Period: "hour", From: now.Add(-(maxSeriesBuckets + 1) * time.Hour), To: now},
+		{Window: maxMovingAvgWindow + 1},
+		{Window: -1},
+	}
+	for _, in := range invalid {
+		if _, err := normalizeAnalyticsQuery(in, now); !errors.Is(err, ErrInvalidAnalyticsQuery) {
+			t.Errorf("normalizeAnalyticsQuery(%+v) = %v, want ErrInvalidAnalyticsQuery", in, err)
+		}
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
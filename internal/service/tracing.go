@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+)
+
+// tracer creates the spans of the service layer.
+var tracer = otel.Tracer("github.com/ilindan-dev/shortener/internal/service")
+
+// spanError marks the span as failed and returns err unchanged, so it can wrap return values.
+func spanError(span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
+
+// goTraced runs fn in the errgroup inside its own child span of ctx.
+func goTraced(g *errgroup.Group, ctx context.Context, name string, fn func(ctx context.Context) error) {
+	g.Go(func() error {
+		ctx, span := tracer.Start(ctx, name)
+		defer span.End()
+
+		if err := fn(ctx); err != nil {
+			return spanError(span, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/rs/zerolog"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Reason codes of a rejected link destination.
+const (
+	ReasonInvalidURL       = "invalid_url"
+	ReasonSchemeNotAllowed = "scheme_not_allowed"
+	ReasonPrivateAddress   = "private_address"
+	ReasonUnresolvableHost = "unresolvable_host"
+	ReasonBlockedDomain    = "blocked_domain"
+	ReasonOwnDomain        = "own_domain"
+	ReasonShortenerLink    = "shortener_link"
+	ReasonTooManyRedirects = "too_many_redirects"
+	ReasonUnexpandableLink = "unexpandable_link"
+)
+
+// Values of config.URLPolicyConfig.ShortenerAction.
+const (
+	ShortenerActionReject = "reject"
+	ShortenerActionExpand = "expand"
+)
+
+// URLRejectedError describes why a link destination was refused by the URL policy.
+// It matches ErrURLRejected with errors.Is.
+type URLRejectedError struct {
+	Reason  string
+	Message string
+}
+
+func (e *URLRejectedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrURLRejected, e.Message)
+}
+
+func (e *URLRejectedError) Unwrap() error {
+	return ErrURLRejected
+}
+
+func rejectURL(reason, format string, args ...any) error {
+	return &URLRejectedError{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// Resolver looks up the addresses of a host. *net.Resolver satisfies it.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// NewResolver returns the system DNS resolver.
+func NewResolver() Resolver {
+	return net.DefaultResolver
+}
+
+// URLPolicy decides whether a destination may be shortened. It enforces the allowed schemes,
+// keeps links away from loopback, private and link-local networks - before and after DNS
+// resolution - checks the host against a domain blocklist that is reloaded when its file
+// changes, and refuses links back to any domain of this service. Links of other shorteners
+// are rejected or expanded to their destination.
+type URLPolicy struct {
+	resolver   Resolver
+	expander   LinkExpander
+	domains    *Domains
+	cfg        config.URLPolicyConfig
+	schemes    map[string]struct{}
+	shorteners map[string]struct{}
+	logger     zerolog.Logger
+
+	mu        sync.RWMutex
+	blocklist map[string]struct{}
+	modTime   time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewURLPolicy creates a new instance of URLPolicy and loads the blocklist file, if one is configured.
+func NewURLPolicy(resolver Resolver, expander LinkExpander, domains *Domains, cfg *config.Config, logger *zerolog.Logger) (*URLPolicy, error) {
+	switch cfg.URLPolicy.ShortenerAction {
+	case ShortenerActionReject, ShortenerActionExpand:
+	default:
+		return nil, fmt.Errorf("url policy: unknown shortener action %q", cfg.URLPolicy.ShortenerAction)
+	}
+
+	p := &URLPolicy{
+		resolver:   resolver,
+		expander:   expander,
+		domains:    domains,
+		cfg:        cfg.URLPolicy,
+		schemes:    make(map[string]struct{}, len(cfg.URLPolicy.AllowedSchemes)),
+		shorteners: make(map[string]struct{}, len(cfg.URLPolicy.Shorteners)),
+		logger:     logger.With().Str("layer", "url_policy").Logger(),
+	}
+	for _, scheme := range cfg.URLPolicy.AllowedSchemes {
+		p.schemes[strings.ToLower(scheme)] = struct{}{}
+	}
+	for _, domain := range cfg.URLPolicy.Shorteners {
+		p.shorteners[normalizeHost(domain)] = struct{}{}
+	}
+	if p.cfg.BlocklistFile != "" {
+		if _, err := p.reload(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Check returns the destination to store for rawURL, which differs from rawURL only when a
+// link of another shortener was expanded. Every hop of an expansion passes all the rules
+// before it is requested, not only the final destination. It returns a *URLRejectedError
+// when the destination may not be shortened; any other error means the check itself could
+// not run.
+func (p *URLPolicy) Check(ctx context.Context, rawURL string) (string, error) {
+	destination := rawURL
+	for hops := 0; ; hops++ {
+		host, err := p.checkDestination(ctx, destination)
+		if err != nil {
+			return "", err
+		}
+		if !matchDomain(p.shorteners, host) {
+			return destination, nil
+		}
+		if p.cfg.ShortenerAction != ShortenerActionExpand {
+			return "", rejectURL(ReasonShortenerLink, "links of the shortener %q cannot be shortened again", host)
+		}
+		if hops >= p.cfg.MaxExpandHops {
+			return "", rejectURL(ReasonTooManyRedirects, "link still points to a shortener after %d redirects", hops)
+		}
+
+		next, err := p.expander.Expand(ctx, destination)
+		if err != nil {
+			p.logger.Warn().Err(err).Str("url", destination).Msg("Failed to expand shortener link")
+			return "", rejectURL(ReasonUnexpandableLink, "link of the shortener %q could not be expanded", host)
+		}
+		destination = next
+	}
+}
+
+// checkDestination applies every rule but the shortener one to rawURL and returns its host.
+func (p *URLPolicy) checkDestination(ctx context.Context, rawURL string) (string, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return "", rejectURL(ReasonInvalidURL, "destination is not a valid URL")
+	}
+	scheme := strings.ToLower(target.Scheme)
+	if _, ok := p.schemes[scheme]; !ok {
+		return "", rejectURL(ReasonSchemeNotAllowed, "scheme %q is not allowed", scheme)
+	}
+	host := normalizeHost(target.Hostname())
+	if host == "" {
+		return "", rejectURL(ReasonInvalidURL, "destination has no host")
+	}
+	if _, err := netip.ParseAddr(host); err != nil && isNumericHost(host) {
+		// Forms like 2130706433 or 0x7f.1 are read as addresses by browsers but not by netip.
+		return "", rejectURL(ReasonInvalidURL, "host %q is not a valid address or domain", host)
+	}
+
+	own, err := p.domains.IsOwn(ctx, host)
+	if err != nil {
+		return "", fmt.Errorf("url policy: failed to look up domain %q: %w", host, err)
+	}
+	if own {
+		return "", rejectURL(ReasonOwnDomain, "links to %q would redirect back to this service", host)
+	}
+	if p.isBlocked(host) {
+		return "", rejectURL(ReasonBlockedDomain, "domain %q is blocked", host)
+	}
+
+	if err := p.checkNetwork(ctx, host); err != nil {
+		return "", err
+	}
+	return host, nil
+}
+
+// CheckWebhookTarget applies the private network rules to the endpoint of a webhook, so that
+// subscriptions cannot make the dispatcher reach internal services. The dispatcher checks the
+// address again when it connects, as DNS may change in between.
+func (p *URLPolicy) CheckWebhookTarget(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return rejectURL(ReasonInvalidURL, "webhook url is not a valid URL")
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return rejectURL(ReasonSchemeNotAllowed, "webhook url must be an absolute http(s) URL")
+	}
+	host := normalizeHost(target.Hostname())
+	if host == "" {
+		return rejectURL(ReasonInvalidURL, "webhook url has no host")
+	}
+	if _, err := netip.ParseAddr(host); err != nil && isNumericHost(host) {
+		return rejectURL(ReasonInvalidURL, "host %q is not a valid address or domain", host)
+	}
+	return p.checkNetwork(ctx, host)
+}
+
+// checkNetwork rejects hosts on loopback, private and link-local networks, resolving domains
+// when configured to.
+func (p *URLPolicy) checkNetwork(ctx context.Context, host string) error {
+	if !p.cfg.BlockPrivateNetworks {
+		return nil
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isPrivateAddr(addr) {
+			return rejectURL(ReasonPrivateAddress, "address %s is not publicly routable", addr)
+		}
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return rejectURL(ReasonPrivateAddress, "host %q is not publicly routable", host)
+	}
+	if !p.cfg.ResolveDNS {
+		return nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, p.cfg.DNSTimeout)
+	defer cancel()
+	addrs, err := p.resolver.LookupNetIP(lookupCtx, "ip", host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return rejectURL(ReasonUnresolvableHost, "host %q does not resolve", host)
+		}
+		return fmt.Errorf("url policy: failed to resolve %q: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return rejectURL(ReasonUnresolvableHost, "host %q does not resolve", host)
+	}
+	for _, addr := range addrs {
+		if isPrivateAddr(addr) {
+			return rejectURL(ReasonPrivateAddress, "host %q resolves to %s, which is not publicly routable", host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// publicDialControl is a net.Dialer Control function that refuses connections to addresses
+// isPrivateAddr reports. It runs after DNS resolution, so a host cannot pass the policy with
+// a public address and later resolve to a private one.
+func publicDialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("refusing to dial %s: %w", address, err)
+	}
+	if isPrivateAddr(addrPort.Addr()) {
+		return fmt.Errorf("refusing to dial %s: address is not publicly routable", address)
+	}
+	return nil
+}
+
+// Start launches the loop that reloads the blocklist file when it changes.
+func (p *URLPolicy) Start() {
+	if p.cfg.BlocklistFile == "" || p.cfg.BlocklistReloadInterval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.cfg.BlocklistReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reloaded, err := p.reload()
+				if err != nil {
+					// The previous blocklist stays in force until the file can be read again.
+					p.logger.Error().Err(err).Msg("Failed to reload URL blocklist")
+				} else if reloaded {
+					p.logger.Info().Int("domains", p.blocklistSize()).Msg("URL blocklist reloaded")
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the reload loop.
+func (p *URLPolicy) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+// isBlocked reports whether host or one of its parent domains is on the blocklist.
+func (p *URLPolicy) isBlocked(host string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return matchDomain(p.blocklist, host)
+}
+
+func (p *URLPolicy) blocklistSize() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.blocklist)
+}
+
+// reload reads the blocklist file if it changed since the last load and reports whether it did.
+func (p *URLPolicy) reload() (bool, error) {
+	info, err := os.Stat(p.cfg.BlocklistFile)
+	if err != nil {
+		return false, fmt.Errorf("url policy: failed to stat blocklist: %w", err)
+	}
+	p.mu.RLock()
+	unchanged := info.ModTime().Equal(p.modTime)
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	f, err := os.Open(p.cfg.BlocklistFile)
+	if err != nil {
+		return false, fmt.Errorf("url policy: failed to open blocklist: %w", err)
+	}
+	defer f.Close()
+
+	blocklist := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		domain := normalizeHost(line)
+		if domain != "" {
+			blocklist[domain] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("url policy: failed to read blocklist: %w", err)
+	}
+
+	p.mu.Lock()
+	p.blocklist = blocklist
+	p.modTime = info.ModTime()
+	p.mu.Unlock()
+	return true, nil
+}
+
+// matchDomain reports whether host or one of its parent domains is in domains.
+func matchDomain(domains map[string]struct{}, host string) bool {
+	for {
+		if _, ok := domains[host]; ok {
+			return true
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return false
+		}
+		host = host[i+1:]
+	}
+}
+
+// normalizeHost lowercases host and removes surrounding space and the trailing dot.
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// nonPublicPrefixes are ranges isPrivateAddr rejects that netip has no predicate for:
+// "this network" (0.0.0.0/8), which reaches the local host on many systems, and the carrier
+// grade NAT range (100.64.0.0/10) shared by the hosts behind a provider's NAT.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// isPrivateAddr reports whether addr is loopback, private, link-local, unspecified, or in
+// one of nonPublicPrefixes.
+func isPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumericHost reports whether the last label of host is a number, which no domain has.
+func isNumericHost(host string) bool {
+	label := host[strings.LastIndexByte(host, '.')+1:]
+	if strings.HasPrefix(label, "0x") {
+		return true
+	}
+	if label == "" {
+		return false
+	}
+	for _, r := range label {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/rs/zerolog"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeResolver resolves the hosts of its map; others do not exist.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	out := make([]netip.Addr, len(addrs))
+	for i, addr := range addrs {
+		out[i] = netip.MustParseAddr(addr)
+	}
+	return out, nil
+}
+
+// fakeWorkspaces knows the hosts registered for workspace 1; other methods are not used.
+type fakeWorkspaces struct {
+	repo.WorkspaceRepository
+	hosts []string
+}
+
+func (w fakeWorkspaces) GetDomain(_ context.Context, host string) (*model.Domain, error) {
+	for _, registered := range w.hosts {
+		if registered == host {
+			return &model.Domain{WorkspaceID: 1, Host: host}, nil
+		}
+	}
+	return nil, repo.ErrNotFound
+}
+
+func newTestPolicy(t *testing.T, resolver Resolver, expander LinkExpander, modify func(*config.Config)) *URLPolicy {
+	t.Helper()
+	cfg := &config.Config{
+		HTTP: config.HTTPConfig{BaseURL: "https://sho.rt", CustomDomains: []string{"s.example.net"}},
+		URLPolicy: config.URLPolicyConfig{
+			AllowedSchemes:       []string{"http", "https"},
+			BlockPrivateNetworks: true,
+			ResolveDNS:           true,
+			Shorteners:           []string{"bit.ly", "tinyurl.com"},
+			ShortenerAction:      ShortenerActionReject,
+			MaxExpandHops:        3,
+		},
+	}
+	if modify != nil {
+		modify(cfg)
+	}
+	logger := zerolog.Nop()
+	policy, err := NewURLPolicy(resolver, expander, NewDomains(fakeWorkspaces{hosts: []string{"go.brand.com"}}, cfg), cfg, &logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+// rejection returns the reason of a *URLRejectedError, "" for nil and "error" for other errors.
+func rejection(err error) string {
+	if err == nil {
+		return ""
+	}
+	var rejected *URLRejectedError
+	if errors.As(err, &rejected) {
+		return rejected.Reason
+	}
+	return "error"
+}
+
+func TestCheckWebhookTarget(t *testing.T) {
+	policy := newTestPolicy(t, fakeResolver{
+		"hooks.example.com": {"93.184.216.34"},
+		"internal.example":  {"10.0.0.5"},
+	}, nil, nil)
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://hooks.example.com/receive", ""},
+		{"http://169.254.169.254/latest/meta-data/", ReasonPrivateAddress},
+		{"http://127.0.0.1:6379/", ReasonPrivateAddress},
+		{"http://[::1]/", ReasonPrivateAddress},
+		{"http://localhost:8080/", ReasonPrivateAddress},
+		{"https://internal.example/", ReasonPrivateAddress},
+		{"http://2130706433/", ReasonInvalidURL},
+		{"https://missing.example/", ReasonUnresolvableHost},
+		{"ftp://hooks.example.com/", ReasonSchemeNotAllowed},
+		{"/relative", ReasonSchemeNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := rejection(policy.CheckWebhookTarget(context.Background(), tt.url)); got != tt.want {
+				t.Errorf("CheckWebhookTarget(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+// publicResolver resolves the hosts used by the URL policy tests.
+var publicResolver = fakeResolver{
+	"example.com":        {"93.184.216.34"},
+	"www.example.com":    {"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"},
+	"evil.example.org":   {"93.184.216.35"},
+	"rebind.example.com": {"93.184.216.34", "10.1.2.3"},
+	"mapped.example.com": {"::ffff:192.168.1.1"},
+	"cgnat.example.com":  {"100.64.12.1"},
+	"cdn.malware.test":   {"93.184.216.36"},
+	"bit.ly":             {"67.199.248.10"},
+	"www.tinyurl.com":    {"104.20.139.65"},
+}
+
+func TestURLPolicyCheck(t *testing.T) {
+	blocklist := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(blocklist, []byte("# phishing\nevil.example.org\nMalware.Test.  # trailing dot\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy := newTestPolicy(t, publicResolver, nil, func(cfg *config.Config) {
+		cfg.URLPolicy.BlocklistFile = blocklist
+	})
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"public domain", "https://www.example.com/pricing", ""},
+		{"public address", "http://93.184.216.34/", ""},
+		{"scheme", "javascript:alert(1)", ReasonSchemeNotAllowed},
+		{"ftp", "ftp://example.com/file", ReasonSchemeNotAllowed},
+		{"no host", "http:///path", ReasonInvalidURL},
+		{"loopback", "http://127.0.0.1/", ReasonPrivateAddress},
+		{"private", "http://192.168.0.10/admin", ReasonPrivateAddress},
+		{"link-local metadata", "http://169.254.169.254/latest/meta-data/", ReasonPrivateAddress},
+		{"this network", "http://0.0.0.0:8080/", ReasonPrivateAddress},
+		{"this network range", "http://0.1.2.3/", ReasonPrivateAddress},
+		{"carrier grade NAT", "http://100.64.0.1/", ReasonPrivateAddress},
+		{"carrier grade NAT end", "http://100.127.255.254/", ReasonPrivateAddress},
+		{"just outside carrier grade NAT", "http://100.128.0.1/", ""},
+		{"IPv6 loopback", "http://[::1]/", ReasonPrivateAddress},
+		{"IPv6 unique local", "http://[fd00::1]/", ReasonPrivateAddress},
+		{"IPv6-mapped IPv4 loopback", "http://[::ffff:127.0.0.1]/", ReasonPrivateAddress},
+		{"IPv6-mapped IPv4 in hex", "http://[::ffff:7f00:1]/", ReasonPrivateAddress},
+		{"IPv6-mapped IPv4 metadata", "http://[::ffff:a9fe:a9fe]/", ReasonPrivateAddress},
+		{"decimal address", "http://2130706433/", ReasonInvalidURL},
+		{"hex address", "http://0x7f000001/", ReasonInvalidURL},
+		{"upper-case hex address", "http://0X7F000001/", ReasonInvalidURL},
+		{"hex octets", "http://0x7f.0x0.0x0.0x1/", ReasonInvalidURL},
+		{"octal address", "http://0177.0.0.1/", ReasonInvalidURL},
+		{"octal metadata", "http://0251.0376.0251.0376/", ReasonInvalidURL},
+		{"short form", "http://127.1/", ReasonInvalidURL},
+		{"localhost", "http://localhost:3000/", ReasonPrivateAddress},
+		{"localhost subdomain", "http://app.localhost/", ReasonPrivateAddress},
+		{"resolves to private", "https://rebind.example.com/", ReasonPrivateAddress},
+		{"resolves to mapped private", "https://mapped.example.com/", ReasonPrivateAddress},
+		{"resolves to carrier grade NAT", "https://cgnat.example.com/", ReasonPrivateAddress},
+		{"does not resolve", "https://nowhere.example.com/", ReasonUnresolvableHost},
+		{"blocked domain", "https://evil.example.org/login", ReasonBlockedDomain},
+		{"blocked subdomain", "https://login.evil.example.org/", ReasonBlockedDomain},
+		{"blocked domain upper case", "https://EVIL.example.org./", ReasonBlockedDomain},
+		{"blocklist entry normalized", "https://cdn.malware.test/", ReasonBlockedDomain},
+		{"blocklist suffix is not a parent", "https://notevil.example.org/", ReasonUnresolvableHost},
+		{"base URL host", "https://sho.rt/s/abc", ReasonOwnDomain},
+		{"shared custom domain", "https://s.example.net/s/abc", ReasonOwnDomain},
+		{"registered workspace domain", "https://go.brand.com/s/abc", ReasonOwnDomain},
+		{"shortener", "https://bit.ly/abc", ReasonShortenerLink},
+		{"shortener subdomain", "https://www.tinyurl.com/abc", ReasonShortenerLink},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := policy.Check(context.Background(), tt.url)
+			if got := rejection(err); got != tt.want {
+				t.Errorf("Check(%q) = %q (%v), want %q", tt.url, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLPolicyCheckWithoutPrivateNetworkRules(t *testing.T) {
+	policy := newTestPolicy(t, publicResolver, nil, func(cfg *config.Config) {
+		cfg.URLPolicy.BlockPrivateNetworks = false
+	})
+	for _, rawURL := range []string{"http://127.0.0.1/", "http://localhost/", "https://rebind.example.com/"} {
+		if _, err := policy.Check(context.Background(), rawURL); err != nil {
+			t.Errorf("Check(%q) = %v, want it accepted", rawURL, err)
+		}
+	}
+	// Numeric hosts are malformed rather than private, so they are still refused.
+	if _, err := policy.Check(context.Background(), "http://2130706433/"); rejection(err) != ReasonInvalidURL {
+		t.Errorf("Check of a decimal address = %v, want %s", err, ReasonInvalidURL)
+	}
+}
+
+func TestURLPolicyCheckWithoutDNS(t *testing.T) {
+	// The resolver must not be consulted when DNS checks are off.
+	policy := newTestPolicy(t, nil, nil, func(cfg *config.Config) {
+		cfg.URLPolicy.ResolveDNS = false
+	})
+	if _, err := policy.Check(context.Background(), "https://rebind.example.com/"); err != nil {
+		t.Errorf("Check = %v, want it accepted without resolving", err)
+	}
+	if _, err := policy.Check(context.Background(), "http://10.0.0.1/"); rejection(err) != ReasonPrivateAddress {
+		t.Errorf("Check of a private address = %v, want %s", err, ReasonPrivateAddress)
+	}
+}
+
+func TestURLPolicyCheckResolverFailure(t *testing.T) {
+	policy := newTestPolicy(t, failingResolver{}, nil, nil)
+	_, err := policy.Check(context.Background(), "https://example.com/")
+	if err == nil || rejection(err) != "error" {
+		t.Errorf("Check = %v, want a non-rejection error", err)
+	}
+}
+
+// failingResolver fails every lookup with a temporary error.
+type failingResolver struct{}
+
+func (failingResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	return nil, &net.DNSError{Err: "server misbehaving", Name: host, IsTemporary: true}
+}
+
+func TestURLPolicyBlocklistReload(t *testing.T) {
+	blocklist := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(blocklist, []byte("evil.example.org\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy := newTestPolicy(t, publicResolver, nil, func(cfg *config.Config) {
+		cfg.URLPolicy.BlocklistFile = blocklist
+	})
+	if !policy.isBlocked("evil.example.org") || policy.isBlocked("example.com") {
+		t.Fatal("initial blocklist not loaded")
+	}
+
+	reloaded, err := policy.reload()
+	if err != nil || reloaded {
+		t.Fatalf("reload of an unchanged file = (%t, %v), want (false, nil)", reloaded, err)
+	}
+
+	if err := os.WriteFile(blocklist, []byte("example.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Make the change visible on file systems with coarse modification times.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(blocklist, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := policy.reload(); err != nil || !reloaded {
+		t.Fatalf("reload of a changed file = (%t, %v), want (true, nil)", reloaded, err)
+	}
+	if policy.isBlocked("evil.example.org") || !policy.isBlocked("www.example.com") {
+		t.Error("blocklist not replaced by the new file")
+	}
+
+	if err := os.Remove(blocklist); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := policy.reload(); err == nil {
+		t.Error("reload of a missing file succeeded")
+	}
+	if !policy.isBlocked("www.example.com") {
+		t.Error("a failed reload dropped the previous blocklist")
+	}
+}
+
+func TestIsPrivateAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"10.255.0.1", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"0.0.0.0", true},
+		{"0.255.255.255", true},
+		{"100.64.0.0", true},
+		{"100.127.255.255", true},
+		{"::", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"fc00::1", true},
+		{"::ffff:10.0.0.1", true},
+		{"::ffff:100.64.0.1", true},
+		{"100.63.255.255", false},
+		{"100.128.0.0", false},
+		{"1.1.1.1", false},
+		{"93.184.216.34", false},
+		{"2606:4700:4700::1111", false},
+		{"::ffff:1.1.1.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPrivateAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPrivateAddr(%s) = %t, want %t", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestIsNumericHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"2130706433", true},
+		{"0x7f000001", true},
+		{"0177.0.0.1", true},
+		{"127.1", true},
+		{"example.0x1", true},
+		{"example.com", false},
+		{"1password.com", false},
+		{"123.example", false},
+		{"localhost", false},
+	}
+	for _, tt := range tests {
+		if got := isNumericHost(tt.host); got != tt.want {
+			t.Errorf("isNumericHost(%q) = %t, want %t", tt.host, got, tt.want)
+		}
+	}
+}
@@ -2,79 +2,692 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
 	"github.com/ilindan-dev/shortener/internal/domain/model"
 	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
 	"github.com/ilindan-dev/shortener/pkg/base62"
+	"github.com/ilindan-dev/shortener/pkg/urlnorm"
+	"github.com/ilindan-dev/shortener/pkg/useragent"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
 	"time"
 )
 
+const (
+	defaultLinksPage = 50
+	maxLinksPage     = 200
+)
+
 // URLService encapsulates the business logic for URL shortening and analytics.
 type URLService struct {
-	urlRepo   repo.URLRepository
-	clickRepo repo.ClickRepository
-	cache     repo.URLCache
-	logger    zerolog.Logger
+	urlRepo       repo.URLRepository
+	tagRepo       repo.TagRepository
+	workspaceRepo repo.WorkspaceRepository
+	clickRepo     repo.ClickRepository
+	cache         repo.URLCache
+	stream        repo.ClickStream
+	tx            repo.Transactor
+	policy        *URLPolicy
+	domains       *Domains
+	audit         *AuditService
+	webhooks      *WebhookService
+	metrics       *ClickMetrics
+	previews      *previewTokens
+	// stripParams are the query parameters removed from destinations; empty keeps them all.
+	stripParams []string
+	logger      zerolog.Logger
 }
 
 // NewURLService creates a new instance of URLService.
 func NewURLService(
 	urlRepo repo.URLRepository,
+	tagRepo repo.TagRepository,
+	workspaceRepo repo.WorkspaceRepository,
 	clickRepo repo.ClickRepository,
 	cache repo.URLCache,
+	stream repo.ClickStream,
+	tx repo.Transactor,
+	policy *URLPolicy,
+	domains *Domains,
+	audit *AuditService,
+	webhooks *WebhookService,
+	metrics *ClickMetrics,
+	cfg *config.Config,
 	logger *zerolog.Logger,
-) *URLService {
-	return &URLService{
-		urlRepo:   urlRepo,
-		clickRepo: clickRepo,
-		cache:     cache,
-		logger:    logger.With().Str("layer", "service").Logger(),
+) (*URLService, error) {
+	previews, err := newPreviewTokens(cfg.Redirect)
+	if err != nil {
+		return nil, err
 	}
+	return &URLService{
+		urlRepo:       urlRepo,
+		tagRepo:       tagRepo,
+		workspaceRepo: workspaceRepo,
+		clickRepo:     clickRepo,
+		cache:         cache,
+		stream:        stream,
+		tx:            tx,
+		policy:        policy,
+		domains:       domains,
+		audit:         audit,
+		webhooks:      webhooks,
+		metrics:       metrics,
+		previews:      previews,
+		stripParams:   strippedParams(cfg),
+		logger:        logger.With().Str("layer", "service").Logger(),
+	}, nil
 }
 
 // CreateShortURL orchestrates the entire process of creating a short URL.
-func (s *URLService) CreateShortURL(ctx context.Context, originalURL string) (*model.URL, error) {
-	s.logger.Info().Str("original_url", originalURL).Msg("Creating new short URL")
+// The record, its short code and the audit event are written in one transaction.
+// When link.ReuseExisting or the workspace's setting asks for it, an existing link with the
+// same normalized destination is returned unchanged instead; created reports which happened.
+// Concurrent creations of the same destination with reuse settle on a single link.
+func (s *URLService) CreateShortURL(ctx context.Context, link model.NewLink) (*model.URL, bool, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "URLService.CreateShortURL")
+	defer span.End()
 
-	url, err := s.urlRepo.Create(ctx, originalURL)
+	ws, err := authorize(ctx, model.RoleEditor)
 	if err != nil {
-		s.logger.Error().Err(err).Msg("Failed to create initial URL record")
-		return nil, err
+		return nil, false, spanError(span, err)
+	}
+	domain, err := s.domains.ForLink(ctx, ws, link.Domain)
+	if err != nil {
+		return nil, false, spanError(span, err)
 	}
 
-	shortCode := base62.Encode(url.ID)
-	url.ShortCode = shortCode
+	destination, err := s.policy.Check(ctx, link.OriginalURL)
+	if err != nil {
+		log.Warn().Err(err).Str("original_url", link.OriginalURL).Msg("Destination rejected by URL policy")
+		return nil, false, spanError(span, err)
+	}
+	normalized, hash, err := s.normalizeDestination(destination)
+	if err != nil {
+		return nil, false, spanError(span, err)
+	}
+	var legacyHash []byte
+	if normalized != destination {
+		legacyHash = urlnorm.Hash(destination)
+	}
+	destination = normalized
 
-	if err := s.urlRepo.UpdateShortCode(ctx, url.ID, shortCode); err != nil {
-		s.logger.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to update URL with short code")
-		// TODO: cleanup/retry mechanism here.
-		return nil, err
+	reuse := link.ReuseExisting
+	if !reuse {
+		workspace, err := s.workspaceRepo.GetWorkspace(ctx, ws)
+		if err != nil {
+			return nil, false, spanError(span, err)
+		}
+		reuse = workspace.ReuseExistingLinks
+	}
+
+	draft := &model.URL{
+		WorkspaceID:    ws,
+		OriginalURL:    destination,
+		URLHash:        hash,
+		Title:          strings.TrimSpace(link.Title),
+		Description:    strings.TrimSpace(link.Description),
+		CreatedBy:      actor(ctx),
+		RedirectStatus: link.RedirectStatus,
+		Domain:         domain,
+	}
+	if link.ExpiresAt != nil {
+		if err := validateExpiry(*link.ExpiresAt, time.Now()); err != nil {
+			return nil, false, spanError(span, err)
+		}
+		expiresAt := link.ExpiresAt.UTC()
+		draft.ExpiresAt = &expiresAt
+	}
+	if err := validateTitle(draft.Title); err != nil {
+		return nil, false, spanError(span, err)
+	}
+	if err := validateRedirectStatus(draft.RedirectStatus); err != nil {
+		return nil, false, spanError(span, err)
+	}
+	if err := validateDescription(draft.Description); err != nil {
+		return nil, false, spanError(span, err)
+	}
+	if link.Metadata != nil {
+		metadata, err := normalizeMetadata(link.Metadata)
+		if err != nil {
+			return nil, false, spanError(span, err)
+		}
+		if string(metadata) != "null" {
+			draft.Metadata = metadata
+		}
+	}
+
+	log.Info().Str("original_url", destination).Msg("Creating new short URL")
+
+	var (
+		url     *model.URL
+		created bool
+	)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if reuse {
+			existing, err := s.findReusable(ctx, ws, domain, hash, legacyHash)
+			if err == nil {
+				url = existing
+				return nil
+			}
+			if !errors.Is(err, repo.ErrNotFound) {
+				return err
+			}
+		}
+
+		create := s.urlRepo.Create
+		if reuse {
+			create = s.urlRepo.CreateCanonical
+		}
+		inserted, err := create(ctx, draft)
+		if reuse && errors.Is(err, repo.ErrDuplicateRecord) {
+			// A concurrent creation of the same destination committed first; reuse its link.
+			existing, err := s.urlRepo.GetByURLHash(ctx, ws, domain, hash)
+			if err != nil {
+				return err
+			}
+			url = existing
+			return nil
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to create initial URL record")
+			return err
+		}
+		inserted.ShortCode = base62.Encode(inserted.ID)
+
+		// The cache is only warmed below, once the transaction has committed.
+		if err := s.audit.record(ctx, ws, model.AuditLinkCreated, inserted.ShortCode, nil, snapshotLink(inserted)); err != nil {
+			return err
+		}
+
+		if err := s.urlRepo.UpdateShortCode(ctx, inserted.WorkspaceID, inserted.ID, inserted.ShortCode); err != nil {
+			log.Error().Err(err).Int64("url_id", inserted.ID).Msg("Failed to update URL with short code")
+			return err
+		}
+		url, created = inserted, true
+		return nil
+	})
+	if err != nil {
+		return nil, false, spanError(span, err)
+	}
+	shortCode := url.ShortCode
+	span.SetAttributes(attribute.String("short_code", shortCode), attribute.Bool("reused", !created))
+	if !created {
+		log.Info().Str("short_code", shortCode).Int64("url_id", url.ID).Msg("Reusing existing short URL")
+		return url, false, nil
 	}
 
 	if err := s.cache.Set(ctx, url, time.Hour*24*7); err != nil {
-		s.logger.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to warm up cache")
+		log.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to warm up cache")
+	}
+
+	s.webhooks.NotifyLinkEvent(ctx, model.EventLinkCreated, url, "")
+
+	log.Info().Str("short_code", shortCode).Int64("url_id", url.ID).Msg("Successfully created short URL")
+	return url, true, nil
+}
+
+// findReusable returns the link of the workspace on domain whose destination hashes to hash.
+// Links hashed by migration 00014 and not rehashed since carry the hash of their destination as
+// given, so legacyHash, the hash of the destination before normalization, is looked up as well
+// when it is set.
+func (s *URLService) findReusable(ctx context.Context, workspaceID int64, domain string, hash, legacyHash []byte) (*model.URL, error) {
+	url, err := s.urlRepo.GetByURLHash(ctx, workspaceID, domain, hash)
+	if legacyHash == nil || !errors.Is(err, repo.ErrNotFound) {
+		return url, err
+	}
+	return s.urlRepo.GetByURLHash(ctx, workspaceID, domain, legacyHash)
+}
+
+// normalizeDestination canonicalizes a destination accepted by the URL policy and hashes it.
+func (s *URLService) normalizeDestination(destination string) (string, []byte, error) {
+	normalized, err := urlnorm.Normalize(destination, s.stripParams)
+	if err != nil {
+		return "", nil, rejectURL(ReasonInvalidURL, "destination is not a valid URL")
+	}
+	return normalized, urlnorm.Hash(normalized), nil
+}
+
+// strippedParams returns the query parameters removed from destinations; nil keeps them all.
+func strippedParams(cfg *config.Config) []string {
+	if !cfg.Links.StripTrackingParams {
+		return nil
+	}
+	return cfg.Links.TrackingParams
+}
+
+// UpdateURL changes the destination and details of an existing short URL on host; an empty
+// host names the base URL, as for every management call taking one.
+func (s *URLService) UpdateURL(ctx context.Context, host, shortCode string, update model.LinkUpdate) (*model.URL, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "URLService.UpdateURL", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	domain := s.domains.stored(host)
+	if update.OriginalURL != nil {
+		destination, err := s.policy.Check(ctx, *update.OriginalURL)
+		if err != nil {
+			log.Warn().Err(err).Str("original_url", *update.OriginalURL).Msg("Destination rejected by URL policy")
+			return nil, spanError(span, err)
+		}
+		destination, hash, err := s.normalizeDestination(destination)
+		if err != nil {
+			return nil, spanError(span, err)
+		}
+		update.OriginalURL, update.URLHash = &destination, hash
+	}
+	if update.Title != nil {
+		title := strings.TrimSpace(*update.Title)
+		if err := validateTitle(title); err != nil {
+			return nil, spanError(span, err)
+		}
+		update.Title = &title
+	}
+	if update.Description != nil {
+		description := strings.TrimSpace(*update.Description)
+		if err := validateDescription(description); err != nil {
+			return nil, spanError(span, err)
+		}
+		update.Description = &description
+	}
+	if update.Metadata != nil {
+		if update.Metadata, err = normalizeMetadata(update.Metadata); err != nil {
+			return nil, spanError(span, err)
+		}
+	}
+	if update.RedirectStatus != nil {
+		if err := validateRedirectStatus(*update.RedirectStatus); err != nil {
+			return nil, spanError(span, err)
+		}
+	}
+	if update.ExpiresAt != nil && !update.ExpiresAt.IsZero() {
+		if err := validateExpiry(*update.ExpiresAt, time.Now()); err != nil {
+			return nil, spanError(span, err)
+		}
+		expiresAt := update.ExpiresAt.UTC()
+		update.ExpiresAt = &expiresAt
+	}
+
+	var current, url *model.URL
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if current, err = s.urlRepo.GetByShortCode(ctx, ws, domain, shortCode); err != nil {
+			return err
+		}
+		if url, err = s.urlRepo.Update(ctx, ws, domain, shortCode, update); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, ws, model.AuditLinkUpdated, shortCode, snapshotLink(current), snapshotLink(url))
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	s.webhooks.NotifyLinkEvent(ctx, model.EventLinkUpdated, url, current.OriginalURL)
+
+	log.Info().Str("short_code", shortCode).Int64("url_id", url.ID).Msg("Short URL updated")
+	return url, nil
+}
+
+// DeleteURL removes a short URL on host together with its analytics.
+func (s *URLService) DeleteURL(ctx context.Context, host, shortCode string) error {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "URLService.DeleteURL", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return spanError(span, err)
+	}
+	domain := s.domains.stored(host)
+	var url *model.URL
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if url, err = s.urlRepo.Delete(ctx, ws, domain, shortCode); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, ws, model.AuditLinkDeleted, shortCode, snapshotLink(url), nil)
+	})
+	if err != nil {
+		return spanError(span, err)
+	}
+
+	s.webhooks.NotifyLinkEvent(ctx, model.EventLinkDeleted, url, "")
+
+	log.Info().Str("short_code", shortCode).Int64("url_id", url.ID).Msg("Short URL deleted")
+	return nil
+}
+
+// GetLink returns a short URL of the workspace on host.
+func (s *URLService) GetLink(ctx context.Context, host, shortCode string) (*model.URL, error) {
+	ctx, span := tracer.Start(ctx, "URLService.GetLink", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	domain := s.domains.stored(host)
+	url, err := s.urlRepo.GetByShortCode(ctx, ws, domain, shortCode)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return url, nil
+}
+
+// ListDomains returns the hosts the workspace's links can be served on.
+func (s *URLService) ListDomains(ctx context.Context) (*model.AvailableDomains, error) {
+	ctx, span := tracer.Start(ctx, "URLService.ListDomains")
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	domains, err := s.domains.Available(ctx, ws)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return domains, nil
+}
+
+// ListLinks returns a single page of the workspace's links, newest or most clicked first.
+func (s *URLService) ListLinks(ctx context.Context, filter model.LinkFilter) (*model.LinkPage, error) {
+	ctx, span := tracer.Start(ctx, "URLService.ListLinks")
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = model.LinkSortCreatedAt
+	}
+	if filter.Tag != "" {
+		if filter.Tag, err = normalizeTag(filter.Tag); err != nil {
+			return nil, spanError(span, err)
+		}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLinksPage
+	}
+	filter.Limit = min(filter.Limit, maxLinksPage)
+	pageSize := filter.Limit
+
+	// Fetch one extra row to find out whether another page follows.
+	filter.Limit++
+	links, err := s.urlRepo.List(ctx, ws, filter)
+	if err != nil {
+		return nil, spanError(span, fmt.Errorf("could not list links: %w", err))
+	}
+
+	page := &model.LinkPage{Links: links}
+	if len(links) > pageSize {
+		page.Links = links[:pageSize]
+		last := page.Links[pageSize-1]
+		page.Next = &model.LinkCursor{CreatedAt: last.CreatedAt, ClickCount: last.ClickCount, ID: last.ID}
+	}
+
+	if err := s.loadTags(ctx, ws, page.Links); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	return page, nil
+}
+
+// SetTags replaces the tags of a short URL on host. Tags are created in the workspace on first use.
+func (s *URLService) SetTags(ctx context.Context, host, shortCode string, tags []string) (*model.URL, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "URLService.SetTags", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	domain := s.domains.stored(host)
+	if tags, err = normalizeTags(tags); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	var url *model.URL
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.urlRepo.GetByShortCode(ctx, ws, domain, shortCode)
+		if err != nil {
+			return err
+		}
+		links := []model.URL{*current}
+		if err := s.loadTags(ctx, ws, links); err != nil {
+			return err
+		}
+		if err := s.tagRepo.SetURLTags(ctx, ws, current.ID, tags); err != nil {
+			return err
+		}
+		url = current
+		url.Tags = tags
+		return s.audit.record(ctx, ws, model.AuditLinkUpdated, shortCode, snapshotLink(&links[0]), snapshotLink(url))
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	log.Info().Str("short_code", shortCode).Strs("tags", tags).Msg("Short URL tags changed")
+	return url, nil
+}
+
+// MoveToFolder moves a short URL on host into a folder of its workspace, or out of any folder when
+// folderID is nil. An unknown folder yields repo.ErrNotFound, like an unknown short code.
+func (s *URLService) MoveToFolder(ctx context.Context, host, shortCode string, folderID *int64) (*model.URL, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "URLService.MoveToFolder", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	domain := s.domains.stored(host)
+
+	var url *model.URL
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.urlRepo.GetByShortCode(ctx, ws, domain, shortCode)
+		if err != nil {
+			return err
+		}
+		if url, err = s.urlRepo.SetFolder(ctx, ws, domain, shortCode, folderID); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, ws, model.AuditLinkUpdated, shortCode, snapshotLink(current), snapshotLink(url))
+	})
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	links := []model.URL{*url}
+	if err := s.loadTags(ctx, ws, links); err != nil {
+		return nil, spanError(span, err)
+	}
+	url = &links[0]
+
+	log.Info().Str("short_code", shortCode).Msg("Short URL moved to folder")
+	return url, nil
+}
+
+// ListTags returns the tags of the caller's workspace with the number of links carrying each.
+func (s *URLService) ListTags(ctx context.Context) ([]model.Tag, error) {
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, err
 	}
+	return s.tagRepo.List(ctx, ws)
+}
 
-	s.logger.Info().Str("short_code", shortCode).Int64("url_id", url.ID).Msg("Successfully created short URL")
+// DeleteTag removes a tag from the caller's workspace and from all of its links. The audit event
+// lists the links that carried it.
+func (s *URLService) DeleteTag(ctx context.Context, name string) error {
+	log := logger.FromContext(ctx, s.logger)
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return err
+	}
+	tag, err := normalizeTag(name)
+	if err != nil {
+		return err
+	}
+
+	var links []model.LinkRef
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if links, err = s.tagRepo.Delete(ctx, ws, tag); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, ws, model.AuditTagDeleted, "", tagSnapshot{Name: tag, Links: snapshotLinkRefs(links)}, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("tag", tag).Int("links", len(links)).Msg("Tag deleted")
+	return nil
+}
+
+// loadTags fills in the tags of links in place.
+func (s *URLService) loadTags(ctx context.Context, workspaceID int64, links []model.URL) error {
+	if len(links) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+	}
+
+	tags, err := s.tagRepo.ListByURLs(ctx, workspaceID, ids)
+	if err != nil {
+		return fmt.Errorf("could not load tags: %w", err)
+	}
+	for i := range links {
+		links[i].Tags = tags[links[i].ID]
+	}
+	return nil
+}
+
+// ResolveShortCode finds the link behind a public short code on the requested host without
+// recording a click. It serves requests that look at a link without following it, such as
+// HEAD requests.
+func (s *URLService) ResolveShortCode(ctx context.Context, host, shortCode string) (*model.URL, error) {
+	ctx, span := tracer.Start(ctx, "URLService.ResolveShortCode", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	url, err := s.resolve(ctx, host, shortCode)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
 	return url, nil
 }
 
-// ProcessRedirect finds the original URL for a given short code and records the click for analytics.
-func (s *URLService) ProcessRedirect(ctx context.Context, shortCode, userAgent, ipAddress string) (*model.URL, error) {
-	url, err := s.urlRepo.GetByShortCode(ctx, shortCode)
+// ConfirmationToken returns the token with which the preview page of link lets the visitor
+// continue to its destination, valid for the configured time.
+func (s *URLService) ConfirmationToken(link *model.URL) string {
+	return s.previews.issue(link, time.Now())
+}
+
+// Confirmed reports whether token is a valid confirmation token of link's preview page.
+func (s *URLService) Confirmed(link *model.URL, token string) bool {
+	return token != "" && s.previews.valid(link, token, time.Now())
+}
+
+// resolve finds the link of a short code on the domain served under the request host.
+// An expired link yields ErrLinkExpired.
+func (s *URLService) resolve(ctx context.Context, host, shortCode string) (*model.URL, error) {
+	domain, err := s.domains.ForRequest(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve domain of %q: %w", host, err)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("domain", domain))
+	url, err := s.urlRepo.Resolve(ctx, domain, shortCode)
 	if err != nil {
 		return nil, err
 	}
+	if url.Expired(time.Now()) {
+		return nil, ErrLinkExpired
+	}
+	return url, nil
+}
+
+// ProcessRedirect finds the original URL for a given short code on the requested host and records
+// the click for analytics. Redirects are public, so the short code is resolved regardless of the
+// workspace owning it.
+// The visit carries the request details (user agent, IP, referrer, country) of the click.
+// The click is recorded in the background under its own trace, linked to the request span,
+// so the redirect does not wait for it and the request's cancellation does not abort it.
+// Suspicious links are only followed with a valid confirmation token of the preview page; until
+// then the link is returned without recording a click.
+func (s *URLService) ProcessRedirect(ctx context.Context, host, shortCode string, visit model.Click, confirmation string) (*model.URL, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ctx, span := tracer.Start(ctx, "URLService.ProcessRedirect", trace.WithAttributes(attribute.String("short_code", shortCode)))
+	defer span.End()
+
+	url, err := s.resolve(ctx, host, shortCode)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if url.Suspicious && !s.Confirmed(url, confirmation) {
+		span.SetAttributes(attribute.Bool("interstitial", true))
+		return url, nil
+	}
 
+	clickCtx, clickSpan := tracer.Start(context.WithoutCancel(ctx), "URLService.recordClick",
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+		trace.WithAttributes(attribute.String("short_code", shortCode)),
+	)
+
+	s.metrics.inFlight.Inc()
 	go func() {
-		click := &model.Click{
-			URLID:     url.ID,
-			UserAgent: userAgent,
-			IPAddress: ipAddress,
+		defer s.metrics.inFlight.Dec()
+		defer clickSpan.End()
+
+		ua := useragent.Parse(visit.UserAgent)
+		click := visit
+		click.URLID = url.ID
+		click.IsBot = ua.IsBot
+		click.CreatedAt = time.Now()
+
+		clickCount, err := s.clickRepo.Create(clickCtx, &click)
+		if err != nil {
+			spanError(clickSpan, err)
+			s.metrics.writes.WithLabelValues("failed").Inc()
+			log.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to record click")
+			return
 		}
-		if err := s.clickRepo.Create(context.Background(), click); err != nil {
-			s.logger.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to record click")
+		s.metrics.writes.WithLabelValues("recorded").Inc()
+
+		event := &model.ClickEvent{
+			ShortCode: url.ShortCode,
+			Domain:    url.Domain,
+			Timestamp: click.CreatedAt,
+			Browser:   ua.Browser,
+			OS:        ua.OS,
+			Device:    ua.Device,
+			IsBot:     ua.IsBot,
+			Country:   click.Country,
+			Referrer:  click.Referrer,
+			Source:    click.Source,
+		}
+		if err := s.stream.Publish(clickCtx, event); err != nil {
+			log.Error().Err(err).Int64("url_id", url.ID).Msg("Failed to publish live click event")
 		}
+
+		s.webhooks.NotifyClickCount(clickCtx, url, clickCount)
 	}()
 
 	return url, nil
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/pkg/urlnorm"
+	"testing"
+)
+
+// hashedLinks finds links by their stored hash; other methods are not used.
+type hashedLinks struct {
+	repo.URLRepository
+	links []model.URL
+}
+
+func (r hashedLinks) GetByURLHash(_ context.Context, workspaceID int64, domain string, hash []byte) (*model.URL, error) {
+	for i, link := range r.links {
+		if link.WorkspaceID == workspaceID && link.Domain == domain && bytes.Equal(link.URLHash, hash) {
+			return &r.links[i], nil
+		}
+	}
+	return nil, repo.ErrNotFound
+}
+
+func TestFindReusableFallsBackToLegacyHash(t *testing.T) {
+	const raw = "HTTPS://Example.com/a?utm_source=x"
+	normalized, err := urlnorm.Normalize(raw, []string{"utm_*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &URLService{urlRepo: hashedLinks{links: []model.URL{
+		// Hashed by migration 00014 as stored.
+		{ShortCode: "legacy", WorkspaceID: 1, OriginalURL: raw, URLHash: urlnorm.Hash(raw)},
+		{ShortCode: "current", WorkspaceID: 2, OriginalURL: normalized, URLHash: urlnorm.Hash(normalized)},
+	}}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		workspaceID int64
+		legacyHash  []byte
+		want        string
+	}{
+		{name: "legacy link found by the hash as given", workspaceID: 1, legacyHash: urlnorm.Hash(raw), want: "legacy"},
+		{name: "no fallback without a legacy hash", workspaceID: 1},
+		{name: "normalized hash wins", workspaceID: 2, legacyHash: urlnorm.Hash(raw), want: "current"},
+		{name: "other workspace", workspaceID: 3, legacyHash: urlnorm.Hash(raw)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, err := s.findReusable(ctx, tt.workspaceID, "", urlnorm.Hash(normalized), tt.legacyHash)
+			if tt.want == "" {
+				if !errors.Is(err, repo.ErrNotFound) {
+					t.Fatalf("findReusable() = %+v, %v, want repo.ErrNotFound", url, err)
+				}
+				return
+			}
+			if err != nil || url.ShortCode != tt.want {
+				t.Fatalf("findReusable() = %+v, %v, want %s", url, err, tt.want)
+			}
+		})
+	}
+}
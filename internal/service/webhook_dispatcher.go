@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/rs/zerolog"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers sent with every webhook delivery.
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookDispatcher sends queued webhook deliveries in the background.
+// Deliveries are retried with exponential backoff and dead-lettered after cfg.MaxAttempts.
+type WebhookDispatcher struct {
+	webhookRepo repo.WebhookRepository
+	client      *http.Client
+	cfg         config.WebhookConfig
+	logger      zerolog.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWebhookDispatcher creates a new instance of WebhookDispatcher. When private networks are
+// blocked, connections to them are refused at dial time, after every DNS lookup and redirect.
+func NewWebhookDispatcher(webhookRepo repo.WebhookRepository, cfg *config.Config, logger *zerolog.Logger) *WebhookDispatcher {
+	dialer := &net.Dialer{Timeout: cfg.Webhooks.Timeout}
+	if cfg.URLPolicy.BlockPrivateNetworks {
+		dialer.Control = publicDialControl
+	}
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		client: &http.Client{
+			Timeout: cfg.Webhooks.Timeout,
+			// No proxy, so the dialed address is the endpoint itself.
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: cfg.Webhooks.Timeout,
+				MaxIdleConnsPerHost: 2,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+		cfg:    cfg.Webhooks,
+		logger: logger.With().Str("layer", "webhook_dispatcher").Logger(),
+	}
+}
+
+// Start launches the polling loop.
+func (d *WebhookDispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.run(ctx)
+	}()
+	d.logger.Info().Dur("poll_interval", d.cfg.PollInterval).Msg("Webhook dispatcher started")
+}
+
+// Stop stops the polling loop and waits for in-flight deliveries to finish.
+func (d *WebhookDispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+	d.logger.Info().Msg("Webhook dispatcher stopped")
+}
+
+func (d *WebhookDispatcher) run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatchDue(ctx)
+		}
+	}
+}
+
+// dispatchDue claims a batch of due deliveries and sends them concurrently.
+// The lease outlives the HTTP timeout, so a delivery is never sent twice at once.
+func (d *WebhookDispatcher) dispatchDue(ctx context.Context) {
+	deliveries, err := d.webhookRepo.ClaimDue(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error().Err(err).Msg("Failed to claim due webhook deliveries")
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver sends a single delivery and records the outcome.
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery) {
+	log := d.logger.With().
+		Int64("delivery_id", delivery.ID).
+		Int64("subscription_id", delivery.SubscriptionID).
+		Str("event_type", delivery.EventType).
+		Int("attempt", delivery.Attempts).
+		Logger()
+
+	statusCode, err := d.send(ctx, delivery)
+	// Outcomes are recorded even when shutting down, otherwise the attempt would be lost.
+	recordCtx := context.WithoutCancel(ctx)
+
+	if err == nil {
+		if err := d.webhookRepo.MarkSucceeded(recordCtx, delivery.ID, statusCode); err != nil {
+			log.Error().Err(err).Msg("Failed to record webhook delivery success")
+			return
+		}
+		log.Info().Int("status_code", statusCode).Msg("Webhook delivered")
+		return
+	}
+
+	status := model.DeliveryPending
+	nextAttempt := time.Now().Add(d.backoff(delivery.Attempts))
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		status = model.DeliveryDead
+	}
+
+	if err := d.webhookRepo.MarkFailed(recordCtx, delivery.ID, status, statusCode, err.Error(), nextAttempt); err != nil {
+		log.Error().Err(err).Msg("Failed to record webhook delivery failure")
+		return
+	}
+
+	if status == model.DeliveryDead {
+		log.Error().Err(err).Int("status_code", statusCode).Msg("Webhook delivery dead-lettered")
+		return
+	}
+	log.Warn().Err(err).Int("status_code", statusCode).Time("next_attempt_at", nextAttempt).Msg("Webhook delivery failed, will retry")
+}
+
+// send POSTs the signed payload. Any non-2xx response is treated as a failure. Only the status
+// is kept; the response body is discarded so endpoints cannot be read through the history.
+func (d *WebhookDispatcher) send(ctx context.Context, delivery model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.TargetURL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "shortener-webhooks/1.0")
+	req.Header.Set(HeaderWebhookEvent, delivery.EventType)
+	req.Header.Set(HeaderWebhookDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, "sha256="+SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt: BackoffBase doubled per attempt, capped at BackoffMax.
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for i := 1; i < attempts && delay < d.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.BackoffMax)
+}
+
+// SignWebhookPayload computes the hex HMAC-SHA256 of "timestamp.payload" with the subscription secret.
+// Receivers recompute it to verify the X-Webhook-Signature header.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"github.com/rs/zerolog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestDispatcher(blockPrivate bool) *WebhookDispatcher {
+	cfg := &config.Config{
+		Webhooks:  config.WebhookConfig{Timeout: 5 * time.Second},
+		URLPolicy: config.URLPolicyConfig{BlockPrivateNetworks: blockPrivate},
+	}
+	logger := zerolog.Nop()
+	return NewWebhookDispatcher(nil, cfg, &logger)
+}
+
+func TestWebhookDispatcherRefusesPrivateAddresses(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := newTestDispatcher(true).send(context.Background(), model.WebhookDelivery{TargetURL: server.URL, Payload: []byte(`{}`)})
+	if err == nil || !strings.Contains(err.Error(), "not publicly routable") {
+		t.Fatalf("send to %s: err = %v, want a refused dial", server.URL, err)
+	}
+	if called {
+		t.Error("the loopback endpoint was reached")
+	}
+}
+
+func TestWebhookDispatcherRefusesMetadataAddress(t *testing.T) {
+	_, err := newTestDispatcher(true).send(context.Background(), model.WebhookDelivery{TargetURL: "http://169.254.169.254/latest/meta-data/", Payload: []byte(`{}`)})
+	if err == nil || !strings.Contains(err.Error(), "not publicly routable") {
+		t.Fatalf("err = %v, want a refused dial", err)
+	}
+}
+
+func TestWebhookDispatcherKeepsOnlyTheStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("internal secret"))
+	}))
+	defer server.Close()
+
+	status, err := newTestDispatcher(false).send(context.Background(), model.WebhookDelivery{TargetURL: server.URL, Payload: []byte(`{}`)})
+	if status != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", status, http.StatusInternalServerError)
+	}
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("err = %v, want the status without the response body", err)
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/rs/zerolog"
+	"slices"
+	"time"
+)
+
+const (
+	defaultDeliveriesPage = 50
+	maxDeliveriesPage     = 200
+)
+
+// WebhookService manages webhook subscriptions and queues link events for delivery.
+// Queuing only writes to the delivery table; the WebhookDispatcher sends them later.
+type WebhookService struct {
+	webhookRepo repo.WebhookRepository
+	policy      *URLPolicy
+	logger      zerolog.Logger
+}
+
+// NewWebhookService creates a new instance of WebhookService.
+func NewWebhookService(webhookRepo repo.WebhookRepository, policy *URLPolicy, logger *zerolog.Logger) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		policy:      policy,
+		logger:      logger.With().Str("layer", "webhook_service").Logger(),
+	}
+}
+
+// CreateSubscription validates and registers a webhook endpoint. Endpoints on loopback, private
+// and link-local networks are refused like link destinations. A signing secret is generated
+// when none is provided; it is only ever returned from this call.
+func (s *WebhookService) CreateSubscription(ctx context.Context, sub model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	log := logger.FromContext(ctx, s.logger)
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.policy.CheckWebhookTarget(ctx, sub.URL); err != nil {
+		var rejected *URLRejectedError
+		if errors.As(err, &rejected) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidWebhook, rejected.Message)
+		}
+		return nil, err
+	}
+	if len(sub.Events) == 0 {
+		return nil, fmt.Errorf("%w: at least one event is required", ErrInvalidWebhook)
+	}
+	for _, event := range sub.Events {
+		if !slices.Contains(model.WebhookEventTypes, event) {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, event)
+		}
+	}
+	if slices.Contains(sub.Events, model.EventLinkClickThreshold) && sub.ClickThreshold <= 0 {
+		return nil, fmt.Errorf("%w: click_threshold is required for %s", ErrInvalidWebhook, model.EventLinkClickThreshold)
+	}
+
+	if sub.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		sub.Secret = secret
+	}
+
+	sub.WorkspaceID = ws
+	created, err := s.webhookRepo.CreateSubscription(ctx, &sub)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Int64("subscription_id", created.ID).Strs("events", created.Events).Msg("Webhook subscription created")
+	return created, nil
+}
+
+// ListSubscriptions returns the webhook subscriptions of the caller's workspace.
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.ListSubscriptions(ctx, ws)
+}
+
+// DeleteSubscription removes a webhook subscription and its delivery history.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id int64) error {
+	ws, err := authorize(ctx, model.RoleEditor)
+	if err != nil {
+		return err
+	}
+	return s.webhookRepo.DeleteSubscription(ctx, ws, id)
+}
+
+// ListDeliveries returns a page of the delivery history of a subscription.
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID int64, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	ws, err := authorize(ctx, model.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.webhookRepo.GetSubscription(ctx, ws, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultDeliveriesPage
+	}
+	filter.Limit = min(filter.Limit, maxDeliveriesPage)
+
+	return s.webhookRepo.ListDeliveries(ctx, subscriptionID, filter)
+}
+
+// NotifyLinkEvent queues an event about a link for every subscriber of the event type
+// in the link's workspace.
+// previousURL is only set for link.updated.
+func (s *WebhookService) NotifyLinkEvent(ctx context.Context, eventType string, link *model.URL, previousURL string) {
+	log := logger.FromContext(ctx, s.logger)
+	if err := s.queueLinkEvent(ctx, eventType, link, previousURL); err != nil {
+		log.Error().Err(err).Str("event_type", eventType).Str("short_code", link.ShortCode).Msg("Failed to queue webhook event")
+	}
+}
+
+// queueLinkEvent queues an event about a link like NotifyLinkEvent, but returns failures, so
+// callers within a transaction can roll back the change the event announces.
+func (s *WebhookService) queueLinkEvent(ctx context.Context, eventType string, link *model.URL, previousURL string) error {
+	log := logger.FromContext(ctx, s.logger)
+	payload, err := s.linkPayload(eventType, link, previousURL)
+	if err != nil {
+		return err
+	}
+
+	n, err := s.webhookRepo.Enqueue(ctx, link.WorkspaceID, eventType, payload)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Info().Str("event_type", eventType).Str("short_code", link.ShortCode).Int64("deliveries", n).Msg("Webhook event queued")
+	}
+	return nil
+}
+
+// NotifyClickCount queues link.click_threshold for subscriptions whose threshold equals the
+// link's new click count. Since the count grows by one per click, each threshold fires once.
+func (s *WebhookService) NotifyClickCount(ctx context.Context, link *model.URL, clickCount int64) {
+	log := logger.FromContext(ctx, s.logger)
+	linkAt := *link
+	linkAt.ClickCount = clickCount
+	payload, err := s.linkPayload(model.EventLinkClickThreshold, &linkAt, "")
+	if err != nil {
+		return
+	}
+
+	n, err := s.webhookRepo.EnqueueClickThreshold(ctx, link.WorkspaceID, clickCount, payload)
+	if err != nil {
+		log.Error().Err(err).Str("short_code", link.ShortCode).Msg("Failed to queue click threshold event")
+		return
+	}
+	if n > 0 {
+		log.Info().Str("short_code", link.ShortCode).Int64("click_count", clickCount).Int64("deliveries", n).Msg("Click threshold event queued")
+	}
+}
+
+// linkPayload builds the JSON body of a link event.
+func (s *WebhookService) linkPayload(eventType string, link *model.URL, previousURL string) ([]byte, error) {
+	event := model.WebhookEvent{
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Link: model.WebhookLinkData{
+			ShortCode:   link.ShortCode,
+			OriginalURL: link.OriginalURL,
+			PreviousURL: previousURL,
+			CreatedAt:   link.CreatedAt,
+			ClickCount:  link.ClickCount,
+			ExpiresAt:   link.ExpiresAt,
+		},
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error().Err(err).Str("event_type", eventType).Msg("Failed to marshal webhook event")
+		return nil, err
+	}
+	return payload, nil
+}
+
+// generateSecret returns a random hex-encoded 256-bit signing secret.
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
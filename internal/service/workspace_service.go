@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/rs/zerolog"
+)
+
+// WorkspaceService manages workspaces and their members.
+type WorkspaceService struct {
+	workspaceRepo repo.WorkspaceRepository
+	domains       *Domains
+	tx            repo.Transactor
+	audit         *AuditService
+	logger        zerolog.Logger
+}
+
+// NewWorkspaceService creates a new instance of WorkspaceService.
+func NewWorkspaceService(
+	workspaceRepo repo.WorkspaceRepository,
+	domains *Domains,
+	tx repo.Transactor,
+	audit *AuditService,
+	logger *zerolog.Logger,
+) *WorkspaceService {
+	return &WorkspaceService{
+		workspaceRepo: workspaceRepo,
+		domains:       domains,
+		tx:            tx,
+		audit:         audit,
+		logger:        logger.With().Str("layer", "workspace_service").Logger(),
+	}
+}
+
+// CreateWorkspace creates a new, empty workspace. Only operators may create workspaces.
+func (s *WorkspaceService) CreateWorkspace(ctx context.Context, name string) (*model.Workspace, error) {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.CreateWorkspace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Int64("workspace_id", workspace.ID).Str("name", name).Msg("Workspace created")
+	return workspace, nil
+}
+
+// ListWorkspaces returns all workspaces. Only operators may list workspaces.
+func (s *WorkspaceService) ListWorkspaces(ctx context.Context) ([]model.Workspace, error) {
+	if err := authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListWorkspaces(ctx)
+}
+
+// AddUser creates a user and makes them a member of a workspace with role.
+// An unknown workspace yields repo.ErrNotFound, a taken email repo.ErrDuplicateRecord.
+func (s *WorkspaceService) AddUser(ctx context.Context, workspaceID int64, email, name, role string) (*model.User, error) {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeMembership(ctx, workspaceID, role); err != nil {
+		return nil, err
+	}
+
+	var user *model.User
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.workspaceRepo.CreateUser(ctx, email, name); err != nil {
+			return err
+		}
+		return s.addMember(ctx, workspaceID, user.ID, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Int64("workspace_id", workspaceID).Int64("user_id", user.ID).Str("role", role).Msg("User added to workspace")
+	return user, nil
+}
+
+// AddMember makes an existing user a member of a workspace with role.
+func (s *WorkspaceService) AddMember(ctx context.Context, workspaceID, userID int64, role string) error {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeMembership(ctx, workspaceID, role); err != nil {
+		return err
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.addMember(ctx, workspaceID, userID, role)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int64("workspace_id", workspaceID).Int64("user_id", userID).Str("role", role).Msg("Member added to workspace")
+	return nil
+}
+
+// SetMemberRole changes the role of a member. API keys acting on behalf of the member
+// pick up the new role with their next request.
+func (s *WorkspaceService) SetMemberRole(ctx context.Context, workspaceID, userID int64, role string) error {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeMembership(ctx, workspaceID, role); err != nil {
+		return err
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		previous, err := s.workspaceRepo.GetMemberRole(ctx, workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.workspaceRepo.UpdateMemberRole(ctx, workspaceID, userID, role); err != nil {
+			return err
+		}
+		return s.audit.record(ctx, workspaceID, model.AuditMemberRoleChanged, "",
+			memberSnapshot{UserID: userID, Role: previous}, memberSnapshot{UserID: userID, Role: role})
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int64("workspace_id", workspaceID).Int64("user_id", userID).Str("role", role).Msg("Member role changed")
+	return nil
+}
+
+// SetReuseExistingLinks changes whether creating a link in the workspace returns an existing
+// link with the same normalized destination instead of creating another one. Only owners may change it.
+func (s *WorkspaceService) SetReuseExistingLinks(ctx context.Context, workspaceID int64, reuse bool) error {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeWorkspace(ctx, workspaceID, model.RoleOwner); err != nil {
+		return err
+	}
+
+	if err := s.workspaceRepo.SetReuseExistingLinks(ctx, workspaceID, reuse); err != nil {
+		return err
+	}
+
+	log.Info().Int64("workspace_id", workspaceID).Bool("reuse_existing_links", reuse).Msg("Workspace settings changed")
+	return nil
+}
+
+// AddDomain registers host for a workspace, so its links can be created on and served from it.
+// The host must point at this service; only operators, who set up its DNS and certificates,
+// may register hosts. A host registered already yields repo.ErrDuplicateRecord.
+func (s *WorkspaceService) AddDomain(ctx context.Context, workspaceID int64, host string) (*model.Domain, error) {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	host = normalizeHost(host)
+	if err := s.domains.validateRegistration(host); err != nil {
+		return nil, err
+	}
+	domain, err := s.workspaceRepo.AddDomain(ctx, workspaceID, host)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Int64("workspace_id", workspaceID).Str("host", host).Msg("Workspace domain added")
+	return domain, nil
+}
+
+// ListDomains returns the hosts registered for a workspace.
+func (s *WorkspaceService) ListDomains(ctx context.Context, workspaceID int64) ([]model.Domain, error) {
+	if err := authorizeWorkspace(ctx, workspaceID, model.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListDomains(ctx, workspaceID)
+}
+
+// RemoveDomain removes host from a workspace. Links created on it are kept but no longer
+// served until the host is registered again. Only operators may remove hosts.
+func (s *WorkspaceService) RemoveDomain(ctx context.Context, workspaceID int64, host string) error {
+	log := logger.FromContext(ctx, s.logger)
+	if err := authorizeOperator(ctx); err != nil {
+		return err
+	}
+
+	host = normalizeHost(host)
+	if err := s.workspaceRepo.RemoveDomain(ctx, workspaceID, host); err != nil {
+		return err
+	}
+
+	log.Info().Int64("workspace_id", workspaceID).Str("host", host).Msg("Workspace domain removed")
+	return nil
+}
+
+// ListMembers returns the members of a workspace with their roles.
+func (s *WorkspaceService) ListMembers(ctx context.Context, workspaceID int64) ([]model.Member, error) {
+	if err := authorizeWorkspace(ctx, workspaceID, model.RoleViewer); err != nil {
+		return nil, err
+	}
+	return s.workspaceRepo.ListMembers(ctx, workspaceID)
+}
+
+// addMember adds the membership and its audit event; it must run in a transaction.
+func (s *WorkspaceService) addMember(ctx context.Context, workspaceID, userID int64, role string) error {
+	if err := s.workspaceRepo.AddMember(ctx, workspaceID, userID, role); err != nil {
+		return err
+	}
+	return s.audit.record(ctx, workspaceID, model.AuditMemberAdded, "", nil, memberSnapshot{UserID: userID, Role: role})
+}
+
+// authorizeMembership checks that the caller may grant role in a workspace: only owners manage members.
+func authorizeMembership(ctx context.Context, workspaceID int64, role string) error {
+	if !model.ValidRole(role) {
+		return fmt.Errorf("%w: %q", ErrInvalidRole, role)
+	}
+	return authorizeWorkspace(ctx, workspaceID, model.RoleOwner)
+}
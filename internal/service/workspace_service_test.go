@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+// changedWorkspaces counts the changes made to memberships and hosts; other methods are not used.
+type changedWorkspaces struct {
+	repo.WorkspaceRepository
+	changes int
+}
+
+func (w *changedWorkspaces) CreateUser(_ context.Context, email, name string) (*model.User, error) {
+	w.changes++
+	return &model.User{ID: 5, Email: email, Name: name}, nil
+}
+
+func (w *changedWorkspaces) AddMember(_ context.Context, _, _ int64, _ string) error {
+	w.changes++
+	return nil
+}
+
+func (w *changedWorkspaces) GetMemberRole(_ context.Context, _, _ int64) (string, error) {
+	return model.RoleViewer, nil
+}
+
+func (w *changedWorkspaces) UpdateMemberRole(_ context.Context, _, _ int64, _ string) error {
+	w.changes++
+	return nil
+}
+
+func (w *changedWorkspaces) AddDomain(_ context.Context, workspaceID int64, host string) (*model.Domain, error) {
+	w.changes++
+	return &model.Domain{WorkspaceID: workspaceID, Host: host}, nil
+}
+
+func (w *changedWorkspaces) RemoveDomain(_ context.Context, _ int64, _ string) error {
+	w.changes++
+	return nil
+}
+
+func newTestWorkspaceService(workspaces repo.WorkspaceRepository) *WorkspaceService {
+	logger := zerolog.Nop()
+	cfg := &config.Config{HTTP: config.HTTPConfig{BaseURL: "https://sho.rt"}}
+	return NewWorkspaceService(workspaces, NewDomains(workspaces, cfg), &inlineTx{}, NewAuditService(&recordedAudit{}, &logger), &logger)
+}
+
+func TestWorkspaceServiceAuthorization(t *testing.T) {
+	operations := []struct {
+		name string
+		call func(ctx context.Context, s *WorkspaceService) error
+	}{
+		{name: "AddUser", call: func(ctx context.Context, s *WorkspaceService) error {
+			_, err := s.AddUser(ctx, 3, "ann@example.com", "Ann", model.RoleViewer)
+			return err
+		}},
+		{name: "AddMember", call: func(ctx context.Context, s *WorkspaceService) error {
+			return s.AddMember(ctx, 3, 5, model.RoleViewer)
+		}},
+		{name: "SetMemberRole", call: func(ctx context.Context, s *WorkspaceService) error {
+			return s.SetMemberRole(ctx, 3, 5, model.RoleEditor)
+		}},
+		{name: "AddDomain", call: func(ctx context.Context, s *WorkspaceService) error {
+			_, err := s.AddDomain(ctx, 3, "go.brand.com")
+			return err
+		}},
+		{name: "RemoveDomain", call: func(ctx context.Context, s *WorkspaceService) error {
+			return s.RemoveDomain(ctx, 3, "go.brand.com")
+		}},
+	}
+	callers := []struct {
+		name string
+		key  *model.APIKey
+		// allowed lists the operations the caller may perform.
+		allowed map[string]bool
+	}{
+		{name: "operator", allowed: map[string]bool{"AddUser": true, "AddMember": true, "SetMemberRole": true, "AddDomain": true, "RemoveDomain": true}},
+		{name: "owner", key: &model.APIKey{WorkspaceID: 3, Role: model.RoleOwner}, allowed: map[string]bool{"AddUser": true, "AddMember": true, "SetMemberRole": true}},
+		{name: "editor", key: &model.APIKey{WorkspaceID: 3, Role: model.RoleEditor}},
+		{name: "viewer", key: &model.APIKey{WorkspaceID: 3, Role: model.RoleViewer}},
+	}
+	for _, caller := range callers {
+		for _, op := range operations {
+			t.Run(caller.name+"/"+op.name, func(t *testing.T) {
+				workspaces := &changedWorkspaces{}
+				ctx := context.Background()
+				if caller.key != nil {
+					ctx = ContextWithAPIKey(ctx, caller.key)
+				}
+
+				err := op.call(ctx, newTestWorkspaceService(workspaces))
+				if caller.allowed[op.name] {
+					if err != nil || workspaces.changes == 0 {
+						t.Fatalf("%s() error = %v with %d changes, want it to succeed", op.name, err, workspaces.changes)
+					}
+					return
+				}
+				if !errors.Is(err, ErrForbidden) {
+					t.Fatalf("%s() error = %v, want ErrForbidden", op.name, err)
+				}
+				if workspaces.changes != 0 {
+					t.Fatalf("%s() made %d changes although it was forbidden", op.name, workspaces.changes)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Ensures AnalyticsRepository implements the interface.
+var _ repo.AnalyticsRepository = (*AnalyticsRepository)(nil)
+
+// AnalyticsRepository implements the domain.repository.AnalyticsRepository interface.
+type AnalyticsRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	logger  zerolog.Logger
+}
+
+// NewAnalyticsRepository creates a new instance of AnalyticsRepository.
+func NewAnalyticsRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *AnalyticsRepository {
+	return &AnalyticsRepository{
+		pool:    pool,
+		queries: db.New(pool),
+		logger:  logger.With().Str("layer", "postgres_analytics_repository").Logger(),
+	}
+}
+
+// GetRecentClicks fetches at most limit of the newest click events for a given URL ID.
+func (r *AnalyticsRepository) GetRecentClicks(ctx context.Context, workspaceID, urlID int64, limit int) ([]model.Click, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetRecentClicksByURLIDParams{
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+		Limit:       int32(limit),
+	}
+	dbClicks, err := r.queries.GetRecentClicksByURLID(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Failed to get recent clicks")
+		return nil, fmt.Errorf("postgres: GetRecentClicksByURLID failed: %w", err)
+	}
+	return toDomainClicks(dbClicks), nil
+}
+
+// CountClicks counts all click events for a given URL ID.
+func (r *AnalyticsRepository) CountClicks(ctx context.Context, workspaceID, urlID int64) (int64, error) {
+	log := logger.FromContext(ctx, r.logger)
+	count, err := r.queries.CountClicksByURLID(ctx, db.CountClicksByURLIDParams{
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Failed to count clicks")
+		return 0, fmt.Errorf("postgres: CountClicksByURLID failed: %w", err)
+	}
+	return count, nil
+}
+
+// ListClicks fetches a page of click events using keyset pagination on (created_at, id).
+func (r *AnalyticsRepository) ListClicks(ctx context.Context, workspaceID, urlID int64, filter model.ClickFilter) ([]model.Click, error) {
+	log := logger.FromContext(ctx, r.logger)
+	dbClicks, err := r.queries.ListClicks(ctx, toDBListClicksParams(workspaceID, urlID, filter))
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Failed to list clicks")
+		return nil, fmt.Errorf("postgres: ListClicks failed: %w", err)
+	}
+	return toDomainClicks(dbClicks), nil
+}
+
+// streamClicksQuery mirrors ListClicks without keyset and limit. It is kept out of sqlc
+// because generated :many methods collect every row into a slice before returning.
+const streamClicksQuery = `
+SELECT id, url_id, created_at, user_agent, ip_address, is_bot, referrer, country, source
+FROM clicks
+WHERE workspace_id = $1
+  AND url_id = $2
+  AND ($3::timestamptz IS NULL OR created_at >= $3)
+  AND ($4::timestamptz IS NULL OR created_at < $4)
+  AND ($5::boolean IS NULL OR is_bot = $5)
+  AND ($6::text IS NULL OR user_agent ILIKE '%' || $6 || '%' ESCAPE '\')
+ORDER BY created_at DESC, id DESC
+`
+
+// StreamClicks iterates over matching clicks row by row as pgx reads them from the connection.
+func (r *AnalyticsRepository) StreamClicks(ctx context.Context, workspaceID, urlID int64, filter model.ClickFilter, fn func(model.Click) error) error {
+	log := logger.FromContext(ctx, r.logger)
+	params := toDBListClicksParams(workspaceID, urlID, filter)
+	rows, err := r.pool.Query(ctx, streamClicksQuery,
+		params.WorkspaceID,
+		params.UrlID,
+		params.FromTime,
+		params.ToTime,
+		params.IsBot,
+		params.UserAgent,
+	)
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Failed to start click stream")
+		return fmt.Errorf("postgres: StreamClicks failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c db.Click
+		if err := rows.Scan(&c.ID, &c.UrlID, &c.CreatedAt, &c.UserAgent, &c.IpAddress, &c.IsBot, &c.Referrer, &c.Country, &c.Source); err != nil {
+			return fmt.Errorf("postgres: StreamClicks scan failed: %w", err)
+		}
+		if err := fn(toDomainClick(c)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Click stream interrupted")
+		return fmt.Errorf("postgres: StreamClicks failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetClicksByPeriod fetches click counts aggregated by a time period.
+func (r *AnalyticsRepository) GetClicksByPeriod(ctx context.Context, workspaceID, urlID int64, period string) ([]model.AggregatedStat, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetClicksByPeriodParams{
+		Period:      period,
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+	}
+	rows, err := r.queries.GetClicksByPeriod(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Str("period", period).Msg("Failed to get clicks by period")
+		return nil, fmt.Errorf("postgres: GetClicksByPeriod failed: %w", err)
+	}
+
+	return toAggregatedStatsFromTime(rows), nil
+}
+
+// GetClicksByUserAgent fetches click counts aggregated by user agent.
+func (r *AnalyticsRepository) GetClicksByUserAgent(ctx context.Context, workspaceID, urlID int64) ([]model.AggregatedStat, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := r.queries.GetClicksByUserAgent(ctx, db.GetClicksByUserAgentParams{
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Failed to get clicks by user agent")
+		return nil, fmt.Errorf("postgres: GetClicksByUserAgent failed: %w", err)
+	}
+
+	return toAggregatedStatsFromString(rows), nil
+}
+
+// GetClicksByPeriodAndUserAgent fetches click counts aggregated by both time period and user agent.
+func (r *AnalyticsRepository) GetClicksByPeriodAndUserAgent(ctx context.Context, workspaceID, urlID int64, period string, from, to time.Time) ([]model.AggregatedStatDetailed, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetClicksByPeriodAndUserAgentParams{
+		Period:      period,
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+	}
+	rows, err := r.queries.GetClicksByPeriodAndUserAgent(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Str("period", period).Msg("Failed to get detailed analytics")
+		return nil, fmt.Errorf("postgres: GetClicksByPeriodAndUserAgent failed: %w", err)
+	}
+
+	return toAggregatedStatsDetailed(rows), nil
+}
+
+// GetClicksByPeriodAndSource fetches click counts aggregated by both time period and click source.
+func (r *AnalyticsRepository) GetClicksByPeriodAndSource(ctx context.Context, workspaceID, urlID int64, period string, from, to time.Time) ([]model.AggregatedStatDetailed, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetClicksByPeriodAndSourceParams{
+		Period:      period,
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+	}
+	rows, err := r.queries.GetClicksByPeriodAndSource(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Str("period", period).Msg("Failed to get clicks by source")
+		return nil, fmt.Errorf("postgres: GetClicksByPeriodAndSource failed: %w", err)
+	}
+
+	stats := make([]model.AggregatedStatDetailed, len(rows))
+	for i, row := range rows {
+		stats[i] = model.AggregatedStatDetailed{
+			TimeKey:  row.TimeKey.Time,
+			GroupKey: row.SourceKey,
+			Value:    row.Value,
+		}
+	}
+	return stats, nil
+}
+
+// GetClicksTimeSeries fetches a dense, zero-filled click series within [from, to).
+func (r *AnalyticsRepository) GetClicksTimeSeries(ctx context.Context, workspaceID, urlID int64, period string, from, to time.Time) ([]model.TimeSeriesPoint, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetClicksTimeSeriesParams{
+		Period:      period,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+	}
+	rows, err := r.queries.GetClicksTimeSeries(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Str("period", period).Msg("Failed to get clicks time series")
+		return nil, fmt.Errorf("postgres: GetClicksTimeSeries failed: %w", err)
+	}
+
+	return toTimeSeriesPoints(rows), nil
+}
+
+// CountClicksInRange counts clicks for a URL in the half-open interval [from, to).
+func (r *AnalyticsRepository) CountClicksInRange(ctx context.Context, workspaceID, urlID int64, from, to time.Time) (int64, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.CountClicksInRangeParams{
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+	}
+	count, err := r.queries.CountClicksInRange(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Failed to count clicks in range")
+		return 0, fmt.Errorf("postgres: CountClicksInRange failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTopURLs fetches the URLs of a workspace with the most clicks within [from, to).
+func (r *AnalyticsRepository) GetTopURLs(ctx context.Context, workspaceID int64, from, to time.Time, sortBy string, limit int) ([]model.LinkStat, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetTopURLsParams{
+		WorkspaceID: workspaceID,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+		SortBy:      sortBy,
+		RowLimit:    int32(limit),
+	}
+	rows, err := r.queries.GetTopURLs(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Str("sort_by", sortBy).Msg("Failed to get top URLs")
+		return nil, fmt.Errorf("postgres: GetTopURLs failed: %w", err)
+	}
+
+	stats := make([]model.LinkStat, len(rows))
+	for i, row := range rows {
+		stats[i] = model.LinkStat{
+			URL: model.URL{
+				ID:          row.ID,
+				WorkspaceID: workspaceID,
+				OriginalURL: row.OriginalUrl,
+				ShortCode:   row.ShortCode.String,
+				CreatedAt:   row.CreatedAt.Time,
+				Domain:      row.Domain,
+			},
+			TotalClicks:  row.TotalClicks,
+			UniqueClicks: row.UniqueClicks,
+		}
+	}
+	return stats, nil
+}
+
+// GetGlobalClicksTimeSeries fetches a dense, zero-filled click series across all URLs of a workspace.
+func (r *AnalyticsRepository) GetGlobalClicksTimeSeries(ctx context.Context, workspaceID int64, period string, from, to time.Time) ([]model.TimeSeriesPoint, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetGlobalClicksTimeSeriesParams{
+		Period:      period,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+		WorkspaceID: workspaceID,
+	}
+	rows, err := r.queries.GetGlobalClicksTimeSeries(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Str("period", period).Msg("Failed to get global clicks time series")
+		return nil, fmt.Errorf("postgres: GetGlobalClicksTimeSeries failed: %w", err)
+	}
+
+	points := make([]model.TimeSeriesPoint, len(rows))
+	for i, row := range rows {
+		points[i] = model.TimeSeriesPoint{Bucket: row.Key.Time, Value: row.Value}
+	}
+	return points, nil
+}
+
+// GetGlobalClickTotals counts clicks and distinct visitors across all URLs of a workspace within [from, to).
+func (r *AnalyticsRepository) GetGlobalClickTotals(ctx context.Context, workspaceID int64, from, to time.Time) (model.ClickTotals, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetGlobalClickTotalsParams{
+		WorkspaceID: workspaceID,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+	}
+	row, err := r.queries.GetGlobalClickTotals(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get global click totals")
+		return model.ClickTotals{}, fmt.Errorf("postgres: GetGlobalClickTotals failed: %w", err)
+	}
+	return model.ClickTotals{TotalClicks: row.TotalClicks, UniqueVisitors: row.UniqueVisitors}, nil
+}
+
+// GetURLTotals counts URLs of a workspace created within [from, to) and all its URLs overall.
+func (r *AnalyticsRepository) GetURLTotals(ctx context.Context, workspaceID int64, from, to time.Time) (model.URLTotals, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetURLTotalsParams{
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+		WorkspaceID: workspaceID,
+	}
+	row, err := r.queries.GetURLTotals(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get URL totals")
+		return model.URLTotals{}, fmt.Errorf("postgres: GetURLTotals failed: %w", err)
+	}
+	return model.URLTotals{CreatedInRange: row.CreatedInRange, Total: row.Total}, nil
+}
+
+// GetTagClicksTimeSeries fetches a dense, zero-filled click series across the URLs carrying a tag.
+func (r *AnalyticsRepository) GetTagClicksTimeSeries(ctx context.Context, workspaceID, tagID int64, period string, from, to time.Time) ([]model.TimeSeriesPoint, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetTagClicksTimeSeriesParams{
+		Period:      period,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+		WorkspaceID: workspaceID,
+		TagID:       tagID,
+	}
+	rows, err := r.queries.GetTagClicksTimeSeries(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("tag_id", tagID).Str("period", period).Msg("Failed to get tag clicks time series")
+		return nil, fmt.Errorf("postgres: GetTagClicksTimeSeries failed: %w", err)
+	}
+
+	points := make([]model.TimeSeriesPoint, len(rows))
+	for i, row := range rows {
+		points[i] = model.TimeSeriesPoint{Bucket: row.Key.Time, Value: row.Value}
+	}
+	return points, nil
+}
+
+// GetTagClickTotals counts clicks and distinct visitors across the URLs carrying a tag within [from, to).
+func (r *AnalyticsRepository) GetTagClickTotals(ctx context.Context, workspaceID, tagID int64, from, to time.Time) (model.ClickTotals, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetTagClickTotalsParams{
+		WorkspaceID: workspaceID,
+		TagID:       tagID,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+	}
+	row, err := r.queries.GetTagClickTotals(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("tag_id", tagID).Msg("Failed to get tag click totals")
+		return model.ClickTotals{}, fmt.Errorf("postgres: GetTagClickTotals failed: %w", err)
+	}
+	return model.ClickTotals{TotalClicks: row.TotalClicks, UniqueVisitors: row.UniqueVisitors}, nil
+}
+
+// GetTopURLsByTag fetches the URLs carrying a tag with the most clicks within [from, to).
+func (r *AnalyticsRepository) GetTopURLsByTag(ctx context.Context, workspaceID, tagID int64, from, to time.Time, sortBy string, limit int) ([]model.LinkStat, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.GetTopURLsByTagParams{
+		WorkspaceID: workspaceID,
+		TagID:       tagID,
+		FromTime:    pgtype.Timestamptz{Time: from, Valid: true},
+		ToTime:      pgtype.Timestamptz{Time: to, Valid: true},
+		SortBy:      sortBy,
+		RowLimit:    int32(limit),
+	}
+	rows, err := r.queries.GetTopURLsByTag(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("tag_id", tagID).Str("sort_by", sortBy).Msg("Failed to get top URLs by tag")
+		return nil, fmt.Errorf("postgres: GetTopURLsByTag failed: %w", err)
+	}
+
+	stats := make([]model.LinkStat, len(rows))
+	for i, row := range rows {
+		stats[i] = model.LinkStat{
+			URL: model.URL{
+				ID:          row.ID,
+				WorkspaceID: workspaceID,
+				OriginalURL: row.OriginalUrl,
+				ShortCode:   row.ShortCode.String,
+				CreatedAt:   row.CreatedAt.Time,
+				Domain:      row.Domain,
+			},
+			TotalClicks:  row.TotalClicks,
+			UniqueClicks: row.UniqueClicks,
+		}
+	}
+	return stats, nil
+}
+
+// --- Mapper Functions ---
+
+func toDBListClicksParams(workspaceID, urlID int64, filter model.ClickFilter) db.ListClicksParams {
+	params := db.ListClicksParams{
+		WorkspaceID: workspaceID,
+		UrlID:       urlID,
+		PageSize:    int32(filter.Limit),
+	}
+
+	if !filter.From.IsZero() {
+		params.FromTime = pgtype.Timestamptz{Time: filter.From, Valid: true}
+	}
+	if !filter.To.IsZero() {
+		params.ToTime = pgtype.Timestamptz{Time: filter.To, Valid: true}
+	}
+	if filter.IsBot != nil {
+		params.IsBot = pgtype.Bool{Bool: *filter.IsBot, Valid: true}
+	}
+	if filter.UserAgent != "" {
+		params.UserAgent = pgtype.Text{String: escapeLike(filter.UserAgent), Valid: true}
+	}
+	if filter.After != nil {
+		params.CursorCreatedAt = pgtype.Timestamptz{Time: filter.After.CreatedAt, Valid: true}
+		params.CursorID = pgtype.Int8{Int64: filter.After.ID, Valid: true}
+	}
+
+	return params
+}
+
+func toTimeSeriesPoints(rows []db.GetClicksTimeSeriesRow) []model.TimeSeriesPoint {
+	points := make([]model.TimeSeriesPoint, len(rows))
+	for i, row := range rows {
+		points[i] = model.TimeSeriesPoint{
+			Bucket: row.Key.Time,
+			Value:  row.Value,
+		}
+	}
+	return points
+}
+
+func toAggregatedStatsFromTime(rows []db.GetClicksByPeriodRow) []model.AggregatedStat {
+	stats := make([]model.AggregatedStat, len(rows))
+	for i, row := range rows {
+		stats[i] = model.AggregatedStat{
+			Key:   row.Key.Time.Format("2006-01-02"),
+			Value: row.Value,
+		}
+	}
+	return stats
+}
+
+func toAggregatedStatsFromString(rows []db.GetClicksByUserAgentRow) []model.AggregatedStat {
+	stats := make([]model.AggregatedStat, len(rows))
+	for i, row := range rows {
+		stats[i] = model.AggregatedStat{
+			Key:   row.Key,
+			Value: row.Value,
+		}
+	}
+	return stats
+}
+
+func toAggregatedStatsDetailed(rows []db.GetClicksByPeriodAndUserAgentRow) []model.AggregatedStatDetailed {
+	stats := make([]model.AggregatedStatDetailed, len(rows))
+	for i, row := range rows {
+		stats[i] = model.AggregatedStatDetailed{
+			TimeKey:  row.TimeKey.Time,
+			GroupKey: row.UaKey,
+			Value:    row.Value,
+		}
+	}
+	return stats
+}
+
+func toDomainClicks(dbClicks []db.Click) []model.Click {
+	if len(dbClicks) == 0 {
+		return []model.Click{}
+	}
+
+	clicks := make([]model.Click, 0, len(dbClicks))
+	for _, dbClick := range dbClicks {
+		clicks = append(clicks, toDomainClick(dbClick))
+	}
+	return clicks
+}
+
+func toDomainClick(dbClick db.Click) model.Click {
+	click := model.Click{
+		ID:        dbClick.ID,
+		URLID:     dbClick.UrlID,
+		IsBot:     dbClick.IsBot,
+		CreatedAt: dbClick.CreatedAt.Time,
+	}
+
+	if dbClick.UserAgent.Valid {
+		click.UserAgent = dbClick.UserAgent.String
+	}
+
+	if dbClick.IpAddress != nil && dbClick.IpAddress.IsValid() {
+		click.IPAddress = dbClick.IpAddress.String()
+	}
+
+	if dbClick.Referrer.Valid {
+		click.Referrer = dbClick.Referrer.String
+	}
+
+	if dbClick.Country.Valid {
+		click.Country = dbClick.Country.String
+	}
+
+	if dbClick.Source.Valid {
+		click.Source = dbClick.Source.String
+	}
+
+	return click
+}
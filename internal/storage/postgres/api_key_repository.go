@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Ensures that APIKeyRepository correctly implements the repo.APIKeyRepository interface at compile time.
+var _ repo.APIKeyRepository = (*APIKeyRepository)(nil)
+
+// APIKeyRepository implements the domain.repository.APIKeyRepository interface
+// using PostgreSQL as a backend.
+type APIKeyRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	logger  zerolog.Logger
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository.
+func NewAPIKeyRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *APIKeyRepository {
+	return &APIKeyRepository{
+		pool:    pool,
+		queries: db.New(pool),
+		logger:  logger.With().Str("layer", "postgres_api_key_repository").Logger(),
+	}
+}
+
+// Create persists a new API key under the hash of its secret. An unknown workspace or user yields repo.ErrNotFound.
+func (r *APIKeyRepository) Create(ctx context.Context, key *model.APIKey, keyHash string) (*model.APIKey, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.CreateAPIKeyParams{
+		WorkspaceID: key.WorkspaceID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		KeyHash:     keyHash,
+		Scope:       key.Scope,
+	}
+	if key.UserID != nil {
+		params.UserID = pgtype.Int8{Int64: *key.UserID, Valid: true}
+	}
+
+	row, err := queriesFrom(ctx, r.queries).CreateAPIKey(ctx, params)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Str("name", key.Name).Msg("Failed to create API key")
+		return nil, fmt.Errorf("postgres: CreateAPIKey failed: %w", err)
+	}
+
+	return toDomainAPIKey(row), nil
+}
+
+// GetActiveByHash retrieves a key that has not been revoked by the hash of its secret,
+// with Role set to the current role of its user.
+func (r *APIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).GetActiveAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Msg("Failed to get API key by hash")
+		return nil, fmt.Errorf("postgres: GetActiveAPIKeyByHash failed: %w", err)
+	}
+
+	key := toDomainAPIKey(db.ApiKey{
+		ID:           row.ID,
+		Name:         row.Name,
+		Prefix:       row.Prefix,
+		KeyHash:      row.KeyHash,
+		Scope:        row.Scope,
+		RequestCount: row.RequestCount,
+		LastUsedAt:   row.LastUsedAt,
+		CreatedAt:    row.CreatedAt,
+		RevokedAt:    row.RevokedAt,
+		WorkspaceID:  row.WorkspaceID,
+		UserID:       row.UserID,
+	})
+	key.Role = row.MemberRole.String
+	return key, nil
+}
+
+// Get retrieves a key by its ID, including revoked ones.
+func (r *APIKeyRepository) Get(ctx context.Context, id int64) (*model.APIKey, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).GetAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Int64("api_key_id", id).Msg("Failed to get API key")
+		return nil, fmt.Errorf("postgres: GetAPIKey failed: %w", err)
+	}
+
+	return toDomainAPIKey(row), nil
+}
+
+// List retrieves API keys, including revoked ones: those of a workspace, or all of them
+// when workspaceID is nil.
+func (r *APIKeyRepository) List(ctx context.Context, workspaceID *int64) ([]model.APIKey, error) {
+	log := logger.FromContext(ctx, r.logger)
+	var ws pgtype.Int8
+	if workspaceID != nil {
+		ws = pgtype.Int8{Int64: *workspaceID, Valid: true}
+	}
+	rows, err := queriesFrom(ctx, r.queries).ListAPIKeys(ctx, ws)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list API keys")
+		return nil, fmt.Errorf("postgres: ListAPIKeys failed: %w", err)
+	}
+
+	keys := make([]model.APIKey, len(rows))
+	for i, row := range rows {
+		keys[i] = *toDomainAPIKey(row)
+	}
+	return keys, nil
+}
+
+// Revoke marks an API key as revoked. Unknown and already revoked keys yield repo.ErrNotFound.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	log := logger.FromContext(ctx, r.logger)
+	affected, err := queriesFrom(ctx, r.queries).RevokeAPIKey(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Int64("api_key_id", id).Msg("Failed to revoke API key")
+		return fmt.Errorf("postgres: RevokeAPIKey failed: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+// RecordUsage adds requests to the request count of a key and advances its last use.
+func (r *APIKeyRepository) RecordUsage(ctx context.Context, id int64, requests int64, lastUsedAt time.Time) error {
+	log := logger.FromContext(ctx, r.logger)
+	err := queriesFrom(ctx, r.queries).RecordAPIKeyUsage(ctx, db.RecordAPIKeyUsageParams{
+		Requests:   requests,
+		LastUsedAt: pgtype.Timestamptz{Time: lastUsedAt, Valid: true},
+		ID:         id,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("api_key_id", id).Msg("Failed to record API key usage")
+		return fmt.Errorf("postgres: RecordAPIKeyUsage failed: %w", err)
+	}
+
+	return nil
+}
+
+func toDomainAPIKey(row db.ApiKey) *model.APIKey {
+	key := &model.APIKey{
+		ID:           row.ID,
+		WorkspaceID:  row.WorkspaceID,
+		Name:         row.Name,
+		Prefix:       row.Prefix,
+		Scope:        row.Scope,
+		RequestCount: row.RequestCount,
+		CreatedAt:    row.CreatedAt.Time,
+	}
+	if row.UserID.Valid {
+		key.UserID = &row.UserID.Int64
+	}
+	if row.LastUsedAt.Valid {
+		key.LastUsedAt = &row.LastUsedAt.Time
+	}
+	if row.RevokedAt.Valid {
+		key.RevokedAt = &row.RevokedAt.Time
+	}
+	return key
+}
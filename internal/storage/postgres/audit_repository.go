@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Ensures that AuditRepository correctly implements the repo.AuditRepository interface at compile time.
+var _ repo.AuditRepository = (*AuditRepository)(nil)
+
+// AuditRepository implements the domain.repository.AuditRepository interface
+// using PostgreSQL as a backend.
+type AuditRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	logger  zerolog.Logger
+}
+
+// NewAuditRepository creates a new instance of AuditRepository.
+func NewAuditRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *AuditRepository {
+	return &AuditRepository{
+		pool:    pool,
+		queries: db.New(pool),
+		logger:  logger.With().Str("layer", "postgres_audit_repository").Logger(),
+	}
+}
+
+// Create appends an event to the audit trail, inside the transaction carried by ctx if any.
+func (r *AuditRepository) Create(ctx context.Context, event *model.AuditEvent) error {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.CreateAuditEventParams{
+		WorkspaceID: event.WorkspaceID,
+		Actor:       event.Actor,
+		Action:      event.Action,
+		BeforeValue: event.Before,
+		AfterValue:  event.After,
+		SourceIp:    event.SourceIP,
+		RequestID:   event.RequestID,
+	}
+	if event.ActorUserID != nil {
+		params.ActorUserID = pgtype.Int8{Int64: *event.ActorUserID, Valid: true}
+	}
+	if event.ActorAPIKeyID != nil {
+		params.ActorApiKeyID = pgtype.Int8{Int64: *event.ActorAPIKeyID, Valid: true}
+	}
+	if event.ShortCode != "" {
+		params.ShortCode = pgtype.Text{String: event.ShortCode, Valid: true}
+	}
+
+	if err := queriesFrom(ctx, r.queries).CreateAuditEvent(ctx, params); err != nil {
+		log.Error().Err(err).Str("action", event.Action).Msg("Failed to write audit event")
+		return fmt.Errorf("postgres: CreateAuditEvent failed: %w", err)
+	}
+
+	return nil
+}
+
+// List retrieves the audit trail of a workspace, newest first.
+func (r *AuditRepository) List(ctx context.Context, workspaceID int64, filter model.AuditFilter) ([]model.AuditEvent, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.ListAuditEventsParams{
+		WorkspaceID: workspaceID,
+		PageSize:    int32(filter.Limit),
+	}
+	if filter.Action != "" {
+		params.Action = pgtype.Text{String: filter.Action, Valid: true}
+	}
+	if filter.ShortCode != "" {
+		params.ShortCode = pgtype.Text{String: filter.ShortCode, Valid: true}
+	}
+	if filter.Actor != "" {
+		params.Actor = pgtype.Text{String: filter.Actor, Valid: true}
+	}
+	if !filter.From.IsZero() {
+		params.FromTime = pgtype.Timestamptz{Time: filter.From, Valid: true}
+	}
+	if !filter.To.IsZero() {
+		params.ToTime = pgtype.Timestamptz{Time: filter.To, Valid: true}
+	}
+	if filter.BeforeID > 0 {
+		params.BeforeID = pgtype.Int8{Int64: filter.BeforeID, Valid: true}
+	}
+
+	rows, err := r.queries.ListAuditEvents(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Msg("Failed to list audit events")
+		return nil, fmt.Errorf("postgres: ListAuditEvents failed: %w", err)
+	}
+
+	events := make([]model.AuditEvent, len(rows))
+	for i, row := range rows {
+		events[i] = toDomainAuditEvent(row)
+	}
+	return events, nil
+}
+
+func toDomainAuditEvent(row db.AuditEvent) model.AuditEvent {
+	event := model.AuditEvent{
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Actor:       row.Actor,
+		Action:      row.Action,
+		ShortCode:   row.ShortCode.String,
+		Before:      row.BeforeValue,
+		After:       row.AfterValue,
+		SourceIP:    row.SourceIp,
+		RequestID:   row.RequestID,
+		CreatedAt:   row.CreatedAt.Time,
+	}
+	if row.ActorUserID.Valid {
+		event.ActorUserID = &row.ActorUserID.Int64
+	}
+	if row.ActorApiKeyID.Valid {
+		event.ActorAPIKeyID = &row.ActorApiKeyID.Int64
+	}
+	return event
+}
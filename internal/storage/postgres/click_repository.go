@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/ilindan-dev/shortener/internal/domain/model"
 	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
 	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -32,33 +33,47 @@ func NewClickRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *ClickReposi
 	}
 }
 
-// Create persists a new click event in the database.
-func (r *ClickRepository) Create(ctx context.Context, click *model.Click) error {
+// Create persists a new click event in the database and bumps the URL's click counter.
+func (r *ClickRepository) Create(ctx context.Context, click *model.Click) (int64, error) {
+	log := logger.FromContext(ctx, r.logger)
 	params, err := toDBCreateClickParams(click)
 	if err != nil {
-		r.logger.Error().Err(err).Msg("Failed to map domain click to db params")
-		return err
+		log.Error().Err(err).Msg("Failed to map domain click to db params")
+		return 0, err
 	}
 
-	err = r.queries.CreateClick(ctx, params)
+	clickCount, err := r.queries.CreateClick(ctx, params)
 	if err != nil {
-		r.logger.Error().Err(err).Int64("url_id", click.URLID).Msg("Failed to create click")
-		return fmt.Errorf("postgres: CreateClick failed: %w", err)
+		log.Error().Err(err).Int64("url_id", click.URLID).Msg("Failed to create click")
+		return 0, fmt.Errorf("postgres: CreateClick failed: %w", err)
 	}
 
-	return nil
+	return clickCount, nil
 }
 
 // toDBCreateClickParams converts a domain model.Click to the sqlc-generated parameters for creation.
 func toDBCreateClickParams(click *model.Click) (db.CreateClickParams, error) {
 	params := db.CreateClickParams{
 		UrlID: click.URLID,
+		IsBot: click.IsBot,
 	}
 
 	if click.UserAgent != "" {
 		params.UserAgent = pgtype.Text{String: click.UserAgent, Valid: true}
 	}
 
+	if click.Referrer != "" {
+		params.Referrer = pgtype.Text{String: click.Referrer, Valid: true}
+	}
+
+	if click.Country != "" {
+		params.Country = pgtype.Text{String: click.Country, Valid: true}
+	}
+
+	if click.Source != "" {
+		params.Source = pgtype.Text{String: click.Source, Valid: true}
+	}
+
 	if click.IPAddress != "" {
 		addr, err := netip.ParseAddr(click.IPAddress)
 		if err != nil {
@@ -3,14 +3,17 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"github.com/exaring/otelpgx"
 	"github.com/ilindan-dev/shortener/internal/config"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
 // NewPool creates and returns a new connection pool for PostgreSQL using pgx.
+// Queries are traced through the pgx tracer hook.
 // It also registers lifecycle hooks with Fx to handle startup and shutdown.
-func NewPool(lc fx.Lifecycle, cfg *config.Config) (*pgxpool.Pool, error) {
+func NewPool(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider) (*pgxpool.Pool, error) {
 	poolConfig, err := pgxpool.ParseConfig(cfg.Postgres.MasterDSN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse postgres config: %w", err)
@@ -19,6 +22,7 @@ func NewPool(lc fx.Lifecycle, cfg *config.Config) (*pgxpool.Pool, error) {
 	poolConfig.MaxConns = int32(cfg.Postgres.Pool.MaxOpenConns)
 	poolConfig.MinConns = int32(cfg.Postgres.Pool.MaxIdleConns)
 	poolConfig.MaxConnLifetime = cfg.Postgres.Pool.ConnMaxLifetime
+	poolConfig.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTracerProvider(tp))
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
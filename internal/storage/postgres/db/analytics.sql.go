@@ -11,19 +11,50 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countClicksInRange = `-- name: CountClicksInRange :one
+SELECT count(*)
+FROM clicks
+WHERE workspace_id = $1
+  AND url_id = $2
+  AND created_at >= $3
+  AND created_at < $4
+`
+
+type CountClicksInRangeParams struct {
+	WorkspaceID int64              `json:"workspace_id"`
+	UrlID       int64              `json:"url_id"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+}
+
+// Counts clicks for a given URL ID within the half-open interval [from_time, to_time).
+func (q *Queries) CountClicksInRange(ctx context.Context, arg CountClicksInRangeParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countClicksInRange,
+		arg.WorkspaceID,
+		arg.UrlID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getClicksByPeriod = `-- name: GetClicksByPeriod :many
 SELECT
     date_trunc($1::text, created_at)::date AS key,
     count(*) AS value
 FROM clicks
-WHERE url_id = $2
+WHERE workspace_id = $2
+  AND url_id = $3
 GROUP BY key
 ORDER BY key DESC
 `
 
 type GetClicksByPeriodParams struct {
-	Period string `json:"period"`
-	UrlID  int64  `json:"url_id"`
+	Period      string `json:"period"`
+	WorkspaceID int64  `json:"workspace_id"`
+	UrlID       int64  `json:"url_id"`
 }
 
 type GetClicksByPeriodRow struct {
@@ -33,7 +64,7 @@ type GetClicksByPeriodRow struct {
 
 // Aggregates click counts for a given URL ID over a specified time period (e.g., 'day', 'month').
 func (q *Queries) GetClicksByPeriod(ctx context.Context, arg GetClicksByPeriodParams) ([]GetClicksByPeriodRow, error) {
-	rows, err := q.db.Query(ctx, getClicksByPeriod, arg.Period, arg.UrlID)
+	rows, err := q.db.Query(ctx, getClicksByPeriod, arg.Period, arg.WorkspaceID, arg.UrlID)
 	if err != nil {
 		return nil, err
 	}
@@ -52,31 +83,99 @@ func (q *Queries) GetClicksByPeriod(ctx context.Context, arg GetClicksByPeriodPa
 	return items, nil
 }
 
+const getClicksByPeriodAndSource = `-- name: GetClicksByPeriodAndSource :many
+SELECT
+    date_trunc($1::text, created_at, 'UTC') AS time_key,
+    COALESCE(source, 'link') AS source_key,
+    count(*) as value
+FROM clicks
+WHERE workspace_id = $2
+  AND url_id = $3
+  AND created_at >= $4
+  AND created_at < $5
+GROUP BY time_key, source_key
+ORDER BY time_key DESC, value DESC
+`
+
+type GetClicksByPeriodAndSourceParams struct {
+	Period      string             `json:"period"`
+	WorkspaceID int64              `json:"workspace_id"`
+	UrlID       int64              `json:"url_id"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+}
+
+type GetClicksByPeriodAndSourceRow struct {
+	TimeKey   pgtype.Timestamptz `json:"time_key"`
+	SourceKey string             `json:"source_key"`
+	Value     int64              `json:"value"`
+}
+
+// Aggregates click counts grouped by both a time period aligned in UTC AND click source within [from_time, to_time).
+// Clicks without a source marker are counted as 'link'.
+func (q *Queries) GetClicksByPeriodAndSource(ctx context.Context, arg GetClicksByPeriodAndSourceParams) ([]GetClicksByPeriodAndSourceRow, error) {
+	rows, err := q.db.Query(ctx, getClicksByPeriodAndSource,
+		arg.Period,
+		arg.WorkspaceID,
+		arg.UrlID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClicksByPeriodAndSourceRow
+	for rows.Next() {
+		var i GetClicksByPeriodAndSourceRow
+		if err := rows.Scan(&i.TimeKey, &i.SourceKey, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getClicksByPeriodAndUserAgent = `-- name: GetClicksByPeriodAndUserAgent :many
 SELECT
-    date_trunc($1::text, created_at)::date AS time_key,
+    date_trunc($1::text, created_at, 'UTC') AS time_key,
     COALESCE(user_agent, 'Unknown') AS ua_key,
     count(*) as value
 FROM clicks
-WHERE url_id = $2
+WHERE workspace_id = $2
+  AND url_id = $3
+  AND created_at >= $4
+  AND created_at < $5
 GROUP BY time_key, ua_key
 ORDER BY time_key DESC, value DESC
 `
 
 type GetClicksByPeriodAndUserAgentParams struct {
-	Period string `json:"period"`
-	UrlID  int64  `json:"url_id"`
+	Period      string             `json:"period"`
+	WorkspaceID int64              `json:"workspace_id"`
+	UrlID       int64              `json:"url_id"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
 }
 
 type GetClicksByPeriodAndUserAgentRow struct {
-	TimeKey pgtype.Date `json:"time_key"`
-	UaKey   string      `json:"ua_key"`
-	Value   int64       `json:"value"`
+	TimeKey pgtype.Timestamptz `json:"time_key"`
+	UaKey   string             `json:"ua_key"`
+	Value   int64              `json:"value"`
 }
 
-// Aggregates click counts grouped by both a time period AND User-Agent.
+// Aggregates click counts grouped by both a time period aligned in UTC AND User-Agent within [from_time, to_time).
 func (q *Queries) GetClicksByPeriodAndUserAgent(ctx context.Context, arg GetClicksByPeriodAndUserAgentParams) ([]GetClicksByPeriodAndUserAgentRow, error) {
-	rows, err := q.db.Query(ctx, getClicksByPeriodAndUserAgent, arg.Period, arg.UrlID)
+	rows, err := q.db.Query(ctx, getClicksByPeriodAndUserAgent,
+		arg.Period,
+		arg.WorkspaceID,
+		arg.UrlID,
+		arg.FromTime,
+		arg.ToTime,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -100,19 +199,25 @@ SELECT
     COALESCE(user_agent, 'Unknown') AS key,
     count(*) as value
 FROM clicks
-WHERE url_id = $1
+WHERE workspace_id = $1
+  AND url_id = $2
 GROUP BY key
 ORDER BY value DESC
 `
 
+type GetClicksByUserAgentParams struct {
+	WorkspaceID int64 `json:"workspace_id"`
+	UrlID       int64 `json:"url_id"`
+}
+
 type GetClicksByUserAgentRow struct {
 	Key   string `json:"key"`
 	Value int64  `json:"value"`
 }
 
 // Aggregates click counts for a given URL ID, grouped by User-Agent.
-func (q *Queries) GetClicksByUserAgent(ctx context.Context, urlID int64) ([]GetClicksByUserAgentRow, error) {
-	rows, err := q.db.Query(ctx, getClicksByUserAgent, urlID)
+func (q *Queries) GetClicksByUserAgent(ctx context.Context, arg GetClicksByUserAgentParams) ([]GetClicksByUserAgentRow, error) {
+	rows, err := q.db.Query(ctx, getClicksByUserAgent, arg.WorkspaceID, arg.UrlID)
 	if err != nil {
 		return nil, err
 	}
@@ -130,3 +235,434 @@ func (q *Queries) GetClicksByUserAgent(ctx context.Context, urlID int64) ([]GetC
 	}
 	return items, nil
 }
+
+const getClicksTimeSeries = `-- name: GetClicksTimeSeries :many
+WITH buckets AS (
+    SELECT bucket
+    FROM generate_series(
+        date_trunc($1::text, $2::timestamptz, 'UTC') AT TIME ZONE 'UTC',
+        ($3::timestamptz AT TIME ZONE 'UTC') - interval '1 microsecond',
+        ('1 ' || $1::text)::interval
+    ) AS bucket
+)
+SELECT
+    (b.bucket AT TIME ZONE 'UTC')::timestamptz AS key,
+    count(c.id) AS value
+FROM buckets b
+LEFT JOIN clicks c
+    ON c.workspace_id = $4
+    AND c.url_id = $5
+    AND c.created_at >= GREATEST(b.bucket AT TIME ZONE 'UTC', $2)
+    AND c.created_at < LEAST((b.bucket + ('1 ' || $1::text)::interval) AT TIME ZONE 'UTC', $3)
+GROUP BY b.bucket
+ORDER BY b.bucket
+`
+
+type GetClicksTimeSeriesParams struct {
+	Period      string             `json:"period"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+	WorkspaceID int64              `json:"workspace_id"`
+	UrlID       int64              `json:"url_id"`
+}
+
+type GetClicksTimeSeriesRow struct {
+	Key   pgtype.Timestamptz `json:"key"`
+	Value int64              `json:"value"`
+}
+
+// Returns a dense, zero-filled click series for a URL ID within [from_time, to_time),
+// with one bucket per period (e.g., 'hour', 'day', 'week', 'month') aligned in UTC.
+// The first and last buckets only count the clicks inside the range.
+func (q *Queries) GetClicksTimeSeries(ctx context.Context, arg GetClicksTimeSeriesParams) ([]GetClicksTimeSeriesRow, error) {
+	rows, err := q.db.Query(ctx, getClicksTimeSeries,
+		arg.Period,
+		arg.FromTime,
+		arg.ToTime,
+		arg.WorkspaceID,
+		arg.UrlID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClicksTimeSeriesRow
+	for rows.Next() {
+		var i GetClicksTimeSeriesRow
+		if err := rows.Scan(&i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGlobalClickTotals = `-- name: GetGlobalClickTotals :one
+SELECT
+    count(*) AS total_clicks,
+    count(DISTINCT ip_address) AS unique_visitors
+FROM clicks
+WHERE workspace_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+`
+
+type GetGlobalClickTotalsParams struct {
+	WorkspaceID int64              `json:"workspace_id"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+}
+
+type GetGlobalClickTotalsRow struct {
+	TotalClicks    int64 `json:"total_clicks"`
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+// Counts all clicks and distinct visitor IPs across all URLs of a workspace within [from_time, to_time).
+func (q *Queries) GetGlobalClickTotals(ctx context.Context, arg GetGlobalClickTotalsParams) (GetGlobalClickTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getGlobalClickTotals, arg.WorkspaceID, arg.FromTime, arg.ToTime)
+	var i GetGlobalClickTotalsRow
+	err := row.Scan(&i.TotalClicks, &i.UniqueVisitors)
+	return i, err
+}
+
+const getGlobalClicksTimeSeries = `-- name: GetGlobalClicksTimeSeries :many
+WITH buckets AS (
+    SELECT bucket
+    FROM generate_series(
+        date_trunc($1::text, $2::timestamptz, 'UTC') AT TIME ZONE 'UTC',
+        ($3::timestamptz AT TIME ZONE 'UTC') - interval '1 microsecond',
+        ('1 ' || $1::text)::interval
+    ) AS bucket
+)
+SELECT
+    (b.bucket AT TIME ZONE 'UTC')::timestamptz AS key,
+    count(c.id) AS value
+FROM buckets b
+LEFT JOIN clicks c
+    ON c.workspace_id = $4
+    AND c.created_at >= GREATEST(b.bucket AT TIME ZONE 'UTC', $2)
+    AND c.created_at < LEAST((b.bucket + ('1 ' || $1::text)::interval) AT TIME ZONE 'UTC', $3)
+GROUP BY b.bucket
+ORDER BY b.bucket
+`
+
+type GetGlobalClicksTimeSeriesParams struct {
+	Period      string             `json:"period"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+	WorkspaceID int64              `json:"workspace_id"`
+}
+
+type GetGlobalClicksTimeSeriesRow struct {
+	Key   pgtype.Timestamptz `json:"key"`
+	Value int64              `json:"value"`
+}
+
+// Returns a dense, zero-filled click series across all URLs of a workspace within [from_time, to_time),
+// bucketed like GetClicksTimeSeries.
+func (q *Queries) GetGlobalClicksTimeSeries(ctx context.Context, arg GetGlobalClicksTimeSeriesParams) ([]GetGlobalClicksTimeSeriesRow, error) {
+	rows, err := q.db.Query(ctx, getGlobalClicksTimeSeries,
+		arg.Period,
+		arg.FromTime,
+		arg.ToTime,
+		arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGlobalClicksTimeSeriesRow
+	for rows.Next() {
+		var i GetGlobalClicksTimeSeriesRow
+		if err := rows.Scan(&i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTagClickTotals = `-- name: GetTagClickTotals :one
+SELECT
+    count(*) AS total_clicks,
+    count(DISTINCT ip_address) AS unique_visitors
+FROM clicks
+WHERE workspace_id = $1
+  AND url_id IN (SELECT url_id FROM url_tags WHERE tag_id = $2)
+  AND created_at >= $3
+  AND created_at < $4
+`
+
+type GetTagClickTotalsParams struct {
+	WorkspaceID int64              `json:"workspace_id"`
+	TagID       int64              `json:"tag_id"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+}
+
+type GetTagClickTotalsRow struct {
+	TotalClicks    int64 `json:"total_clicks"`
+	UniqueVisitors int64 `json:"unique_visitors"`
+}
+
+// Counts clicks and distinct visitor IPs across the URLs carrying a tag within [from_time, to_time).
+func (q *Queries) GetTagClickTotals(ctx context.Context, arg GetTagClickTotalsParams) (GetTagClickTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getTagClickTotals,
+		arg.WorkspaceID,
+		arg.TagID,
+		arg.FromTime,
+		arg.ToTime,
+	)
+	var i GetTagClickTotalsRow
+	err := row.Scan(&i.TotalClicks, &i.UniqueVisitors)
+	return i, err
+}
+
+const getTagClicksTimeSeries = `-- name: GetTagClicksTimeSeries :many
+WITH buckets AS (
+    SELECT bucket
+    FROM generate_series(
+        date_trunc($1::text, $2::timestamptz, 'UTC') AT TIME ZONE 'UTC',
+        ($3::timestamptz AT TIME ZONE 'UTC') - interval '1 microsecond',
+        ('1 ' || $1::text)::interval
+    ) AS bucket
+)
+SELECT
+    (b.bucket AT TIME ZONE 'UTC')::timestamptz AS key,
+    count(c.id) AS value
+FROM buckets b
+LEFT JOIN clicks c
+    ON c.workspace_id = $4
+    AND c.url_id IN (SELECT url_id FROM url_tags WHERE tag_id = $5)
+    AND c.created_at >= GREATEST(b.bucket AT TIME ZONE 'UTC', $2)
+    AND c.created_at < LEAST((b.bucket + ('1 ' || $1::text)::interval) AT TIME ZONE 'UTC', $3)
+GROUP BY b.bucket
+ORDER BY b.bucket
+`
+
+type GetTagClicksTimeSeriesParams struct {
+	Period      string             `json:"period"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+	WorkspaceID int64              `json:"workspace_id"`
+	TagID       int64              `json:"tag_id"`
+}
+
+type GetTagClicksTimeSeriesRow struct {
+	Key   pgtype.Timestamptz `json:"key"`
+	Value int64              `json:"value"`
+}
+
+// Returns a dense, zero-filled click series across the URLs carrying a tag within [from_time, to_time),
+// bucketed like GetClicksTimeSeries.
+func (q *Queries) GetTagClicksTimeSeries(ctx context.Context, arg GetTagClicksTimeSeriesParams) ([]GetTagClicksTimeSeriesRow, error) {
+	rows, err := q.db.Query(ctx, getTagClicksTimeSeries,
+		arg.Period,
+		arg.FromTime,
+		arg.ToTime,
+		arg.WorkspaceID,
+		arg.TagID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTagClicksTimeSeriesRow
+	for rows.Next() {
+		var i GetTagClicksTimeSeriesRow
+		if err := rows.Scan(&i.Key, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTopURLs = `-- name: GetTopURLs :many
+SELECT
+    u.id,
+    u.original_url,
+    u.short_code,
+    u.created_at,
+    u.domain,
+    count(c.id) AS total_clicks,
+    count(DISTINCT c.ip_address) AS unique_clicks
+FROM clicks c
+JOIN urls u ON u.id = c.url_id
+WHERE c.workspace_id = $1
+  AND c.created_at >= $2
+  AND c.created_at < $3
+GROUP BY u.id
+ORDER BY
+    CASE WHEN $4::text = 'unique' THEN count(DISTINCT c.ip_address) ELSE count(c.id) END DESC,
+    u.id
+LIMIT $5
+`
+
+type GetTopURLsParams struct {
+	WorkspaceID int64              `json:"workspace_id"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+	SortBy      string             `json:"sort_by"`
+	RowLimit    int32              `json:"row_limit"`
+}
+
+type GetTopURLsRow struct {
+	ID           int64              `json:"id"`
+	OriginalUrl  string             `json:"original_url"`
+	ShortCode    pgtype.Text        `json:"short_code"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	Domain       string             `json:"domain"`
+	TotalClicks  int64              `json:"total_clicks"`
+	UniqueClicks int64              `json:"unique_clicks"`
+}
+
+// Ranks the URLs of a workspace by total or unique (distinct IP) clicks within [from_time, to_time).
+func (q *Queries) GetTopURLs(ctx context.Context, arg GetTopURLsParams) ([]GetTopURLsRow, error) {
+	rows, err := q.db.Query(ctx, getTopURLs,
+		arg.WorkspaceID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.SortBy,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTopURLsRow
+	for rows.Next() {
+		var i GetTopURLsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortCode,
+			&i.CreatedAt,
+			&i.Domain,
+			&i.TotalClicks,
+			&i.UniqueClicks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTopURLsByTag = `-- name: GetTopURLsByTag :many
+SELECT
+    u.id,
+    u.original_url,
+    u.short_code,
+    u.created_at,
+    u.domain,
+    count(c.id) AS total_clicks,
+    count(DISTINCT c.ip_address) AS unique_clicks
+FROM clicks c
+JOIN urls u ON u.id = c.url_id
+JOIN url_tags ut ON ut.url_id = u.id
+WHERE c.workspace_id = $1
+  AND ut.tag_id = $2
+  AND c.created_at >= $3
+  AND c.created_at < $4
+GROUP BY u.id
+ORDER BY
+    CASE WHEN $5::text = 'unique' THEN count(DISTINCT c.ip_address) ELSE count(c.id) END DESC,
+    u.id
+LIMIT $6
+`
+
+type GetTopURLsByTagParams struct {
+	WorkspaceID int64              `json:"workspace_id"`
+	TagID       int64              `json:"tag_id"`
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+	SortBy      string             `json:"sort_by"`
+	RowLimit    int32              `json:"row_limit"`
+}
+
+type GetTopURLsByTagRow struct {
+	ID           int64              `json:"id"`
+	OriginalUrl  string             `json:"original_url"`
+	ShortCode    pgtype.Text        `json:"short_code"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	Domain       string             `json:"domain"`
+	TotalClicks  int64              `json:"total_clicks"`
+	UniqueClicks int64              `json:"unique_clicks"`
+}
+
+// Ranks the URLs carrying a tag by total or unique (distinct IP) clicks within [from_time, to_time).
+func (q *Queries) GetTopURLsByTag(ctx context.Context, arg GetTopURLsByTagParams) ([]GetTopURLsByTagRow, error) {
+	rows, err := q.db.Query(ctx, getTopURLsByTag,
+		arg.WorkspaceID,
+		arg.TagID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.SortBy,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTopURLsByTagRow
+	for rows.Next() {
+		var i GetTopURLsByTagRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortCode,
+			&i.CreatedAt,
+			&i.Domain,
+			&i.TotalClicks,
+			&i.UniqueClicks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getURLTotals = `-- name: GetURLTotals :one
+SELECT
+    count(*) FILTER (WHERE created_at >= $1 AND created_at < $2) AS created_in_range,
+    count(*) AS total
+FROM urls
+WHERE workspace_id = $3
+`
+
+type GetURLTotalsParams struct {
+	FromTime    pgtype.Timestamptz `json:"from_time"`
+	ToTime      pgtype.Timestamptz `json:"to_time"`
+	WorkspaceID int64              `json:"workspace_id"`
+}
+
+type GetURLTotalsRow struct {
+	CreatedInRange int64 `json:"created_in_range"`
+	Total          int64 `json:"total"`
+}
+
+// Counts URLs of a workspace created within [from_time, to_time) alongside its overall number of URLs.
+func (q *Queries) GetURLTotals(ctx context.Context, arg GetURLTotalsParams) (GetURLTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getURLTotals, arg.FromTime, arg.ToTime, arg.WorkspaceID)
+	var i GetURLTotalsRow
+	err := row.Scan(&i.CreatedInRange, &i.Total)
+	return i, err
+}
@@ -10,17 +10,137 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID           int64              `json:"id"`
+	Name         string             `json:"name"`
+	Prefix       string             `json:"prefix"`
+	KeyHash      string             `json:"key_hash"`
+	Scope        string             `json:"scope"`
+	RequestCount int64              `json:"request_count"`
+	LastUsedAt   pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	RevokedAt    pgtype.Timestamptz `json:"revoked_at"`
+	WorkspaceID  int64              `json:"workspace_id"`
+	UserID       pgtype.Int8        `json:"user_id"`
+}
+
+type AuditEvent struct {
+	ID            int64              `json:"id"`
+	WorkspaceID   int64              `json:"workspace_id"`
+	Actor         string             `json:"actor"`
+	ActorUserID   pgtype.Int8        `json:"actor_user_id"`
+	ActorApiKeyID pgtype.Int8        `json:"actor_api_key_id"`
+	Action        string             `json:"action"`
+	ShortCode     pgtype.Text        `json:"short_code"`
+	BeforeValue   []byte             `json:"before_value"`
+	AfterValue    []byte             `json:"after_value"`
+	SourceIp      string             `json:"source_ip"`
+	RequestID     string             `json:"request_id"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
 type Click struct {
+	ID          int64              `json:"id"`
+	UrlID       int64              `json:"url_id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UserAgent   pgtype.Text        `json:"user_agent"`
+	IpAddress   *netip.Addr        `json:"ip_address"`
+	IsBot       bool               `json:"is_bot"`
+	Referrer    pgtype.Text        `json:"referrer"`
+	Country     pgtype.Text        `json:"country"`
+	WorkspaceID int64              `json:"workspace_id"`
+	Source      pgtype.Text        `json:"source"`
+}
+
+type Folder struct {
+	ID          int64              `json:"id"`
+	WorkspaceID int64              `json:"workspace_id"`
+	Name        string             `json:"name"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type Tag struct {
+	ID          int64              `json:"id"`
+	WorkspaceID int64              `json:"workspace_id"`
+	Name        string             `json:"name"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type Url struct {
+	ID             int64              `json:"id"`
+	OriginalUrl    string             `json:"original_url"`
+	ShortCode      pgtype.Text        `json:"short_code"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	ClickCount     int64              `json:"click_count"`
+	WorkspaceID    int64              `json:"workspace_id"`
+	FolderID       pgtype.Int8        `json:"folder_id"`
+	Title          string             `json:"title"`
+	Description    string             `json:"description"`
+	Metadata       []byte             `json:"metadata"`
+	CreatedBy      string             `json:"created_by"`
+	UrlHash        []byte             `json:"url_hash"`
+	RedirectStatus pgtype.Int2        `json:"redirect_status"`
+	Suspicious     bool               `json:"suspicious"`
+	Domain         string             `json:"domain"`
+	Canonical      bool               `json:"canonical"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+	ExpiryNotified bool               `json:"expiry_notified"`
+}
+
+type UrlTag struct {
+	UrlID int64 `json:"url_id"`
+	TagID int64 `json:"tag_id"`
+}
+
+type User struct {
 	ID        int64              `json:"id"`
-	UrlID     int64              `json:"url_id"`
+	Email     string             `json:"email"`
+	Name      string             `json:"name"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UserAgent pgtype.Text        `json:"user_agent"`
-	IpAddress *netip.Addr        `json:"ip_address"`
 }
 
-type Url struct {
+type WebhookDelivery struct {
+	ID             int64              `json:"id"`
+	SubscriptionID int64              `json:"subscription_id"`
+	EventType      string             `json:"event_type"`
+	Payload        []byte             `json:"payload"`
+	Status         string             `json:"status"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+	LastStatusCode pgtype.Int4        `json:"last_status_code"`
+	LastError      pgtype.Text        `json:"last_error"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+}
+
+type WebhookSubscription struct {
+	ID             int64              `json:"id"`
+	Url            string             `json:"url"`
+	Secret         string             `json:"secret"`
+	Events         []string           `json:"events"`
+	ClickThreshold pgtype.Int8        `json:"click_threshold"`
+	Active         bool               `json:"active"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	WorkspaceID    int64              `json:"workspace_id"`
+}
+
+type Workspace struct {
+	ID                 int64              `json:"id"`
+	Name               string             `json:"name"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	ReuseExistingLinks bool               `json:"reuse_existing_links"`
+}
+
+type WorkspaceDomain struct {
 	ID          int64              `json:"id"`
-	OriginalUrl string             `json:"original_url"`
-	ShortCode   pgtype.Text        `json:"short_code"`
+	WorkspaceID int64              `json:"workspace_id"`
+	Host        string             `json:"host"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID int64              `json:"workspace_id"`
+	UserID      int64              `json:"user_id"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	Role        string             `json:"role"`
 }
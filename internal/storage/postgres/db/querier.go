@@ -11,22 +11,186 @@ import (
 )
 
 type Querier interface {
-	// Inserts a new click record for analytics.
-	CreateClick(ctx context.Context, arg CreateClickParams) error
-	// Inserts a new URL record with the original URL.
-	CreateURL(ctx context.Context, originalUrl string) (Url, error)
+	// Attaches the named tags of a workspace to a URL.
+	AddURLTags(ctx context.Context, arg AddURLTagsParams) error
+	// Registers a host on which a workspace serves its links; a host belongs to one workspace.
+	AddWorkspaceDomain(ctx context.Context, arg AddWorkspaceDomainParams) (WorkspaceDomain, error)
+	// Adds a user to a workspace with a role.
+	AddWorkspaceMember(ctx context.Context, arg AddWorkspaceMemberParams) error
+	// Leases a batch of due deliveries to the caller. Concurrent dispatchers skip each other's rows,
+	// and a lease that is never resolved (e.g. the process crashed) becomes due again once it expires.
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]ClaimDueWebhookDeliveriesRow, error)
+	// Marks up to batch_size URLs of any workspace whose expiry has passed and was not announced yet
+	// as announced, and returns them. URLs claimed by a concurrent sweep are skipped.
+	ClaimExpiredURLs(ctx context.Context, batchSize int32) ([]Url, error)
+	// Counts all click records for a given URL.
+	CountClicksByURLID(ctx context.Context, arg CountClicksByURLIDParams) (int64, error)
+	// Counts clicks for a given URL ID within the half-open interval [from_time, to_time).
+	CountClicksInRange(ctx context.Context, arg CountClicksInRangeParams) (int64, error)
+	// Stores a new API key. Only the SHA-256 hash of the key is persisted.
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	// Appends an event to the audit trail.
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	// Inserts a new click record for analytics and returns the URL's updated click count.
+	// The click inherits the workspace of its URL.
+	CreateClick(ctx context.Context, arg CreateClickParams) (int64, error)
+	// Creates a folder in a workspace; folder names are unique per workspace.
+	CreateFolder(ctx context.Context, arg CreateFolderParams) (Folder, error)
+	// Creates the named tags of a workspace that do not exist yet.
+	CreateTags(ctx context.Context, arg CreateTagsParams) error
+	// Inserts a new URL record with the original URL, its hash, its domain, its details and its expiry into a workspace.
+	// A canonical record is not inserted, and no row is returned, when the workspace already has a
+	// canonical record with the same hash on the domain.
+	CreateURL(ctx context.Context, arg CreateURLParams) (Url, error)
+	// Creates a new user; the email is unique across all workspaces.
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	// Registers a new webhook endpoint for a set of event types.
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	// Creates a new workspace.
+	CreateWorkspace(ctx context.Context, name string) (Workspace, error)
+	// Deletes a folder of a workspace; its links are kept outside of any folder. Returns the name of
+	// the folder with the short codes and domains of the links it held, whose folder_id is only
+	// cleared at the end of the statement. A folder without links yields a single row with NULL
+	// link columns; no row is returned when the folder does not exist.
+	DeleteFolder(ctx context.Context, arg DeleteFolderParams) ([]DeleteFolderRow, error)
+	// Deletes a tag of a workspace, which removes it from its links, and returns the short codes and
+	// domains of those links as they were before. A tag without links yields a single row of NULLs;
+	// no row is returned when the tag does not exist.
+	DeleteTag(ctx context.Context, arg DeleteTagParams) ([]DeleteTagRow, error)
+	// Deletes a URL of a workspace on a domain (and, by cascade, its clicks) and returns the deleted record.
+	DeleteURLByShortCode(ctx context.Context, arg DeleteURLByShortCodeParams) (Url, error)
+	// Removes every tag from a URL.
+	DeleteURLTags(ctx context.Context, urlID int64) error
+	// Deletes a webhook subscription together with its delivery history.
+	DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error)
+	// Removes a host from a workspace.
+	DeleteWorkspaceDomain(ctx context.Context, arg DeleteWorkspaceDomainParams) (int64, error)
+	// Creates a pending delivery for every active subscription of the workspace whose click threshold was just reached.
+	EnqueueClickThresholdDeliveries(ctx context.Context, arg EnqueueClickThresholdDeliveriesParams) (int64, error)
+	// Creates a pending delivery for every active subscription of the workspace listening to the event type.
+	EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) (int64, error)
+	// Retrieves a key by its ID, including revoked ones.
+	GetAPIKey(ctx context.Context, id int64) (ApiKey, error)
+	// Retrieves a key that has not been revoked by the hash of its secret, together with
+	// the current role of its user; member_role is NULL for keys without a user.
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (GetActiveAPIKeyByHashRow, error)
 	// Aggregates click counts for a given URL ID over a specified time period (e.g., 'day', 'month').
 	GetClicksByPeriod(ctx context.Context, arg GetClicksByPeriodParams) ([]GetClicksByPeriodRow, error)
-	// Aggregates click counts grouped by both a time period AND User-Agent.
+	// Aggregates click counts grouped by both a time period aligned in UTC AND click source within [from_time, to_time).
+	// Clicks without a source marker are counted as 'link'.
+	GetClicksByPeriodAndSource(ctx context.Context, arg GetClicksByPeriodAndSourceParams) ([]GetClicksByPeriodAndSourceRow, error)
+	// Aggregates click counts grouped by both a time period aligned in UTC AND User-Agent within [from_time, to_time).
 	GetClicksByPeriodAndUserAgent(ctx context.Context, arg GetClicksByPeriodAndUserAgentParams) ([]GetClicksByPeriodAndUserAgentRow, error)
-	// Retrieves all click records for a given URL, ordered by the most recent.
-	GetClicksByURLID(ctx context.Context, urlID int64) ([]Click, error)
 	// Aggregates click counts for a given URL ID, grouped by User-Agent.
-	GetClicksByUserAgent(ctx context.Context, urlID int64) ([]GetClicksByUserAgentRow, error)
-	// Retrieves a URL record by its unique short code.
-	GetURLByShortCode(ctx context.Context, shortCode pgtype.Text) (Url, error)
+	GetClicksByUserAgent(ctx context.Context, arg GetClicksByUserAgentParams) ([]GetClicksByUserAgentRow, error)
+	// Returns a dense, zero-filled click series for a URL ID within [from_time, to_time),
+	// with one bucket per period (e.g., 'hour', 'day', 'week', 'month') aligned in UTC.
+	// The first and last buckets only count the clicks inside the range.
+	GetClicksTimeSeries(ctx context.Context, arg GetClicksTimeSeriesParams) ([]GetClicksTimeSeriesRow, error)
+	// Counts all clicks and distinct visitor IPs across all URLs of a workspace within [from_time, to_time).
+	GetGlobalClickTotals(ctx context.Context, arg GetGlobalClickTotalsParams) (GetGlobalClickTotalsRow, error)
+	// Returns a dense, zero-filled click series across all URLs of a workspace within [from_time, to_time),
+	// bucketed like GetClicksTimeSeries.
+	GetGlobalClicksTimeSeries(ctx context.Context, arg GetGlobalClicksTimeSeriesParams) ([]GetGlobalClicksTimeSeriesRow, error)
+	// Retrieves the most recent click records for a given URL, capped by a limit.
+	GetRecentClicksByURLID(ctx context.Context, arg GetRecentClicksByURLIDParams) ([]Click, error)
+	// Retrieves a tag of a workspace by its name with the number of links carrying it.
+	GetTagByName(ctx context.Context, arg GetTagByNameParams) (GetTagByNameRow, error)
+	// Counts clicks and distinct visitor IPs across the URLs carrying a tag within [from_time, to_time).
+	GetTagClickTotals(ctx context.Context, arg GetTagClickTotalsParams) (GetTagClickTotalsRow, error)
+	// Returns a dense, zero-filled click series across the URLs carrying a tag within [from_time, to_time),
+	// bucketed like GetClicksTimeSeries.
+	GetTagClicksTimeSeries(ctx context.Context, arg GetTagClicksTimeSeriesParams) ([]GetTagClicksTimeSeriesRow, error)
+	// Ranks the URLs of a workspace by total or unique (distinct IP) clicks within [from_time, to_time).
+	GetTopURLs(ctx context.Context, arg GetTopURLsParams) ([]GetTopURLsRow, error)
+	// Ranks the URLs carrying a tag by total or unique (distinct IP) clicks within [from_time, to_time).
+	GetTopURLsByTag(ctx context.Context, arg GetTopURLsByTagParams) ([]GetTopURLsByTagRow, error)
+	// Retrieves the oldest link of a workspace on a domain whose normalized destination has the given hash.
+	GetURLByHash(ctx context.Context, arg GetURLByHashParams) (Url, error)
+	// Retrieves a URL record of a workspace by its short code on a domain.
+	GetURLByShortCode(ctx context.Context, arg GetURLByShortCodeParams) (Url, error)
+	// Counts URLs of a workspace created within [from_time, to_time) alongside its overall number of URLs.
+	GetURLTotals(ctx context.Context, arg GetURLTotalsParams) (GetURLTotalsRow, error)
+	// Retrieves a webhook subscription of a workspace by its ID.
+	GetWebhookSubscription(ctx context.Context, arg GetWebhookSubscriptionParams) (WebhookSubscription, error)
+	// Retrieves a workspace by its ID.
+	GetWorkspace(ctx context.Context, id int64) (Workspace, error)
+	// Retrieves the registration of a host regardless of its workspace.
+	GetWorkspaceDomain(ctx context.Context, host string) (WorkspaceDomain, error)
+	// Retrieves the role of a user in a workspace.
+	GetWorkspaceMemberRole(ctx context.Context, arg GetWorkspaceMemberRoleParams) (string, error)
+	// Retrieves API keys, including revoked ones: those of a workspace, or all of them
+	// when workspace_id is NULL.
+	ListAPIKeys(ctx context.Context, workspaceID pgtype.Int8) ([]ApiKey, error)
+	// Lists the audit trail of a workspace, newest first, paginated by ID.
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	// Lists click records for a given URL using keyset pagination on (created_at, id).
+	// Every filter is optional; a NULL argument disables it. user_agent matches a substring,
+	// with LIKE wildcards escaped by the caller.
+	ListClicks(ctx context.Context, arg ListClicksParams) ([]Click, error)
+	// Retrieves the folders of a workspace with the number of links in each.
+	ListFolders(ctx context.Context, workspaceID int64) ([]ListFoldersRow, error)
+	// Retrieves the tags of a workspace with the number of links carrying each.
+	ListTags(ctx context.Context, workspaceID int64) ([]ListTagsRow, error)
+	// Retrieves the tag names of the given URLs of a workspace.
+	ListTagsByURLIDs(ctx context.Context, arg ListTagsByURLIDsParams) ([]ListTagsByURLIDsRow, error)
+	// Retrieves the URLs of all workspaces with an ID above the given one, in ID order.
+	// Only maintenance by operators may use it.
+	ListURLsAfterID(ctx context.Context, arg ListURLsAfterIDParams) ([]Url, error)
+	// Lists the links of a workspace, most clicked first, using keyset pagination on (click_count, id).
+	// The filters are the same as in ListURLsByCreatedAt.
+	ListURLsByClickCount(ctx context.Context, arg ListURLsByClickCountParams) ([]Url, error)
+	// Lists the links of a workspace, newest first, using keyset pagination on (created_at, id).
+	// Every filter is optional; a NULL argument disables it. The domain filter matches the host
+	// and its subdomains; search is a substring match over the destination, short code, title
+	// and description, with LIKE wildcards escaped by the caller; tag keeps the links carrying
+	// the named tag; suspicious keeps flagged (true) or unflagged (false) links.
+	ListURLsByCreatedAt(ctx context.Context, arg ListURLsByCreatedAtParams) ([]Url, error)
+	// Lists the delivery history of a subscription, newest first, paginated by ID.
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// Retrieves all webhook subscriptions of a workspace.
+	ListWebhookSubscriptions(ctx context.Context, workspaceID int64) ([]WebhookSubscription, error)
+	// Retrieves the hosts registered for a workspace.
+	ListWorkspaceDomains(ctx context.Context, workspaceID int64) ([]WorkspaceDomain, error)
+	// Retrieves the users belonging to a workspace with their roles.
+	ListWorkspaceMembers(ctx context.Context, workspaceID int64) ([]ListWorkspaceMembersRow, error)
+	// Retrieves all workspaces.
+	ListWorkspaces(ctx context.Context) ([]Workspace, error)
+	// Marks the oldest URL with a short code of every workspace, domain and hash without a
+	// canonical URL as canonical.
+	MarkCanonicalURLs(ctx context.Context) (int64, error)
+	// Records a failed delivery attempt and either schedules a retry or dead-letters the delivery.
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error
+	// Records a successful delivery attempt.
+	MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) error
+	// Adds a batch of requests to the usage counters of a key.
+	RecordAPIKeyUsage(ctx context.Context, arg RecordAPIKeyUsageParams) error
+	// Retrieves a URL record by its short code on a domain regardless of its workspace.
+	// Only the public redirect may use it.
+	ResolveURLByShortCode(ctx context.Context, arg ResolveURLByShortCodeParams) (Url, error)
+	// Revokes a key; it is rejected from the next request on.
+	RevokeAPIKey(ctx context.Context, id int64) (int64, error)
+	// Replaces the destination hash of a URL regardless of its workspace; the URL is no longer canonical.
+	// Only maintenance by operators may use it.
+	SetURLHash(ctx context.Context, arg SetURLHashParams) error
+	// Flags or unflags a URL on a domain as suspicious regardless of its workspace.
+	// Only operators may use it.
+	SetURLSuspicious(ctx context.Context, arg SetURLSuspiciousParams) (Url, error)
+	// Changes the destination and details of a URL identified by its short code and domain within a workspace.
+	// A NULL argument keeps the current value; metadata is only written when set_metadata is true,
+	// so it can be cleared with NULL; the same holds for redirect_status and set_redirect_status, and for
+	// expires_at and set_expires_at. A new expiry is announced again once it passes.
+	// url_hash is given together with original_url; a URL whose hash changes is no longer canonical.
+	UpdateURL(ctx context.Context, arg UpdateURLParams) (Url, error)
+	// Moves a URL of a workspace on a domain into a folder of the same workspace, or out of any folder
+	// when folder_id is NULL. No row is updated when the folder belongs to another workspace.
+	UpdateURLFolder(ctx context.Context, arg UpdateURLFolderParams) (Url, error)
 	// Updates a URL record with its generated short code.
 	UpdateURLShortCode(ctx context.Context, arg UpdateURLShortCodeParams) error
+	// Changes the role of a member.
+	UpdateWorkspaceMemberRole(ctx context.Context, arg UpdateWorkspaceMemberRoleParams) (int64, error)
+	// Changes whether creating a link reuses an existing link to the same destination.
+	UpdateWorkspaceReuseExistingLinks(ctx context.Context, arg UpdateWorkspaceReuseExistingLinksParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,52 +12,242 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createClick = `-- name: CreateClick :exec
-INSERT INTO clicks (url_id, user_agent, ip_address)
-VALUES ($1, $2, $3)
+const claimExpiredURLs = `-- name: ClaimExpiredURLs :many
+UPDATE urls
+SET expiry_notified = true
+WHERE id IN (
+    SELECT id
+    FROM urls
+    WHERE expires_at <= now()
+      AND NOT expiry_notified
+      AND short_code IS NOT NULL
+    ORDER BY expires_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+`
+
+// Marks up to batch_size URLs of any workspace whose expiry has passed and was not announced yet
+// as announced, and returns them. URLs claimed by a concurrent sweep are skipped.
+func (q *Queries) ClaimExpiredURLs(ctx context.Context, batchSize int32) ([]Url, error) {
+	rows, err := q.db.Query(ctx, claimExpiredURLs, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortCode,
+			&i.CreatedAt,
+			&i.ClickCount,
+			&i.WorkspaceID,
+			&i.FolderID,
+			&i.Title,
+			&i.Description,
+			&i.Metadata,
+			&i.CreatedBy,
+			&i.UrlHash,
+			&i.RedirectStatus,
+			&i.Suspicious,
+			&i.Domain,
+			&i.Canonical,
+			&i.ExpiresAt,
+			&i.ExpiryNotified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countClicksByURLID = `-- name: CountClicksByURLID :one
+SELECT count(*)
+FROM clicks
+WHERE workspace_id = $1
+  AND url_id = $2
+`
+
+type CountClicksByURLIDParams struct {
+	WorkspaceID int64 `json:"workspace_id"`
+	UrlID       int64 `json:"url_id"`
+}
+
+// Counts all click records for a given URL.
+func (q *Queries) CountClicksByURLID(ctx context.Context, arg CountClicksByURLIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countClicksByURLID, arg.WorkspaceID, arg.UrlID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createClick = `-- name: CreateClick :one
+WITH inserted AS (
+    INSERT INTO clicks (url_id, workspace_id, user_agent, ip_address, is_bot, referrer, country, source)
+    VALUES ($1, (SELECT workspace_id FROM urls WHERE id = $1), $2, $3, $4, $5, $6, $7)
+)
+UPDATE urls
+SET click_count = click_count + 1
+WHERE id = $1
+RETURNING click_count
 `
 
 type CreateClickParams struct {
 	UrlID     int64       `json:"url_id"`
 	UserAgent pgtype.Text `json:"user_agent"`
 	IpAddress *netip.Addr `json:"ip_address"`
+	IsBot     bool        `json:"is_bot"`
+	Referrer  pgtype.Text `json:"referrer"`
+	Country   pgtype.Text `json:"country"`
+	Source    pgtype.Text `json:"source"`
 }
 
-// Inserts a new click record for analytics.
-func (q *Queries) CreateClick(ctx context.Context, arg CreateClickParams) error {
-	_, err := q.db.Exec(ctx, createClick, arg.UrlID, arg.UserAgent, arg.IpAddress)
-	return err
+// Inserts a new click record for analytics and returns the URL's updated click count.
+// The click inherits the workspace of its URL.
+func (q *Queries) CreateClick(ctx context.Context, arg CreateClickParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createClick,
+		arg.UrlID,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.IsBot,
+		arg.Referrer,
+		arg.Country,
+		arg.Source,
+	)
+	var click_count int64
+	err := row.Scan(&click_count)
+	return click_count, err
 }
 
 const createURL = `-- name: CreateURL :one
-INSERT INTO urls (original_url)
-VALUES ($1)
-RETURNING id, original_url, short_code, created_at
+INSERT INTO urls (workspace_id, original_url, title, description, metadata, created_by, url_hash, redirect_status, domain, canonical, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (workspace_id, domain, url_hash) WHERE canonical DO NOTHING
+RETURNING id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+`
+
+type CreateURLParams struct {
+	WorkspaceID    int64              `json:"workspace_id"`
+	OriginalUrl    string             `json:"original_url"`
+	Title          string             `json:"title"`
+	Description    string             `json:"description"`
+	Metadata       []byte             `json:"metadata"`
+	CreatedBy      string             `json:"created_by"`
+	UrlHash        []byte             `json:"url_hash"`
+	RedirectStatus pgtype.Int2        `json:"redirect_status"`
+	Domain         string             `json:"domain"`
+	Canonical      bool               `json:"canonical"`
+	ExpiresAt      pgtype.Timestamptz `json:"expires_at"`
+}
+
+// Inserts a new URL record with the original URL, its hash, its domain, its details and its expiry into a workspace.
+// A canonical record is not inserted, and no row is returned, when the workspace already has a
+// canonical record with the same hash on the domain.
+func (q *Queries) CreateURL(ctx context.Context, arg CreateURLParams) (Url, error) {
+	row := q.db.QueryRow(ctx, createURL,
+		arg.WorkspaceID,
+		arg.OriginalUrl,
+		arg.Title,
+		arg.Description,
+		arg.Metadata,
+		arg.CreatedBy,
+		arg.UrlHash,
+		arg.RedirectStatus,
+		arg.Domain,
+		arg.Canonical,
+		arg.ExpiresAt,
+	)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortCode,
+		&i.CreatedAt,
+		&i.ClickCount,
+		&i.WorkspaceID,
+		&i.FolderID,
+		&i.Title,
+		&i.Description,
+		&i.Metadata,
+		&i.CreatedBy,
+		&i.UrlHash,
+		&i.RedirectStatus,
+		&i.Suspicious,
+		&i.Domain,
+		&i.Canonical,
+		&i.ExpiresAt,
+		&i.ExpiryNotified,
+	)
+	return i, err
+}
+
+const deleteURLByShortCode = `-- name: DeleteURLByShortCode :one
+DELETE FROM urls
+WHERE workspace_id = $1
+  AND short_code = $2
+  AND domain = $3
+RETURNING id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
 `
 
-// Inserts a new URL record with the original URL.
-func (q *Queries) CreateURL(ctx context.Context, originalUrl string) (Url, error) {
-	row := q.db.QueryRow(ctx, createURL, originalUrl)
+type DeleteURLByShortCodeParams struct {
+	WorkspaceID int64       `json:"workspace_id"`
+	ShortCode   pgtype.Text `json:"short_code"`
+	Domain      string      `json:"domain"`
+}
+
+// Deletes a URL of a workspace on a domain (and, by cascade, its clicks) and returns the deleted record.
+func (q *Queries) DeleteURLByShortCode(ctx context.Context, arg DeleteURLByShortCodeParams) (Url, error) {
+	row := q.db.QueryRow(ctx, deleteURLByShortCode, arg.WorkspaceID, arg.ShortCode, arg.Domain)
 	var i Url
 	err := row.Scan(
 		&i.ID,
 		&i.OriginalUrl,
 		&i.ShortCode,
 		&i.CreatedAt,
+		&i.ClickCount,
+		&i.WorkspaceID,
+		&i.FolderID,
+		&i.Title,
+		&i.Description,
+		&i.Metadata,
+		&i.CreatedBy,
+		&i.UrlHash,
+		&i.RedirectStatus,
+		&i.Suspicious,
+		&i.Domain,
+		&i.Canonical,
+		&i.ExpiresAt,
+		&i.ExpiryNotified,
 	)
 	return i, err
 }
 
-const getClicksByURLID = `-- name: GetClicksByURLID :many
-SELECT id, url_id, created_at, user_agent, ip_address
+const getRecentClicksByURLID = `-- name: GetRecentClicksByURLID :many
+SELECT id, url_id, created_at, user_agent, ip_address, is_bot, referrer, country, workspace_id, source
 FROM clicks
-WHERE url_id = $1
-ORDER BY created_at DESC
+WHERE workspace_id = $1
+  AND url_id = $2
+ORDER BY created_at DESC, id DESC
+LIMIT $3
 `
 
-// Retrieves all click records for a given URL, ordered by the most recent.
-func (q *Queries) GetClicksByURLID(ctx context.Context, urlID int64) ([]Click, error) {
-	rows, err := q.db.Query(ctx, getClicksByURLID, urlID)
+type GetRecentClicksByURLIDParams struct {
+	WorkspaceID int64 `json:"workspace_id"`
+	UrlID       int64 `json:"url_id"`
+	Limit       int32 `json:"limit"`
+}
+
+// Retrieves the most recent click records for a given URL, capped by a limit.
+func (q *Queries) GetRecentClicksByURLID(ctx context.Context, arg GetRecentClicksByURLIDParams) ([]Click, error) {
+	rows, err := q.db.Query(ctx, getRecentClicksByURLID, arg.WorkspaceID, arg.UrlID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +261,11 @@ func (q *Queries) GetClicksByURLID(ctx context.Context, urlID int64) ([]Click, e
 			&i.CreatedAt,
 			&i.UserAgent,
 			&i.IpAddress,
+			&i.IsBot,
+			&i.Referrer,
+			&i.Country,
+			&i.WorkspaceID,
+			&i.Source,
 		); err != nil {
 			return nil, err
 		}
@@ -82,21 +277,660 @@ func (q *Queries) GetClicksByURLID(ctx context.Context, urlID int64) ([]Click, e
 	return items, nil
 }
 
+const getURLByHash = `-- name: GetURLByHash :one
+SELECT id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+FROM urls
+WHERE workspace_id = $1
+  AND url_hash = $2
+  AND domain = $3
+  AND short_code IS NOT NULL
+ORDER BY id
+LIMIT 1
+`
+
+type GetURLByHashParams struct {
+	WorkspaceID int64  `json:"workspace_id"`
+	UrlHash     []byte `json:"url_hash"`
+	Domain      string `json:"domain"`
+}
+
+// Retrieves the oldest link of a workspace on a domain whose normalized destination has the given hash.
+func (q *Queries) GetURLByHash(ctx context.Context, arg GetURLByHashParams) (Url, error) {
+	row := q.db.QueryRow(ctx, getURLByHash, arg.WorkspaceID, arg.UrlHash, arg.Domain)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortCode,
+		&i.CreatedAt,
+		&i.ClickCount,
+		&i.WorkspaceID,
+		&i.FolderID,
+		&i.Title,
+		&i.Description,
+		&i.Metadata,
+		&i.CreatedBy,
+		&i.UrlHash,
+		&i.RedirectStatus,
+		&i.Suspicious,
+		&i.Domain,
+		&i.Canonical,
+		&i.ExpiresAt,
+		&i.ExpiryNotified,
+	)
+	return i, err
+}
+
 const getURLByShortCode = `-- name: GetURLByShortCode :one
-SELECT id, original_url, short_code, created_at
+SELECT id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+FROM urls
+WHERE workspace_id = $1
+  AND short_code = $2
+  AND domain = $3
+`
+
+type GetURLByShortCodeParams struct {
+	WorkspaceID int64       `json:"workspace_id"`
+	ShortCode   pgtype.Text `json:"short_code"`
+	Domain      string      `json:"domain"`
+}
+
+// Retrieves a URL record of a workspace by its short code on a domain.
+func (q *Queries) GetURLByShortCode(ctx context.Context, arg GetURLByShortCodeParams) (Url, error) {
+	row := q.db.QueryRow(ctx, getURLByShortCode, arg.WorkspaceID, arg.ShortCode, arg.Domain)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortCode,
+		&i.CreatedAt,
+		&i.ClickCount,
+		&i.WorkspaceID,
+		&i.FolderID,
+		&i.Title,
+		&i.Description,
+		&i.Metadata,
+		&i.CreatedBy,
+		&i.UrlHash,
+		&i.RedirectStatus,
+		&i.Suspicious,
+		&i.Domain,
+		&i.Canonical,
+		&i.ExpiresAt,
+		&i.ExpiryNotified,
+	)
+	return i, err
+}
+
+const listClicks = `-- name: ListClicks :many
+SELECT id, url_id, created_at, user_agent, ip_address, is_bot, referrer, country, workspace_id, source
+FROM clicks
+WHERE workspace_id = $1
+  AND url_id = $2
+  AND ($3::timestamptz IS NULL OR created_at >= $3)
+  AND ($4::timestamptz IS NULL OR created_at < $4)
+  AND ($5::boolean IS NULL OR is_bot = $5)
+  AND ($6::text IS NULL OR user_agent ILIKE '%' || $6 || '%' ESCAPE '\')
+  AND ($7::timestamptz IS NULL
+       OR (created_at, id) < ($7, $8::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+`
+
+type ListClicksParams struct {
+	WorkspaceID     int64              `json:"workspace_id"`
+	UrlID           int64              `json:"url_id"`
+	FromTime        pgtype.Timestamptz `json:"from_time"`
+	ToTime          pgtype.Timestamptz `json:"to_time"`
+	IsBot           pgtype.Bool        `json:"is_bot"`
+	UserAgent       pgtype.Text        `json:"user_agent"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        pgtype.Int8        `json:"cursor_id"`
+	PageSize        int32              `json:"page_size"`
+}
+
+// Lists click records for a given URL using keyset pagination on (created_at, id).
+// Every filter is optional; a NULL argument disables it. user_agent matches a substring,
+// with LIKE wildcards escaped by the caller.
+func (q *Queries) ListClicks(ctx context.Context, arg ListClicksParams) ([]Click, error) {
+	rows, err := q.db.Query(ctx, listClicks,
+		arg.WorkspaceID,
+		arg.UrlID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.IsBot,
+		arg.UserAgent,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Click
+	for rows.Next() {
+		var i Click
+		if err := rows.Scan(
+			&i.ID,
+			&i.UrlID,
+			&i.CreatedAt,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.IsBot,
+			&i.Referrer,
+			&i.Country,
+			&i.WorkspaceID,
+			&i.Source,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listURLsAfterID = `-- name: ListURLsAfterID :many
+SELECT id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+FROM urls
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListURLsAfterIDParams struct {
+	ID    int64 `json:"id"`
+	Limit int32 `json:"limit"`
+}
+
+// Retrieves the URLs of all workspaces with an ID above the given one, in ID order.
+// Only maintenance by operators may use it.
+func (q *Queries) ListURLsAfterID(ctx context.Context, arg ListURLsAfterIDParams) ([]Url, error) {
+	rows, err := q.db.Query(ctx, listURLsAfterID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortCode,
+			&i.CreatedAt,
+			&i.ClickCount,
+			&i.WorkspaceID,
+			&i.FolderID,
+			&i.Title,
+			&i.Description,
+			&i.Metadata,
+			&i.CreatedBy,
+			&i.UrlHash,
+			&i.RedirectStatus,
+			&i.Suspicious,
+			&i.Domain,
+			&i.Canonical,
+			&i.ExpiresAt,
+			&i.ExpiryNotified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listURLsByClickCount = `-- name: ListURLsByClickCount :many
+SELECT id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+FROM urls
+WHERE workspace_id = $1
+  AND short_code IS NOT NULL
+  AND ($2::timestamptz IS NULL OR created_at >= $2)
+  AND ($3::timestamptz IS NULL OR created_at < $3)
+  AND ($4::text IS NULL
+       OR url_host(original_url) = $4
+       OR right(url_host(original_url), length($4) + 1) = '.' || $4)
+  AND ($5::text IS NULL
+       OR original_url ILIKE '%' || $5 || '%' ESCAPE '\'
+       OR short_code ILIKE '%' || $5 || '%' ESCAPE '\'
+       OR title ILIKE '%' || $5 || '%' ESCAPE '\'
+       OR description ILIKE '%' || $5 || '%' ESCAPE '\')
+  AND ($6::bigint IS NULL OR folder_id = $6)
+  AND ($7::text IS NULL OR EXISTS (
+       SELECT 1
+       FROM url_tags ut
+       JOIN tags t ON t.id = ut.tag_id
+       WHERE ut.url_id = urls.id
+         AND t.name = $7))
+  AND ($8::boolean IS NULL OR suspicious = $8)
+  AND ($9::bigint IS NULL
+       OR (click_count, id) < ($9, $10::bigint))
+ORDER BY click_count DESC, id DESC
+LIMIT $11
+`
+
+type ListURLsByClickCountParams struct {
+	WorkspaceID      int64              `json:"workspace_id"`
+	FromTime         pgtype.Timestamptz `json:"from_time"`
+	ToTime           pgtype.Timestamptz `json:"to_time"`
+	Domain           pgtype.Text        `json:"domain"`
+	Search           pgtype.Text        `json:"search"`
+	FolderID         pgtype.Int8        `json:"folder_id"`
+	Tag              pgtype.Text        `json:"tag"`
+	Suspicious       pgtype.Bool        `json:"suspicious"`
+	CursorClickCount pgtype.Int8        `json:"cursor_click_count"`
+	CursorID         pgtype.Int8        `json:"cursor_id"`
+	PageSize         int32              `json:"page_size"`
+}
+
+// Lists the links of a workspace, most clicked first, using keyset pagination on (click_count, id).
+// The filters are the same as in ListURLsByCreatedAt.
+func (q *Queries) ListURLsByClickCount(ctx context.Context, arg ListURLsByClickCountParams) ([]Url, error) {
+	rows, err := q.db.Query(ctx, listURLsByClickCount,
+		arg.WorkspaceID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Domain,
+		arg.Search,
+		arg.FolderID,
+		arg.Tag,
+		arg.Suspicious,
+		arg.CursorClickCount,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortCode,
+			&i.CreatedAt,
+			&i.ClickCount,
+			&i.WorkspaceID,
+			&i.FolderID,
+			&i.Title,
+			&i.Description,
+			&i.Metadata,
+			&i.CreatedBy,
+			&i.UrlHash,
+			&i.RedirectStatus,
+			&i.Suspicious,
+			&i.Domain,
+			&i.Canonical,
+			&i.ExpiresAt,
+			&i.ExpiryNotified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listURLsByCreatedAt = `-- name: ListURLsByCreatedAt :many
+SELECT id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
 FROM urls
-WHERE short_code = $1
+WHERE workspace_id = $1
+  AND short_code IS NOT NULL
+  AND ($2::timestamptz IS NULL OR created_at >= $2)
+  AND ($3::timestamptz IS NULL OR created_at < $3)
+  AND ($4::text IS NULL
+       OR url_host(original_url) = $4
+       OR right(url_host(original_url), length($4) + 1) = '.' || $4)
+  AND ($5::text IS NULL
+       OR original_url ILIKE '%' || $5 || '%' ESCAPE '\'
+       OR short_code ILIKE '%' || $5 || '%' ESCAPE '\'
+       OR title ILIKE '%' || $5 || '%' ESCAPE '\'
+       OR description ILIKE '%' || $5 || '%' ESCAPE '\')
+  AND ($6::bigint IS NULL OR folder_id = $6)
+  AND ($7::text IS NULL OR EXISTS (
+       SELECT 1
+       FROM url_tags ut
+       JOIN tags t ON t.id = ut.tag_id
+       WHERE ut.url_id = urls.id
+         AND t.name = $7))
+  AND ($8::boolean IS NULL OR suspicious = $8)
+  AND ($9::timestamptz IS NULL
+       OR (created_at, id) < ($9, $10::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT $11
+`
+
+type ListURLsByCreatedAtParams struct {
+	WorkspaceID     int64              `json:"workspace_id"`
+	FromTime        pgtype.Timestamptz `json:"from_time"`
+	ToTime          pgtype.Timestamptz `json:"to_time"`
+	Domain          pgtype.Text        `json:"domain"`
+	Search          pgtype.Text        `json:"search"`
+	FolderID        pgtype.Int8        `json:"folder_id"`
+	Tag             pgtype.Text        `json:"tag"`
+	Suspicious      pgtype.Bool        `json:"suspicious"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        pgtype.Int8        `json:"cursor_id"`
+	PageSize        int32              `json:"page_size"`
+}
+
+// Lists the links of a workspace, newest first, using keyset pagination on (created_at, id).
+// Every filter is optional; a NULL argument disables it. The domain filter matches the host
+// and its subdomains; search is a substring match over the destination, short code, title
+// and description, with LIKE wildcards escaped by the caller; tag keeps the links carrying
+// the named tag; suspicious keeps flagged (true) or unflagged (false) links.
+func (q *Queries) ListURLsByCreatedAt(ctx context.Context, arg ListURLsByCreatedAtParams) ([]Url, error) {
+	rows, err := q.db.Query(ctx, listURLsByCreatedAt,
+		arg.WorkspaceID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Domain,
+		arg.Search,
+		arg.FolderID,
+		arg.Tag,
+		arg.Suspicious,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Url
+	for rows.Next() {
+		var i Url
+		if err := rows.Scan(
+			&i.ID,
+			&i.OriginalUrl,
+			&i.ShortCode,
+			&i.CreatedAt,
+			&i.ClickCount,
+			&i.WorkspaceID,
+			&i.FolderID,
+			&i.Title,
+			&i.Description,
+			&i.Metadata,
+			&i.CreatedBy,
+			&i.UrlHash,
+			&i.RedirectStatus,
+			&i.Suspicious,
+			&i.Domain,
+			&i.Canonical,
+			&i.ExpiresAt,
+			&i.ExpiryNotified,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markCanonicalURLs = `-- name: MarkCanonicalURLs :execrows
+UPDATE urls u
+SET canonical = true
+WHERE u.url_hash IS NOT NULL
+  AND u.short_code IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1
+    FROM urls o
+    WHERE o.workspace_id = u.workspace_id
+      AND o.domain = u.domain
+      AND o.url_hash = u.url_hash
+      AND (o.canonical OR (o.short_code IS NOT NULL AND o.id < u.id))
+)
 `
 
-// Retrieves a URL record by its unique short code.
-func (q *Queries) GetURLByShortCode(ctx context.Context, shortCode pgtype.Text) (Url, error) {
-	row := q.db.QueryRow(ctx, getURLByShortCode, shortCode)
+// Marks the oldest URL with a short code of every workspace, domain and hash without a
+// canonical URL as canonical.
+func (q *Queries) MarkCanonicalURLs(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, markCanonicalURLs)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resolveURLByShortCode = `-- name: ResolveURLByShortCode :one
+SELECT id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+FROM urls
+WHERE domain = $1
+  AND short_code = $2
+`
+
+type ResolveURLByShortCodeParams struct {
+	Domain    string      `json:"domain"`
+	ShortCode pgtype.Text `json:"short_code"`
+}
+
+// Retrieves a URL record by its short code on a domain regardless of its workspace.
+// Only the public redirect may use it.
+func (q *Queries) ResolveURLByShortCode(ctx context.Context, arg ResolveURLByShortCodeParams) (Url, error) {
+	row := q.db.QueryRow(ctx, resolveURLByShortCode, arg.Domain, arg.ShortCode)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortCode,
+		&i.CreatedAt,
+		&i.ClickCount,
+		&i.WorkspaceID,
+		&i.FolderID,
+		&i.Title,
+		&i.Description,
+		&i.Metadata,
+		&i.CreatedBy,
+		&i.UrlHash,
+		&i.RedirectStatus,
+		&i.Suspicious,
+		&i.Domain,
+		&i.Canonical,
+		&i.ExpiresAt,
+		&i.ExpiryNotified,
+	)
+	return i, err
+}
+
+const setURLHash = `-- name: SetURLHash :exec
+UPDATE urls
+SET url_hash = $2,
+    canonical = false
+WHERE id = $1
+`
+
+type SetURLHashParams struct {
+	ID      int64  `json:"id"`
+	UrlHash []byte `json:"url_hash"`
+}
+
+// Replaces the destination hash of a URL regardless of its workspace; the URL is no longer canonical.
+// Only maintenance by operators may use it.
+func (q *Queries) SetURLHash(ctx context.Context, arg SetURLHashParams) error {
+	_, err := q.db.Exec(ctx, setURLHash, arg.ID, arg.UrlHash)
+	return err
+}
+
+const setURLSuspicious = `-- name: SetURLSuspicious :one
+UPDATE urls
+SET suspicious = $3
+WHERE domain = $1
+  AND short_code = $2
+RETURNING id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+`
+
+type SetURLSuspiciousParams struct {
+	Domain     string      `json:"domain"`
+	ShortCode  pgtype.Text `json:"short_code"`
+	Suspicious bool        `json:"suspicious"`
+}
+
+// Flags or unflags a URL on a domain as suspicious regardless of its workspace.
+// Only operators may use it.
+func (q *Queries) SetURLSuspicious(ctx context.Context, arg SetURLSuspiciousParams) (Url, error) {
+	row := q.db.QueryRow(ctx, setURLSuspicious, arg.Domain, arg.ShortCode, arg.Suspicious)
 	var i Url
 	err := row.Scan(
 		&i.ID,
 		&i.OriginalUrl,
 		&i.ShortCode,
 		&i.CreatedAt,
+		&i.ClickCount,
+		&i.WorkspaceID,
+		&i.FolderID,
+		&i.Title,
+		&i.Description,
+		&i.Metadata,
+		&i.CreatedBy,
+		&i.UrlHash,
+		&i.RedirectStatus,
+		&i.Suspicious,
+		&i.Domain,
+		&i.Canonical,
+		&i.ExpiresAt,
+		&i.ExpiryNotified,
+	)
+	return i, err
+}
+
+const updateURL = `-- name: UpdateURL :one
+UPDATE urls
+SET original_url = COALESCE($1, original_url),
+    url_hash = COALESCE($2, url_hash),
+    canonical = canonical AND COALESCE($2 = url_hash, true),
+    title = COALESCE($3, title),
+    description = COALESCE($4, description),
+    metadata = CASE WHEN $5::boolean THEN $6::jsonb ELSE metadata END,
+    redirect_status = CASE WHEN $7::boolean THEN $8::smallint ELSE redirect_status END,
+    expires_at = CASE WHEN $9::boolean THEN $10::timestamptz ELSE expires_at END,
+    expiry_notified = expiry_notified AND NOT $9::boolean
+WHERE workspace_id = $11
+  AND short_code = $12
+  AND domain = $13
+RETURNING id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+`
+
+type UpdateURLParams struct {
+	OriginalUrl       pgtype.Text        `json:"original_url"`
+	UrlHash           []byte             `json:"url_hash"`
+	Title             pgtype.Text        `json:"title"`
+	Description       pgtype.Text        `json:"description"`
+	SetMetadata       bool               `json:"set_metadata"`
+	Metadata          []byte             `json:"metadata"`
+	SetRedirectStatus bool               `json:"set_redirect_status"`
+	RedirectStatus    pgtype.Int2        `json:"redirect_status"`
+	SetExpiresAt      bool               `json:"set_expires_at"`
+	ExpiresAt         pgtype.Timestamptz `json:"expires_at"`
+	WorkspaceID       int64              `json:"workspace_id"`
+	ShortCode         pgtype.Text        `json:"short_code"`
+	Domain            string             `json:"domain"`
+}
+
+// Changes the destination and details of a URL identified by its short code and domain within a workspace.
+// A NULL argument keeps the current value; metadata is only written when set_metadata is true,
+// so it can be cleared with NULL; the same holds for redirect_status and set_redirect_status, and for
+// expires_at and set_expires_at. A new expiry is announced again once it passes.
+// url_hash is given together with original_url; a URL whose hash changes is no longer canonical.
+func (q *Queries) UpdateURL(ctx context.Context, arg UpdateURLParams) (Url, error) {
+	row := q.db.QueryRow(ctx, updateURL,
+		arg.OriginalUrl,
+		arg.UrlHash,
+		arg.Title,
+		arg.Description,
+		arg.SetMetadata,
+		arg.Metadata,
+		arg.SetRedirectStatus,
+		arg.RedirectStatus,
+		arg.SetExpiresAt,
+		arg.ExpiresAt,
+		arg.WorkspaceID,
+		arg.ShortCode,
+		arg.Domain,
+	)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortCode,
+		&i.CreatedAt,
+		&i.ClickCount,
+		&i.WorkspaceID,
+		&i.FolderID,
+		&i.Title,
+		&i.Description,
+		&i.Metadata,
+		&i.CreatedBy,
+		&i.UrlHash,
+		&i.RedirectStatus,
+		&i.Suspicious,
+		&i.Domain,
+		&i.Canonical,
+		&i.ExpiresAt,
+		&i.ExpiryNotified,
+	)
+	return i, err
+}
+
+const updateURLFolder = `-- name: UpdateURLFolder :one
+UPDATE urls
+SET folder_id = $1
+WHERE workspace_id = $2
+  AND short_code = $3
+  AND domain = $4
+  AND ($1::bigint IS NULL OR EXISTS (
+       SELECT 1
+       FROM folders
+       WHERE id = $1
+         AND workspace_id = $2))
+RETURNING id, original_url, short_code, created_at, click_count, workspace_id, folder_id, title, description, metadata, created_by, url_hash, redirect_status, suspicious, domain, canonical, expires_at, expiry_notified
+`
+
+type UpdateURLFolderParams struct {
+	FolderID    pgtype.Int8 `json:"folder_id"`
+	WorkspaceID int64       `json:"workspace_id"`
+	ShortCode   pgtype.Text `json:"short_code"`
+	Domain      string      `json:"domain"`
+}
+
+// Moves a URL of a workspace on a domain into a folder of the same workspace, or out of any folder
+// when folder_id is NULL. No row is updated when the folder belongs to another workspace.
+func (q *Queries) UpdateURLFolder(ctx context.Context, arg UpdateURLFolderParams) (Url, error) {
+	row := q.db.QueryRow(ctx, updateURLFolder, arg.FolderID, arg.WorkspaceID, arg.ShortCode, arg.Domain)
+	var i Url
+	err := row.Scan(
+		&i.ID,
+		&i.OriginalUrl,
+		&i.ShortCode,
+		&i.CreatedAt,
+		&i.ClickCount,
+		&i.WorkspaceID,
+		&i.FolderID,
+		&i.Title,
+		&i.Description,
+		&i.Metadata,
+		&i.CreatedBy,
+		&i.UrlHash,
+		&i.RedirectStatus,
+		&i.Suspicious,
+		&i.Domain,
+		&i.Canonical,
+		&i.ExpiresAt,
+		&i.ExpiryNotified,
 	)
 	return i, err
 }
@@ -105,15 +939,17 @@ const updateURLShortCode = `-- name: UpdateURLShortCode :exec
 UPDATE urls
 SET short_code = $2
 WHERE id = $1
+  AND workspace_id = $3
 `
 
 type UpdateURLShortCodeParams struct {
-	ID        int64       `json:"id"`
-	ShortCode pgtype.Text `json:"short_code"`
+	ID          int64       `json:"id"`
+	ShortCode   pgtype.Text `json:"short_code"`
+	WorkspaceID int64       `json:"workspace_id"`
 }
 
 // Updates a URL record with its generated short code.
 func (q *Queries) UpdateURLShortCode(ctx context.Context, arg UpdateURLShortCodeParams) error {
-	_, err := q.db.Exec(ctx, updateURLShortCode, arg.ID, arg.ShortCode)
+	_, err := q.db.Exec(ctx, updateURLShortCode, arg.ID, arg.ShortCode, arg.WorkspaceID)
 	return err
 }
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Ensures that FolderRepository correctly implements the repo.FolderRepository interface at compile time.
+var _ repo.FolderRepository = (*FolderRepository)(nil)
+
+// FolderRepository implements the domain.repository.FolderRepository interface
+// using PostgreSQL as a backend.
+type FolderRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	logger  zerolog.Logger
+}
+
+// NewFolderRepository creates a new instance of FolderRepository.
+func NewFolderRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *FolderRepository {
+	return &FolderRepository{
+		pool:    pool,
+		queries: db.New(pool),
+		logger:  logger.With().Str("layer", "postgres_folder_repository").Logger(),
+	}
+}
+
+// Create persists a new folder. A name already taken in the workspace yields repo.ErrDuplicateRecord.
+func (r *FolderRepository) Create(ctx context.Context, workspaceID int64, name string) (*model.Folder, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).CreateFolder(ctx, db.CreateFolderParams{
+		WorkspaceID: workspaceID,
+		Name:        name,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return nil, repo.ErrDuplicateRecord
+		}
+		log.Error().Err(err).Str("name", name).Msg("Failed to create folder")
+		return nil, fmt.Errorf("postgres: CreateFolder failed: %w", err)
+	}
+
+	return &model.Folder{
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Name:        row.Name,
+		CreatedAt:   row.CreatedAt.Time,
+	}, nil
+}
+
+// List retrieves the folders of a workspace with their link counts.
+func (r *FolderRepository) List(ctx context.Context, workspaceID int64) ([]model.Folder, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).ListFolders(ctx, workspaceID)
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Msg("Failed to list folders")
+		return nil, fmt.Errorf("postgres: ListFolders failed: %w", err)
+	}
+
+	folders := make([]model.Folder, len(rows))
+	for i, row := range rows {
+		folders[i] = model.Folder{
+			ID:          row.ID,
+			WorkspaceID: row.WorkspaceID,
+			Name:        row.Name,
+			CreatedAt:   row.CreatedAt.Time,
+			LinkCount:   row.LinkCount,
+		}
+	}
+	return folders, nil
+}
+
+// Delete removes a folder of a workspace and returns it with the links it held. Unknown folders yield repo.ErrNotFound.
+func (r *FolderRepository) Delete(ctx context.Context, workspaceID, id int64) (*model.Folder, []model.LinkRef, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).DeleteFolder(ctx, db.DeleteFolderParams{
+		WorkspaceID: workspaceID,
+		ID:          id,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("folder_id", id).Msg("Failed to delete folder")
+		return nil, nil, fmt.Errorf("postgres: DeleteFolder failed: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, repo.ErrNotFound
+	}
+
+	links := make([]model.LinkRef, 0, len(rows))
+	for _, row := range rows {
+		if row.ShortCode.Valid {
+			links = append(links, model.LinkRef{ShortCode: row.ShortCode.String, Domain: row.Domain.String})
+		}
+	}
+	folder := &model.Folder{ID: id, WorkspaceID: workspaceID, Name: rows[0].Name, LinkCount: int64(len(links))}
+	return folder, links, nil
+}
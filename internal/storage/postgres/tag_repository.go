@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Ensures that TagRepository correctly implements the repo.TagRepository interface at compile time.
+var _ repo.TagRepository = (*TagRepository)(nil)
+
+// TagRepository implements the domain.repository.TagRepository interface
+// using PostgreSQL as a backend.
+type TagRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	logger  zerolog.Logger
+}
+
+// NewTagRepository creates a new instance of TagRepository.
+func NewTagRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *TagRepository {
+	return &TagRepository{
+		pool:    pool,
+		queries: db.New(pool),
+		logger:  logger.With().Str("layer", "postgres_tag_repository").Logger(),
+	}
+}
+
+// SetURLTags replaces the tags of a URL. The three statements must run in one transaction,
+// which the caller provides through the context.
+func (r *TagRepository) SetURLTags(ctx context.Context, workspaceID, urlID int64, names []string) error {
+	log := logger.FromContext(ctx, r.logger)
+	queries := queriesFrom(ctx, r.queries)
+
+	if err := queries.DeleteURLTags(ctx, urlID); err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Failed to remove URL tags")
+		return fmt.Errorf("postgres: DeleteURLTags failed: %w", err)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	if err := queries.CreateTags(ctx, db.CreateTagsParams{WorkspaceID: workspaceID, Names: names}); err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Msg("Failed to create tags")
+		return fmt.Errorf("postgres: CreateTags failed: %w", err)
+	}
+	err := queries.AddURLTags(ctx, db.AddURLTagsParams{UrlID: urlID, WorkspaceID: workspaceID, Names: names})
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("Failed to add URL tags")
+		return fmt.Errorf("postgres: AddURLTags failed: %w", err)
+	}
+
+	return nil
+}
+
+// ListByURLs retrieves the tag names of the given URLs, sorted by name.
+func (r *TagRepository) ListByURLs(ctx context.Context, workspaceID int64, urlIDs []int64) (map[int64][]string, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).ListTagsByURLIDs(ctx, db.ListTagsByURLIDsParams{
+		WorkspaceID: workspaceID,
+		UrlIds:      urlIDs,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Msg("Failed to list URL tags")
+		return nil, fmt.Errorf("postgres: ListTagsByURLIDs failed: %w", err)
+	}
+
+	tags := make(map[int64][]string)
+	for _, row := range rows {
+		tags[row.UrlID] = append(tags[row.UrlID], row.Name)
+	}
+	return tags, nil
+}
+
+// List retrieves the tags of a workspace with their link counts.
+func (r *TagRepository) List(ctx context.Context, workspaceID int64) ([]model.Tag, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).ListTags(ctx, workspaceID)
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Msg("Failed to list tags")
+		return nil, fmt.Errorf("postgres: ListTags failed: %w", err)
+	}
+
+	tags := make([]model.Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = model.Tag{
+			ID:          row.ID,
+			WorkspaceID: row.WorkspaceID,
+			Name:        row.Name,
+			CreatedAt:   row.CreatedAt.Time,
+			LinkCount:   row.LinkCount,
+		}
+	}
+	return tags, nil
+}
+
+// GetByName retrieves a tag of a workspace by its name. Unknown tags yield repo.ErrNotFound.
+func (r *TagRepository) GetByName(ctx context.Context, workspaceID int64, name string) (*model.Tag, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).GetTagByName(ctx, db.GetTagByNameParams{
+		WorkspaceID: workspaceID,
+		Name:        name,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Str("tag", name).Msg("Failed to get tag")
+		return nil, fmt.Errorf("postgres: GetTagByName failed: %w", err)
+	}
+
+	return &model.Tag{
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Name:        row.Name,
+		CreatedAt:   row.CreatedAt.Time,
+		LinkCount:   row.LinkCount,
+	}, nil
+}
+
+// Delete removes a tag of a workspace and returns the links that carried it. Unknown tags yield repo.ErrNotFound.
+func (r *TagRepository) Delete(ctx context.Context, workspaceID int64, name string) ([]model.LinkRef, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).DeleteTag(ctx, db.DeleteTagParams{
+		WorkspaceID: workspaceID,
+		Name:        name,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("tag", name).Msg("Failed to delete tag")
+		return nil, fmt.Errorf("postgres: DeleteTag failed: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, repo.ErrNotFound
+	}
+
+	links := make([]model.LinkRef, 0, len(rows))
+	for _, row := range rows {
+		if row.ShortCode.Valid {
+			links = append(links, model.LinkRef{ShortCode: row.ShortCode.String, Domain: row.Domain.String})
+		}
+	}
+	return links, nil
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Ensures that Transactor correctly implements the repo.Transactor interface at compile time.
+var _ repo.Transactor = (*Transactor)(nil)
+
+type txKey struct{}
+
+// Transactor implements the domain.repository.Transactor interface. The transaction travels
+// in the context, so repositories need no transaction-specific methods.
+type Transactor struct {
+	pool   *pgxpool.Pool
+	logger zerolog.Logger
+}
+
+// NewTransactor creates a new instance of Transactor.
+func NewTransactor(pool *pgxpool.Pool, logger *zerolog.Logger) *Transactor {
+	return &Transactor{
+		pool:   pool,
+		logger: logger.With().Str("layer", "postgres_transactor").Logger(),
+	}
+}
+
+// WithinTx calls fn in a transaction; a transaction already carried by ctx is joined.
+// Functions registered with repo.AfterCommit run once the outermost transaction commits.
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	txCtx, runHooks := repo.WithCommitHooks(ctx)
+	err := pgx.BeginFunc(ctx, t.pool, func(tx pgx.Tx) error {
+		return fn(context.WithValue(txCtx, txKey{}, tx))
+	})
+	if err != nil {
+		logger.FromContext(ctx, t.logger).Debug().Err(err).Msg("Transaction rolled back")
+		return err
+	}
+	runHooks()
+	return nil
+}
+
+// queriesFrom returns q bound to the transaction carried by ctx, or q itself outside a transaction.
+func queriesFrom(ctx context.Context, q *db.Queries) *db.Queries {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return q.WithTx(tx)
+	}
+	return q
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/ilindan-dev/shortener/internal/domain/model"
 	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
 	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
@@ -13,6 +14,8 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
+	"strings"
+	"time"
 )
 
 // Ensures that URLRepository correctly implements the repo.URLRepository interface at compile time.
@@ -35,16 +38,47 @@ func NewURLRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *URLRepository
 	}
 }
 
-// Create persists a new URL record in the database.
-func (r *URLRepository) Create(ctx context.Context, originalURL string) (*model.URL, error) {
-	createdDB, err := r.queries.CreateURL(ctx, originalURL)
+// Create persists a new URL record of a workspace in the database.
+func (r *URLRepository) Create(ctx context.Context, url *model.URL) (*model.URL, error) {
+	return r.create(ctx, url, false)
+}
+
+// CreateCanonical persists a new URL record as the canonical one of its destination.
+// The unique index on canonical URLs makes concurrent creations of the same destination wait
+// for each other; all but the first one yield ErrDuplicateRecord.
+func (r *URLRepository) CreateCanonical(ctx context.Context, url *model.URL) (*model.URL, error) {
+	return r.create(ctx, url, true)
+}
+
+func (r *URLRepository) create(ctx context.Context, url *model.URL, canonical bool) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	createdDB, err := queriesFrom(ctx, r.queries).CreateURL(ctx, db.CreateURLParams{
+		WorkspaceID: url.WorkspaceID,
+		OriginalUrl: url.OriginalURL,
+		Title:       url.Title,
+		Description: url.Description,
+		Metadata:    url.Metadata,
+		CreatedBy:   url.CreatedBy,
+		UrlHash:     url.URLHash,
+		RedirectStatus: pgtype.Int2{
+			Int16: int16(url.RedirectStatus),
+			Valid: url.RedirectStatus != 0,
+		},
+		Domain:    url.Domain,
+		Canonical: canonical,
+		ExpiresAt: toTimestamptz(url.ExpiresAt),
+	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Info().Str("url", url.OriginalURL).Msg("Canonical URL already exists")
+			return nil, repo.ErrDuplicateRecord
+		}
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-			r.logger.Warn().Err(err).Str("url", originalURL).Msg("Failed to create URL due to duplicate")
+			log.Warn().Err(err).Str("url", url.OriginalURL).Msg("Failed to create URL due to duplicate")
 			return nil, repo.ErrDuplicateRecord
 		}
-		r.logger.Error().Err(err).Str("url", originalURL).Msg("Failed to create URL")
+		log.Error().Err(err).Str("url", url.OriginalURL).Msg("Failed to create URL")
 		return nil, fmt.Errorf("postgres: CreateURL failed: %w", err)
 	}
 
@@ -52,47 +86,386 @@ func (r *URLRepository) Create(ctx context.Context, originalURL string) (*model.
 }
 
 // UpdateShortCode updates an existing URL record with its generated short code.
-func (r *URLRepository) UpdateShortCode(ctx context.Context, id int64, shortCode string) error {
+func (r *URLRepository) UpdateShortCode(ctx context.Context, workspaceID, id int64, shortCode string) error {
+	log := logger.FromContext(ctx, r.logger)
 	params := db.UpdateURLShortCodeParams{
-		ID:        id,
-		ShortCode: pgtype.Text{String: shortCode, Valid: true},
+		ID:          id,
+		ShortCode:   pgtype.Text{String: shortCode, Valid: true},
+		WorkspaceID: workspaceID,
 	}
 
-	err := r.queries.UpdateURLShortCode(ctx, params)
+	err := queriesFrom(ctx, r.queries).UpdateURLShortCode(ctx, params)
 	if err != nil {
-		r.logger.Error().Err(err).Int64("id", id).Msg("Failed to update URL short code")
+		log.Error().Err(err).Int64("id", id).Msg("Failed to update URL short code")
 		return fmt.Errorf("postgres: UpdateURLShortCode failed: %w", err)
 	}
 
 	return nil
 }
 
-// GetByShortCode retrieves a single URL from the database by its unique short code.
-func (r *URLRepository) GetByShortCode(ctx context.Context, shortCode string) (*model.URL, error) {
-	dbURL, err := r.queries.GetURLByShortCode(ctx, pgtype.Text{String: shortCode, Valid: true})
+// GetByShortCode retrieves a single URL of a workspace from the database by its short code on a domain.
+func (r *URLRepository) GetByShortCode(ctx context.Context, workspaceID int64, domain, shortCode string) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	dbURL, err := queriesFrom(ctx, r.queries).GetURLByShortCode(ctx, db.GetURLByShortCodeParams{
+		WorkspaceID: workspaceID,
+		ShortCode:   pgtype.Text{String: shortCode, Valid: true},
+		Domain:      domain,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			r.logger.Warn().Str("short_code", shortCode).Msg("URL not found by short code")
+			log.Warn().Str("short_code", shortCode).Msg("URL not found by short code")
 			return nil, repo.ErrNotFound
 		}
-		r.logger.Error().Err(err).Str("short_code", shortCode).Msg("Failed to get URL by short code")
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to get URL by short code")
 		return nil, fmt.Errorf("postgres: GetURLByShortCode failed: %w", err)
 	}
 
 	return toDomainURL(dbURL), nil
 }
 
+// GetByURLHash retrieves the oldest URL of the workspace on a domain with the given destination hash.
+func (r *URLRepository) GetByURLHash(ctx context.Context, workspaceID int64, domain string, hash []byte) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	dbURL, err := queriesFrom(ctx, r.queries).GetURLByHash(ctx, db.GetURLByHashParams{
+		WorkspaceID: workspaceID,
+		UrlHash:     hash,
+		Domain:      domain,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Msg("Failed to get URL by hash")
+		return nil, fmt.Errorf("postgres: GetURLByHash failed: %w", err)
+	}
+
+	return toDomainURL(dbURL), nil
+}
+
+// Resolve retrieves a single URL from the database by its short code on a domain in any workspace.
+func (r *URLRepository) Resolve(ctx context.Context, domain, shortCode string) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	dbURL, err := queriesFrom(ctx, r.queries).ResolveURLByShortCode(ctx, db.ResolveURLByShortCodeParams{
+		Domain:    domain,
+		ShortCode: pgtype.Text{String: shortCode, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Warn().Str("domain", domain).Str("short_code", shortCode).Msg("URL not found by short code")
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Str("domain", domain).Str("short_code", shortCode).Msg("Failed to resolve URL by short code")
+		return nil, fmt.Errorf("postgres: ResolveURLByShortCode failed: %w", err)
+	}
+
+	return toDomainURL(dbURL), nil
+}
+
+// List retrieves a page of the workspace's URLs using keyset pagination on the sort column and id.
+func (r *URLRepository) List(ctx context.Context, workspaceID int64, filter model.LinkFilter) ([]model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	var (
+		fromTime, toTime pgtype.Timestamptz
+		domain, search   pgtype.Text
+		tag              pgtype.Text
+		folderID         pgtype.Int8
+		suspicious       pgtype.Bool
+	)
+	if !filter.From.IsZero() {
+		fromTime = pgtype.Timestamptz{Time: filter.From, Valid: true}
+	}
+	if !filter.To.IsZero() {
+		toTime = pgtype.Timestamptz{Time: filter.To, Valid: true}
+	}
+	if filter.Domain != "" {
+		domain = pgtype.Text{String: strings.ToLower(filter.Domain), Valid: true}
+	}
+	if filter.Search != "" {
+		search = pgtype.Text{String: escapeLike(filter.Search), Valid: true}
+	}
+	if filter.Tag != "" {
+		tag = pgtype.Text{String: filter.Tag, Valid: true}
+	}
+	if filter.FolderID != nil {
+		folderID = pgtype.Int8{Int64: *filter.FolderID, Valid: true}
+	}
+	switch filter.Status {
+	case model.LinkStatusActive:
+		suspicious = pgtype.Bool{Bool: false, Valid: true}
+	case model.LinkStatusSuspicious:
+		suspicious = pgtype.Bool{Bool: true, Valid: true}
+	}
+
+	var (
+		rows []db.Url
+		err  error
+	)
+	if filter.SortBy == model.LinkSortClickCount {
+		params := db.ListURLsByClickCountParams{
+			WorkspaceID: workspaceID,
+			FromTime:    fromTime,
+			ToTime:      toTime,
+			Domain:      domain,
+			Search:      search,
+			FolderID:    folderID,
+			Tag:         tag,
+			Suspicious:  suspicious,
+			PageSize:    int32(filter.Limit),
+		}
+		if filter.After != nil {
+			params.CursorClickCount = pgtype.Int8{Int64: filter.After.ClickCount, Valid: true}
+			params.CursorID = pgtype.Int8{Int64: filter.After.ID, Valid: true}
+		}
+		rows, err = queriesFrom(ctx, r.queries).ListURLsByClickCount(ctx, params)
+	} else {
+		params := db.ListURLsByCreatedAtParams{
+			WorkspaceID: workspaceID,
+			FromTime:    fromTime,
+			ToTime:      toTime,
+			Domain:      domain,
+			Search:      search,
+			FolderID:    folderID,
+			Tag:         tag,
+			Suspicious:  suspicious,
+			PageSize:    int32(filter.Limit),
+		}
+		if filter.After != nil {
+			params.CursorCreatedAt = pgtype.Timestamptz{Time: filter.After.CreatedAt, Valid: true}
+			params.CursorID = pgtype.Int8{Int64: filter.After.ID, Valid: true}
+		}
+		rows, err = queriesFrom(ctx, r.queries).ListURLsByCreatedAt(ctx, params)
+	}
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Msg("Failed to list URLs")
+		return nil, fmt.Errorf("postgres: ListURLs failed: %w", err)
+	}
+
+	urls := make([]model.URL, len(rows))
+	for i, row := range rows {
+		urls[i] = *toDomainURL(row)
+	}
+	return urls, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s, so it is matched literally.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Update changes the destination and details of a URL identified by its short code on a domain.
+func (r *URLRepository) Update(ctx context.Context, workspaceID int64, domain, shortCode string, update model.LinkUpdate) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.UpdateURLParams{
+		WorkspaceID: workspaceID,
+		ShortCode:   pgtype.Text{String: shortCode, Valid: true},
+		Domain:      domain,
+	}
+	if update.OriginalURL != nil {
+		params.OriginalUrl = pgtype.Text{String: *update.OriginalURL, Valid: true}
+		params.UrlHash = update.URLHash
+	}
+	if update.Title != nil {
+		params.Title = pgtype.Text{String: *update.Title, Valid: true}
+	}
+	if update.Description != nil {
+		params.Description = pgtype.Text{String: *update.Description, Valid: true}
+	}
+	if update.Metadata != nil {
+		params.SetMetadata = true
+		if string(update.Metadata) != "null" {
+			params.Metadata = update.Metadata
+		}
+	}
+	if update.ExpiresAt != nil {
+		params.SetExpiresAt = true
+		if !update.ExpiresAt.IsZero() {
+			params.ExpiresAt = toTimestamptz(update.ExpiresAt)
+		}
+	}
+	if update.RedirectStatus != nil {
+		params.SetRedirectStatus = true
+		params.RedirectStatus = pgtype.Int2{
+			Int16: int16(*update.RedirectStatus),
+			Valid: *update.RedirectStatus != 0,
+		}
+	}
+
+	dbURL, err := queriesFrom(ctx, r.queries).UpdateURL(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to update URL")
+		return nil, fmt.Errorf("postgres: UpdateURL failed: %w", err)
+	}
+
+	return toDomainURL(dbURL), nil
+}
+
+// SetSuspicious flags or unflags a URL on a domain as suspicious in any workspace.
+func (r *URLRepository) SetSuspicious(ctx context.Context, domain, shortCode string, suspicious bool) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	dbURL, err := queriesFrom(ctx, r.queries).SetURLSuspicious(ctx, db.SetURLSuspiciousParams{
+		Domain:     domain,
+		ShortCode:  pgtype.Text{String: shortCode, Valid: true},
+		Suspicious: suspicious,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to flag URL")
+		return nil, fmt.Errorf("postgres: SetURLSuspicious failed: %w", err)
+	}
+
+	return toDomainURL(dbURL), nil
+}
+
+// SetFolder moves a URL on a domain into a folder of its workspace, or out of any folder when folderID is nil.
+func (r *URLRepository) SetFolder(ctx context.Context, workspaceID int64, domain, shortCode string, folderID *int64) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.UpdateURLFolderParams{
+		WorkspaceID: workspaceID,
+		ShortCode:   pgtype.Text{String: shortCode, Valid: true},
+		Domain:      domain,
+	}
+	if folderID != nil {
+		params.FolderID = pgtype.Int8{Int64: *folderID, Valid: true}
+	}
+
+	dbURL, err := queriesFrom(ctx, r.queries).UpdateURLFolder(ctx, params)
+	if err != nil {
+		// No row means either the link or the folder does not exist in the workspace.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to move URL to folder")
+		return nil, fmt.Errorf("postgres: UpdateURLFolder failed: %w", err)
+	}
+
+	return toDomainURL(dbURL), nil
+}
+
+// Delete removes a URL by its short code on a domain; its clicks are removed by cascade.
+func (r *URLRepository) Delete(ctx context.Context, workspaceID int64, domain, shortCode string) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	dbURL, err := queriesFrom(ctx, r.queries).DeleteURLByShortCode(ctx, db.DeleteURLByShortCodeParams{
+		WorkspaceID: workspaceID,
+		ShortCode:   pgtype.Text{String: shortCode, Valid: true},
+		Domain:      domain,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to delete URL")
+		return nil, fmt.Errorf("postgres: DeleteURLByShortCode failed: %w", err)
+	}
+
+	return toDomainURL(dbURL), nil
+}
+
+// ListAfter retrieves a batch of the URLs of all workspaces in ID order, starting after afterID.
+func (r *URLRepository) ListAfter(ctx context.Context, afterID int64, limit int32) ([]model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).ListURLsAfterID(ctx, db.ListURLsAfterIDParams{
+		ID:    afterID,
+		Limit: limit,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("after_id", afterID).Msg("Failed to list URLs")
+		return nil, fmt.Errorf("postgres: ListURLsAfterID failed: %w", err)
+	}
+
+	urls := make([]model.URL, len(rows))
+	for i, row := range rows {
+		urls[i] = *toDomainURL(row)
+	}
+	return urls, nil
+}
+
+// SetURLHash replaces the destination hash of a URL in any workspace.
+func (r *URLRepository) SetURLHash(ctx context.Context, id int64, hash []byte) error {
+	log := logger.FromContext(ctx, r.logger)
+	err := queriesFrom(ctx, r.queries).SetURLHash(ctx, db.SetURLHashParams{
+		ID:      id,
+		UrlHash: hash,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to set URL hash")
+		return fmt.Errorf("postgres: SetURLHash failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkCanonical marks the oldest URL of every destination without a canonical URL as canonical.
+func (r *URLRepository) MarkCanonical(ctx context.Context) (int64, error) {
+	log := logger.FromContext(ctx, r.logger)
+	marked, err := queriesFrom(ctx, r.queries).MarkCanonicalURLs(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to mark canonical URLs")
+		return 0, fmt.Errorf("postgres: MarkCanonicalURLs failed: %w", err)
+	}
+
+	return marked, nil
+}
+
+// ClaimExpired marks a batch of URLs of all workspaces whose expiry has passed as announced.
+func (r *URLRepository) ClaimExpired(ctx context.Context, limit int32) ([]model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).ClaimExpiredURLs(ctx, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to claim expired URLs")
+		return nil, fmt.Errorf("postgres: ClaimExpiredURLs failed: %w", err)
+	}
+
+	urls := make([]model.URL, len(rows))
+	for i, row := range rows {
+		urls[i] = *toDomainURL(row)
+	}
+	return urls, nil
+}
+
 // toDomainURL converts a database model (from sqlc) to a domain model.
 func toDomainURL(dbURL db.Url) *model.URL {
 	domainModel := &model.URL{
 		ID:          dbURL.ID,
+		WorkspaceID: dbURL.WorkspaceID,
 		OriginalURL: dbURL.OriginalUrl,
 		CreatedAt:   dbURL.CreatedAt.Time,
+		ClickCount:  dbURL.ClickCount,
+		Title:       dbURL.Title,
+		Description: dbURL.Description,
+		Metadata:    dbURL.Metadata,
+		CreatedBy:   dbURL.CreatedBy,
+		URLHash:     dbURL.UrlHash,
+		Domain:      dbURL.Domain,
 	}
 
 	if dbURL.ShortCode.Valid {
 		domainModel.ShortCode = dbURL.ShortCode.String
 	}
+	if dbURL.FolderID.Valid {
+		domainModel.FolderID = &dbURL.FolderID.Int64
+	}
+	domainModel.Suspicious = dbURL.Suspicious
+	if dbURL.RedirectStatus.Valid {
+		domainModel.RedirectStatus = int(dbURL.RedirectStatus.Int16)
+	}
+	if dbURL.ExpiresAt.Valid {
+		domainModel.ExpiresAt = &dbURL.ExpiresAt.Time
+	}
 
 	return domainModel
 }
+
+// toTimestamptz converts an optional time to a nullable timestamp.
+func toTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Ensures that WebhookRepository correctly implements the repo.WebhookRepository interface at compile time.
+var _ repo.WebhookRepository = (*WebhookRepository)(nil)
+
+// WebhookRepository implements the domain.repository.WebhookRepository interface
+// using PostgreSQL as a backend.
+type WebhookRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	logger  zerolog.Logger
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository.
+func NewWebhookRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		pool:    pool,
+		queries: db.New(pool),
+		logger:  logger.With().Str("layer", "postgres_webhook_repository").Logger(),
+	}
+}
+
+// CreateSubscription persists a new webhook subscription.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.CreateWebhookSubscriptionParams{
+		WorkspaceID: sub.WorkspaceID,
+		Url:         sub.URL,
+		Secret:      sub.Secret,
+		Events:      sub.Events,
+	}
+	if sub.ClickThreshold > 0 {
+		params.ClickThreshold = pgtype.Int8{Int64: sub.ClickThreshold, Valid: true}
+	}
+
+	created, err := r.queries.CreateWebhookSubscription(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Str("url", sub.URL).Msg("Failed to create webhook subscription")
+		return nil, fmt.Errorf("postgres: CreateWebhookSubscription failed: %w", err)
+	}
+
+	return toDomainWebhookSubscription(created), nil
+}
+
+// ListSubscriptions retrieves all webhook subscriptions of a workspace.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context, workspaceID int64) ([]model.WebhookSubscription, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := r.queries.ListWebhookSubscriptions(ctx, workspaceID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list webhook subscriptions")
+		return nil, fmt.Errorf("postgres: ListWebhookSubscriptions failed: %w", err)
+	}
+
+	subs := make([]model.WebhookSubscription, len(rows))
+	for i, row := range rows {
+		subs[i] = *toDomainWebhookSubscription(row)
+	}
+	return subs, nil
+}
+
+// GetSubscription retrieves a webhook subscription of a workspace by its ID.
+func (r *WebhookRepository) GetSubscription(ctx context.Context, workspaceID, id int64) (*model.WebhookSubscription, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := r.queries.GetWebhookSubscription(ctx, db.GetWebhookSubscriptionParams{
+		WorkspaceID: workspaceID,
+		ID:          id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Int64("id", id).Msg("Failed to get webhook subscription")
+		return nil, fmt.Errorf("postgres: GetWebhookSubscription failed: %w", err)
+	}
+
+	return toDomainWebhookSubscription(row), nil
+}
+
+// DeleteSubscription removes a webhook subscription; its deliveries are removed by cascade.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, workspaceID, id int64) error {
+	log := logger.FromContext(ctx, r.logger)
+	affected, err := r.queries.DeleteWebhookSubscription(ctx, db.DeleteWebhookSubscriptionParams{
+		WorkspaceID: workspaceID,
+		ID:          id,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("id", id).Msg("Failed to delete webhook subscription")
+		return fmt.Errorf("postgres: DeleteWebhookSubscription failed: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+// Enqueue fans an event out into one pending delivery per interested subscription of the workspace.
+func (r *WebhookRepository) Enqueue(ctx context.Context, workspaceID int64, eventType string, payload []byte) (int64, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.EnqueueWebhookDeliveriesParams{
+		EventType:   eventType,
+		Payload:     payload,
+		WorkspaceID: workspaceID,
+	}
+	n, err := queriesFrom(ctx, r.queries).EnqueueWebhookDeliveries(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Str("event_type", eventType).Msg("Failed to enqueue webhook deliveries")
+		return 0, fmt.Errorf("postgres: EnqueueWebhookDeliveries failed: %w", err)
+	}
+	return n, nil
+}
+
+// EnqueueClickThreshold fans a threshold event out to subscriptions of the workspace whose threshold equals clickCount.
+func (r *WebhookRepository) EnqueueClickThreshold(ctx context.Context, workspaceID, clickCount int64, payload []byte) (int64, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.EnqueueClickThresholdDeliveriesParams{
+		Payload:     payload,
+		WorkspaceID: workspaceID,
+		ClickCount:  clickCount,
+	}
+	n, err := r.queries.EnqueueClickThresholdDeliveries(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("click_count", clickCount).Msg("Failed to enqueue click threshold deliveries")
+		return 0, fmt.Errorf("postgres: EnqueueClickThresholdDeliveries failed: %w", err)
+	}
+	return n, nil
+}
+
+// ClaimDue leases due deliveries so that concurrent dispatchers never send the same one twice.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.ClaimDueWebhookDeliveriesParams{
+		LeaseSeconds: int32(lease / time.Second),
+		BatchSize:    int32(limit),
+	}
+	rows, err := r.queries.ClaimDueWebhookDeliveries(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to claim webhook deliveries")
+		return nil, fmt.Errorf("postgres: ClaimDueWebhookDeliveries failed: %w", err)
+	}
+
+	deliveries := make([]model.WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = model.WebhookDelivery{
+			ID:             row.ID,
+			SubscriptionID: row.SubscriptionID,
+			EventType:      row.EventType,
+			Payload:        row.Payload,
+			Status:         model.DeliveryPending,
+			Attempts:       int(row.Attempts),
+			CreatedAt:      row.CreatedAt.Time,
+			TargetURL:      row.Url,
+			Secret:         row.Secret,
+		}
+	}
+	return deliveries, nil
+}
+
+// MarkSucceeded records a successful delivery attempt.
+func (r *WebhookRepository) MarkSucceeded(ctx context.Context, id int64, statusCode int) error {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.MarkWebhookDeliverySucceededParams{
+		ID:             id,
+		LastStatusCode: pgtype.Int4{Int32: int32(statusCode), Valid: true},
+	}
+	if err := r.queries.MarkWebhookDeliverySucceeded(ctx, params); err != nil {
+		log.Error().Err(err).Int64("delivery_id", id).Msg("Failed to mark webhook delivery as succeeded")
+		return fmt.Errorf("postgres: MarkWebhookDeliverySucceeded failed: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed delivery attempt. A zero statusCode means no response was received.
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id int64, status string, statusCode int, lastErr string, nextAttempt time.Time) error {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.MarkWebhookDeliveryFailedParams{
+		ID:            id,
+		Status:        status,
+		LastError:     pgtype.Text{String: lastErr, Valid: true},
+		NextAttemptAt: pgtype.Timestamptz{Time: nextAttempt, Valid: true},
+	}
+	if statusCode != 0 {
+		params.LastStatusCode = pgtype.Int4{Int32: int32(statusCode), Valid: true}
+	}
+	if err := r.queries.MarkWebhookDeliveryFailed(ctx, params); err != nil {
+		log.Error().Err(err).Int64("delivery_id", id).Msg("Failed to mark webhook delivery as failed")
+		return fmt.Errorf("postgres: MarkWebhookDeliveryFailed failed: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the delivery history of a subscription, newest first.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	log := logger.FromContext(ctx, r.logger)
+	params := db.ListWebhookDeliveriesParams{
+		SubscriptionID: subscriptionID,
+		PageSize:       int32(filter.Limit),
+	}
+	if filter.Status != "" {
+		params.Status = pgtype.Text{String: filter.Status, Valid: true}
+	}
+	if filter.BeforeID > 0 {
+		params.BeforeID = pgtype.Int8{Int64: filter.BeforeID, Valid: true}
+	}
+
+	rows, err := r.queries.ListWebhookDeliveries(ctx, params)
+	if err != nil {
+		log.Error().Err(err).Int64("subscription_id", subscriptionID).Msg("Failed to list webhook deliveries")
+		return nil, fmt.Errorf("postgres: ListWebhookDeliveries failed: %w", err)
+	}
+
+	deliveries := make([]model.WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = toDomainWebhookDelivery(row)
+	}
+	return deliveries, nil
+}
+
+// --- Mapper Functions ---
+
+func toDomainWebhookSubscription(row db.WebhookSubscription) *model.WebhookSubscription {
+	return &model.WebhookSubscription{
+		ID:             row.ID,
+		WorkspaceID:    row.WorkspaceID,
+		URL:            row.Url,
+		Secret:         row.Secret,
+		Events:         row.Events,
+		ClickThreshold: row.ClickThreshold.Int64,
+		Active:         row.Active,
+		CreatedAt:      row.CreatedAt.Time,
+	}
+}
+
+func toDomainWebhookDelivery(row db.WebhookDelivery) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:             row.ID,
+		SubscriptionID: row.SubscriptionID,
+		EventType:      row.EventType,
+		Payload:        row.Payload,
+		Status:         row.Status,
+		Attempts:       int(row.Attempts),
+		NextAttemptAt:  row.NextAttemptAt.Time,
+		LastStatusCode: int(row.LastStatusCode.Int32),
+		LastError:      row.LastError.String,
+		CreatedAt:      row.CreatedAt.Time,
+		UpdatedAt:      row.UpdatedAt.Time,
+	}
+}
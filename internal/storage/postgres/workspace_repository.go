@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/internal/storage/postgres/db"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+// Ensures that WorkspaceRepository correctly implements the repo.WorkspaceRepository interface at compile time.
+var _ repo.WorkspaceRepository = (*WorkspaceRepository)(nil)
+
+// WorkspaceRepository implements the domain.repository.WorkspaceRepository interface
+// using PostgreSQL as a backend.
+type WorkspaceRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	logger  zerolog.Logger
+}
+
+// NewWorkspaceRepository creates a new instance of WorkspaceRepository.
+func NewWorkspaceRepository(pool *pgxpool.Pool, logger *zerolog.Logger) *WorkspaceRepository {
+	return &WorkspaceRepository{
+		pool:    pool,
+		queries: db.New(pool),
+		logger:  logger.With().Str("layer", "postgres_workspace_repository").Logger(),
+	}
+}
+
+// CreateWorkspace persists a new workspace.
+func (r *WorkspaceRepository) CreateWorkspace(ctx context.Context, name string) (*model.Workspace, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).CreateWorkspace(ctx, name)
+	if err != nil {
+		log.Error().Err(err).Str("name", name).Msg("Failed to create workspace")
+		return nil, fmt.Errorf("postgres: CreateWorkspace failed: %w", err)
+	}
+
+	return toDomainWorkspace(row), nil
+}
+
+// ListWorkspaces retrieves all workspaces.
+func (r *WorkspaceRepository) ListWorkspaces(ctx context.Context) ([]model.Workspace, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).ListWorkspaces(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list workspaces")
+		return nil, fmt.Errorf("postgres: ListWorkspaces failed: %w", err)
+	}
+
+	workspaces := make([]model.Workspace, len(rows))
+	for i, row := range rows {
+		workspaces[i] = *toDomainWorkspace(row)
+	}
+	return workspaces, nil
+}
+
+// GetWorkspace retrieves a workspace by its ID.
+func (r *WorkspaceRepository) GetWorkspace(ctx context.Context, id int64) (*model.Workspace, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).GetWorkspace(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Int64("workspace_id", id).Msg("Failed to get workspace")
+		return nil, fmt.Errorf("postgres: GetWorkspace failed: %w", err)
+	}
+
+	return toDomainWorkspace(row), nil
+}
+
+// SetReuseExistingLinks changes whether creating a link in the workspace reuses an existing one.
+func (r *WorkspaceRepository) SetReuseExistingLinks(ctx context.Context, id int64, reuse bool) error {
+	log := logger.FromContext(ctx, r.logger)
+	affected, err := queriesFrom(ctx, r.queries).UpdateWorkspaceReuseExistingLinks(ctx, db.UpdateWorkspaceReuseExistingLinksParams{
+		ID:                 id,
+		ReuseExistingLinks: reuse,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", id).Msg("Failed to update workspace settings")
+		return fmt.Errorf("postgres: UpdateWorkspaceReuseExistingLinks failed: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+// AddDomain registers a host for a workspace. An unknown workspace yields repo.ErrNotFound
+// and a host registered already, by any workspace, repo.ErrDuplicateRecord.
+func (r *WorkspaceRepository) AddDomain(ctx context.Context, workspaceID int64, host string) (*model.Domain, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).AddWorkspaceDomain(ctx, db.AddWorkspaceDomainParams{
+		WorkspaceID: workspaceID,
+		Host:        host,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.ForeignKeyViolation:
+				return nil, repo.ErrNotFound
+			case pgerrcode.UniqueViolation:
+				return nil, repo.ErrDuplicateRecord
+			}
+		}
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Str("host", host).Msg("Failed to add workspace domain")
+		return nil, fmt.Errorf("postgres: AddWorkspaceDomain failed: %w", err)
+	}
+
+	return toDomainDomain(row), nil
+}
+
+// GetDomain retrieves the registration of a host. Unregistered hosts yield repo.ErrNotFound.
+func (r *WorkspaceRepository) GetDomain(ctx context.Context, host string) (*model.Domain, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).GetWorkspaceDomain(ctx, host)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repo.ErrNotFound
+		}
+		log.Error().Err(err).Str("host", host).Msg("Failed to get workspace domain")
+		return nil, fmt.Errorf("postgres: GetWorkspaceDomain failed: %w", err)
+	}
+
+	return toDomainDomain(row), nil
+}
+
+// ListDomains retrieves the hosts registered for a workspace, ordered by host.
+func (r *WorkspaceRepository) ListDomains(ctx context.Context, workspaceID int64) ([]model.Domain, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).ListWorkspaceDomains(ctx, workspaceID)
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Msg("Failed to list workspace domains")
+		return nil, fmt.Errorf("postgres: ListWorkspaceDomains failed: %w", err)
+	}
+
+	domains := make([]model.Domain, len(rows))
+	for i, row := range rows {
+		domains[i] = *toDomainDomain(row)
+	}
+	return domains, nil
+}
+
+// RemoveDomain removes a host from a workspace. Hosts not registered for it yield repo.ErrNotFound.
+func (r *WorkspaceRepository) RemoveDomain(ctx context.Context, workspaceID int64, host string) error {
+	log := logger.FromContext(ctx, r.logger)
+	affected, err := queriesFrom(ctx, r.queries).DeleteWorkspaceDomain(ctx, db.DeleteWorkspaceDomainParams{
+		WorkspaceID: workspaceID,
+		Host:        host,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Str("host", host).Msg("Failed to remove workspace domain")
+		return fmt.Errorf("postgres: DeleteWorkspaceDomain failed: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+	return nil
+}
+
+// CreateUser persists a new user. A taken email yields repo.ErrDuplicateRecord.
+func (r *WorkspaceRepository) CreateUser(ctx context.Context, email, name string) (*model.User, error) {
+	log := logger.FromContext(ctx, r.logger)
+	row, err := queriesFrom(ctx, r.queries).CreateUser(ctx, db.CreateUserParams{Email: email, Name: name})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return nil, repo.ErrDuplicateRecord
+		}
+		log.Error().Err(err).Msg("Failed to create user")
+		return nil, fmt.Errorf("postgres: CreateUser failed: %w", err)
+	}
+
+	return toDomainUser(row), nil
+}
+
+// AddMember adds a user to a workspace with a role. An unknown workspace or user yields repo.ErrNotFound
+// and an existing membership repo.ErrDuplicateRecord.
+func (r *WorkspaceRepository) AddMember(ctx context.Context, workspaceID, userID int64, role string) error {
+	log := logger.FromContext(ctx, r.logger)
+	err := queriesFrom(ctx, r.queries).AddWorkspaceMember(ctx, db.AddWorkspaceMemberParams{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.ForeignKeyViolation:
+				return repo.ErrNotFound
+			case pgerrcode.UniqueViolation:
+				return repo.ErrDuplicateRecord
+			}
+		}
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Int64("user_id", userID).Msg("Failed to add workspace member")
+		return fmt.Errorf("postgres: AddWorkspaceMember failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetMemberRole retrieves the role of a user in a workspace. Non-members yield repo.ErrNotFound.
+func (r *WorkspaceRepository) GetMemberRole(ctx context.Context, workspaceID, userID int64) (string, error) {
+	log := logger.FromContext(ctx, r.logger)
+	role, err := queriesFrom(ctx, r.queries).GetWorkspaceMemberRole(ctx, db.GetWorkspaceMemberRoleParams{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", repo.ErrNotFound
+		}
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Int64("user_id", userID).Msg("Failed to get member role")
+		return "", fmt.Errorf("postgres: GetWorkspaceMemberRole failed: %w", err)
+	}
+
+	return role, nil
+}
+
+// UpdateMemberRole changes the role of a member. Non-members yield repo.ErrNotFound.
+func (r *WorkspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID, userID int64, role string) error {
+	log := logger.FromContext(ctx, r.logger)
+	affected, err := queriesFrom(ctx, r.queries).UpdateWorkspaceMemberRole(ctx, db.UpdateWorkspaceMemberRoleParams{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Int64("user_id", userID).Msg("Failed to update member role")
+		return fmt.Errorf("postgres: UpdateWorkspaceMemberRole failed: %w", err)
+	}
+	if affected == 0 {
+		return repo.ErrNotFound
+	}
+
+	return nil
+}
+
+// ListMembers retrieves the members of a workspace with their roles.
+func (r *WorkspaceRepository) ListMembers(ctx context.Context, workspaceID int64) ([]model.Member, error) {
+	log := logger.FromContext(ctx, r.logger)
+	rows, err := queriesFrom(ctx, r.queries).ListWorkspaceMembers(ctx, workspaceID)
+	if err != nil {
+		log.Error().Err(err).Int64("workspace_id", workspaceID).Msg("Failed to list workspace members")
+		return nil, fmt.Errorf("postgres: ListWorkspaceMembers failed: %w", err)
+	}
+
+	members := make([]model.Member, len(rows))
+	for i, row := range rows {
+		members[i] = model.Member{
+			User: model.User{
+				ID:        row.ID,
+				Email:     row.Email,
+				Name:      row.Name,
+				CreatedAt: row.CreatedAt.Time,
+			},
+			Role: row.Role,
+		}
+	}
+	return members, nil
+}
+
+func toDomainUser(row db.User) *model.User {
+	return &model.User{
+		ID:        row.ID,
+		Email:     row.Email,
+		Name:      row.Name,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+func toDomainWorkspace(row db.Workspace) *model.Workspace {
+	return &model.Workspace{
+		ID:                 row.ID,
+		Name:               row.Name,
+		CreatedAt:          row.CreatedAt.Time,
+		ReuseExistingLinks: row.ReuseExistingLinks,
+	}
+}
+
+func toDomainDomain(row db.WorkspaceDomain) *model.Domain {
+	return &model.Domain{
+		ID:          row.ID,
+		WorkspaceID: row.WorkspaceID,
+		Host:        row.Host,
+		CreatedAt:   row.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,228 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/pkg/keybuilder"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ensures that ClickStream correctly implements the repo.ClickStream interface at compile time.
+var _ repo.ClickStream = (*ClickStream)(nil)
+
+// ClickStream implements the domain.repository.ClickStream interface using Redis.
+// Events are published over pub/sub for live delivery and appended to a capped
+// Redis stream per link, whose entry IDs double as SSE event IDs for resume.
+type ClickStream struct {
+	redis      *goredis.Client
+	bufferSize int
+	replaySize int64
+	replayTTL  time.Duration
+	metrics    *Metrics
+	logger     zerolog.Logger
+}
+
+// NewClickStream creates a new instance of ClickStream.
+func NewClickStream(logger *zerolog.Logger, redis *goredis.Client, cfg *config.Config, metrics *Metrics) *ClickStream {
+	return &ClickStream{
+		redis:      redis,
+		bufferSize: cfg.Live.BufferSize,
+		replaySize: cfg.Live.ReplaySize,
+		replayTTL:  cfg.Live.ReplayTTL,
+		metrics:    metrics,
+		logger:     logger.With().Str("layer", "redis_click_stream").Logger(),
+	}
+}
+
+// Publish appends the event to the replay buffer and broadcasts it to all replicas.
+func (s *ClickStream) Publish(ctx context.Context, event *model.ClickEvent) error {
+	log := logger.FromContext(ctx, s.logger)
+	event.ID = ""
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal click event: %w", err)
+	}
+
+	streamKey := keybuilder.ClickStreamKey(event.Domain, event.ShortCode)
+	id, err := s.redis.XAdd(ctx, &goredis.XAddArgs{
+		Stream: streamKey,
+		MaxLen: s.replaySize,
+		Approx: true,
+		Values: map[string]any{"event": payload},
+	}).Result()
+	if err != nil {
+		log.Error().Err(err).Str("key", streamKey).Msg("Failed to append click event to stream")
+		return err
+	}
+	event.ID = id
+
+	payload, err = json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal click event: %w", err)
+	}
+
+	pipe := s.redis.Pipeline()
+	pipe.Expire(ctx, streamKey, s.replayTTL)
+	pipe.Publish(ctx, keybuilder.ClickChannelKey(event.Domain, event.ShortCode), payload)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error().Err(err).Str("short_code", event.ShortCode).Msg("Failed to publish click event")
+		return err
+	}
+
+	return nil
+}
+
+// Subscribe listens for events of a short code on domain. The subscription is established before
+// the replay buffer is read, so no event is lost between replay and live delivery.
+// Replayed events wait for the subscriber, however many there are, so a resume is complete;
+// live events that do not fit into the per-connection buffer are dropped rather than
+// blocking the Redis subscription.
+func (s *ClickStream) Subscribe(ctx context.Context, domain, shortCode, lastEventID string) (<-chan model.ClickEvent, error) {
+	log := logger.FromContext(ctx, s.logger)
+	if !validStreamID(lastEventID) {
+		lastEventID = ""
+	}
+
+	pubsub := s.redis.Subscribe(ctx, keybuilder.ClickChannelKey(domain, shortCode))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to subscribe to click events")
+		return nil, err
+	}
+
+	var replay []model.ClickEvent
+	if lastEventID != "" {
+		var err error
+		replay, err = s.readAfter(ctx, domain, shortCode, lastEventID)
+		if err != nil {
+			_ = pubsub.Close()
+			return nil, err
+		}
+	}
+
+	out := make(chan model.ClickEvent, s.bufferSize)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		lastSent, ok := sendReplay(ctx, out, replay, lastEventID)
+		if !ok {
+			return
+		}
+
+		messages := pubsub.Channel(goredis.WithChannelSize(s.bufferSize))
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event model.ClickEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Error().Err(err).Str("short_code", shortCode).Msg("Failed to unmarshal click event")
+					continue
+				}
+				if lastSent != "" && !streamIDAfter(event.ID, lastSent) {
+					continue
+				}
+				if s.offer(out, event, shortCode) {
+					lastSent = event.ID
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// readAfter returns buffered events with IDs strictly greater than lastEventID.
+func (s *ClickStream) readAfter(ctx context.Context, domain, shortCode, lastEventID string) ([]model.ClickEvent, error) {
+	log := logger.FromContext(ctx, s.logger)
+	streamKey := keybuilder.ClickStreamKey(domain, shortCode)
+	entries, err := s.redis.XRangeN(ctx, streamKey, "("+lastEventID, "+", s.replaySize).Result()
+	if err != nil {
+		log.Error().Err(err).Str("key", streamKey).Msg("Failed to read click event replay")
+		return nil, err
+	}
+
+	events := make([]model.ClickEvent, 0, len(entries))
+	for _, entry := range entries {
+		raw, ok := entry.Values["event"].(string)
+		if !ok {
+			continue
+		}
+		var event model.ClickEvent
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			continue
+		}
+		event.ID = entry.ID
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// sendReplay hands the replayed events to the subscriber, waiting for it to take each one,
+// and returns the ID of the last one. It reports false when ctx ends first.
+func sendReplay(ctx context.Context, out chan<- model.ClickEvent, replay []model.ClickEvent, lastSent string) (string, bool) {
+	for _, event := range replay {
+		select {
+		case <-ctx.Done():
+			return lastSent, false
+		case out <- event:
+			lastSent = event.ID
+		}
+	}
+	return lastSent, true
+}
+
+// offer hands an event to the subscriber without blocking and reports whether it was accepted.
+func (s *ClickStream) offer(out chan<- model.ClickEvent, event model.ClickEvent, shortCode string) bool {
+	select {
+	case out <- event:
+		return true
+	default:
+		s.metrics.liveDropped.Inc()
+		s.logger.Warn().Str("short_code", shortCode).Str("event_id", event.ID).Msg("Live subscriber is too slow, dropping click event")
+		return false
+	}
+}
+
+// streamIDAfter reports whether Redis stream ID a ("<ms>-<seq>") is greater than b.
+func streamIDAfter(a, b string) bool {
+	aMs, aSeq := splitStreamID(a)
+	bMs, bSeq := splitStreamID(b)
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+// validStreamID reports whether id has the "<ms>-<seq>" form of a Redis stream ID.
+func validStreamID(id string) bool {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	if !ok {
+		return false
+	}
+	_, msErr := strconv.ParseUint(msPart, 10, 64)
+	_, seqErr := strconv.ParseUint(seqPart, 10, 64)
+	return msErr == nil && seqErr == nil
+}
+
+// splitStreamID parses a Redis stream ID; malformed parts are treated as zero.
+func splitStreamID(id string) (uint64, uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ := strconv.ParseUint(msPart, 10, 64)
+	seq, _ := strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
+}
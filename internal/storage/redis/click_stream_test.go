@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	"testing"
+)
+
+func TestSendReplayDeliversMoreEventsThanTheBuffer(t *testing.T) {
+	replay := make([]model.ClickEvent, 100)
+	for i := range replay {
+		replay[i].ID = fmt.Sprintf("1700000000000-%d", i)
+	}
+	out := make(chan model.ClickEvent, 4)
+
+	done := make(chan string)
+	go func() {
+		lastSent, ok := sendReplay(context.Background(), out, replay, "")
+		if !ok {
+			lastSent = "cancelled"
+		}
+		close(out)
+		done <- lastSent
+	}()
+
+	var received int
+	for event := range out {
+		if event.ID != replay[received].ID {
+			t.Fatalf("event %d = %s, want %s", received, event.ID, replay[received].ID)
+		}
+		received++
+	}
+	if lastSent := <-done; lastSent != replay[len(replay)-1].ID {
+		t.Errorf("lastSent = %s, want %s", lastSent, replay[len(replay)-1].ID)
+	}
+	if received != len(replay) {
+		t.Errorf("received %d events, want %d", received, len(replay))
+	}
+}
+
+func TestSendReplayStopsWhenTheSubscriberLeaves(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out := make(chan model.ClickEvent)
+	lastSent, ok := sendReplay(ctx, out, []model.ClickEvent{{ID: "1-0"}}, "0-1")
+	if ok || lastSent != "0-1" {
+		t.Errorf("sendReplay = (%q, %t), want (\"0-1\", false)", lastSent, ok)
+	}
+}
+
+func TestStreamIDAfter(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2-0", "1-9", true},
+		{"1-10", "1-9", true},
+		{"1-9", "1-9", false},
+		{"1-0", "2-0", false},
+	}
+	for _, tt := range tests {
+		if got := streamIDAfter(tt.a, tt.b); got != tt.want {
+			t.Errorf("streamIDAfter(%s, %s) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -2,20 +2,28 @@ package redis
 
 import (
 	"context"
+	"fmt"
 	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
 // NewClient creates and returns a new client for Redis.
+// Commands are traced through go-redis hooks.
 // It also registers lifecycle hooks with Fx for graceful shutdown.
-func NewClient(lc fx.Lifecycle, cfg *config.Config) (*goredis.Client, error) {
+func NewClient(lc fx.Lifecycle, cfg *config.Config, tp trace.TracerProvider) (*goredis.Client, error) {
 	rdb := goredis.NewClient(&goredis.Options{
 		Addr:     cfg.Redis.Addr,
 		Password: cfg.Redis.Password,
 		DB:       cfg.Redis.DB,
 	})
 
+	if err := redisotel.InstrumentTracing(rdb, redisotel.WithTracerProvider(tp)); err != nil {
+		return nil, fmt.Errorf("failed to instrument redis client: %w", err)
+	}
+
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			return rdb.Ping(ctx).Err()
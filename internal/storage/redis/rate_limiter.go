@@ -0,0 +1,116 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"strconv"
+	"time"
+)
+
+// Ensures that RateLimiter correctly implements the repo.RateLimiter interface at compile time.
+var _ repo.RateLimiter = (*RateLimiter)(nil)
+
+// gcraScript implements the generic cell rate algorithm (GCRA). The key stores the
+// theoretical arrival time (TAT) of the next request; a request is allowed if it does
+// not push the TAT further than the burst allowance ahead of now. Time is read from the
+// Redis server so that replicas with skewed clocks share a single timeline.
+//
+// KEYS[1] - limiter key; ARGV[1] - burst; ARGV[2] - rate; ARGV[3] - period in seconds.
+// Returns {allowed, remaining, retry_after, reset_after}, durations in seconds.
+var gcraScript = goredis.NewScript(`
+local key = KEYS[1]
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+
+local emission_interval = period / rate
+local burst_offset = emission_interval * burst
+
+local now = redis.call("TIME")
+now = tonumber(now[1]) + tonumber(now[2]) / 1000000
+
+local tat = redis.call("GET", key)
+if not tat then
+  tat = now
+else
+  tat = math.max(tonumber(tat), now)
+end
+
+local new_tat = tat + emission_interval
+local allow_at = new_tat - burst_offset
+local diff = now - allow_at
+local remaining = math.floor(diff / emission_interval)
+
+if remaining < 0 then
+  return {0, 0, tostring(-diff), tostring(tat - now)}
+end
+
+local reset_after = new_tat - now
+redis.call("SET", key, tostring(new_tat), "EX", math.ceil(reset_after))
+return {1, remaining, "0", tostring(reset_after)}
+`)
+
+// RateLimiter implements the domain.repository.RateLimiter interface using Redis.
+// Each check is a single atomic script execution, so concurrent replicas cannot overshoot a limit.
+type RateLimiter struct {
+	redis  *goredis.Client
+	logger zerolog.Logger
+}
+
+// NewRateLimiter creates a new instance of RateLimiter.
+func NewRateLimiter(logger *zerolog.Logger, redis *goredis.Client) *RateLimiter {
+	return &RateLimiter{
+		redis:  redis,
+		logger: logger.With().Str("layer", "redis_rate_limiter").Logger(),
+	}
+}
+
+// Allow records a request against key and reports whether it is within the limit.
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit model.RateLimit) (model.RateLimitResult, error) {
+	burst := max(limit.Burst, 1)
+	values, err := gcraScript.Run(ctx, l.redis, []string{key}, burst, limit.Rate, limit.Period.Seconds()).Slice()
+	if err != nil {
+		return model.RateLimitResult{}, fmt.Errorf("redis: rate limit script failed: %w", err)
+	}
+	if len(values) != 4 {
+		return model.RateLimitResult{}, fmt.Errorf("redis: unexpected rate limit script result %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retryAfter, err := parseSeconds(values[2])
+	if err != nil {
+		return model.RateLimitResult{}, err
+	}
+	resetAfter, err := parseSeconds(values[3])
+	if err != nil {
+		return model.RateLimitResult{}, err
+	}
+
+	return model.RateLimitResult{
+		Allowed:    allowed == 1,
+		Limit:      limit.Rate,
+		Period:     limit.Period,
+		Burst:      burst,
+		Remaining:  int(remaining),
+		RetryAfter: retryAfter,
+		ResetAfter: resetAfter,
+	}, nil
+}
+
+// parseSeconds converts a script duration, returned as a string to keep its fraction, to a time.Duration.
+func parseSeconds(v any) (time.Duration, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected rate limit duration %v", v)
+	}
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("redis: invalid rate limit duration %q: %w", s, err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/ilindan-dev/shortener/internal/domain/model"
 	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
 	"github.com/rs/zerolog"
 	"time"
 )
@@ -16,6 +17,7 @@ var _ repo.URLRepository = (*CachedURLRepository)(nil)
 type CachedURLRepository struct {
 	primaryRepo repo.URLRepository
 	cache       repo.URLCache
+	metrics     *Metrics
 	logger      zerolog.Logger
 	ttl         time.Duration
 }
@@ -24,50 +26,156 @@ type CachedURLRepository struct {
 func NewCachedURLRepository(
 	primaryRepo repo.URLRepository,
 	cache repo.URLCache,
+	metrics *Metrics,
 	logger *zerolog.Logger,
 ) *CachedURLRepository {
 	return &CachedURLRepository{
 		primaryRepo: primaryRepo,
 		cache:       cache,
+		metrics:     metrics,
 		logger:      logger.With().Str("layer", "cached_repository").Logger(),
 		ttl:         time.Hour * 24 * 7,
 	}
 }
 
-// Create first persists the URL in the primary repository, then warms up the cache.
-func (r *CachedURLRepository) Create(ctx context.Context, originalURL string) (*model.URL, error) {
-	return r.primaryRepo.Create(ctx, originalURL)
+// Create persists the URL in the primary repository; the caller warms the cache once its
+// short code is committed.
+func (r *CachedURLRepository) Create(ctx context.Context, url *model.URL) (*model.URL, error) {
+	return r.primaryRepo.Create(ctx, url)
 }
 
-// UpdateShortCode updates the primary repository and then warms up the cache.
-func (r *CachedURLRepository) UpdateShortCode(ctx context.Context, id int64, shortCode string) error {
-	if err := r.primaryRepo.UpdateShortCode(ctx, id, shortCode); err != nil {
-		return err
+// CreateCanonical persists the URL in the primary repository like Create.
+func (r *CachedURLRepository) CreateCanonical(ctx context.Context, url *model.URL) (*model.URL, error) {
+	return r.primaryRepo.CreateCanonical(ctx, url)
+}
+
+// UpdateShortCode updates the primary repository. New links have no cache entry yet, so
+// there is nothing to evict.
+func (r *CachedURLRepository) UpdateShortCode(ctx context.Context, workspaceID, id int64, shortCode string) error {
+	return r.primaryRepo.UpdateShortCode(ctx, workspaceID, id, shortCode)
+}
+
+// Update updates the primary repository and then evicts the stale cache entry once the change is committed.
+func (r *CachedURLRepository) Update(ctx context.Context, workspaceID int64, domain, shortCode string, update model.LinkUpdate) (*model.URL, error) {
+	url, err := r.primaryRepo.Update(ctx, workspaceID, domain, shortCode, update)
+	if err != nil {
+		return nil, err
+	}
+	r.evict(ctx, url)
+	return url, nil
+}
+
+// SetFolder updates the primary repository and then evicts the stale cache entry once the change is committed.
+func (r *CachedURLRepository) SetFolder(ctx context.Context, workspaceID int64, domain, shortCode string, folderID *int64) (*model.URL, error) {
+	url, err := r.primaryRepo.SetFolder(ctx, workspaceID, domain, shortCode, folderID)
+	if err != nil {
+		return nil, err
+	}
+	r.evict(ctx, url)
+	return url, nil
+}
+
+// SetSuspicious updates the primary repository and then evicts the stale cache entry once the change is committed.
+func (r *CachedURLRepository) SetSuspicious(ctx context.Context, domain, shortCode string, suspicious bool) (*model.URL, error) {
+	url, err := r.primaryRepo.SetSuspicious(ctx, domain, shortCode, suspicious)
+	if err != nil {
+		return nil, err
+	}
+	r.evict(ctx, url)
+	return url, nil
+}
+
+// Delete removes the URL from the primary repository and then evicts it from the cache once the deletion is committed.
+func (r *CachedURLRepository) Delete(ctx context.Context, workspaceID int64, domain, shortCode string) (*model.URL, error) {
+	url, err := r.primaryRepo.Delete(ctx, workspaceID, domain, shortCode)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	r.evict(ctx, url)
+	return url, nil
+}
+
+// GetByURLHash reads straight from the primary repository; hash lookups are not cached.
+func (r *CachedURLRepository) GetByURLHash(ctx context.Context, workspaceID int64, domain string, hash []byte) (*model.URL, error) {
+	return r.primaryRepo.GetByURLHash(ctx, workspaceID, domain, hash)
+}
+
+// List reads straight from the primary repository; listings are not cached.
+func (r *CachedURLRepository) List(ctx context.Context, workspaceID int64, filter model.LinkFilter) ([]model.URL, error) {
+	return r.primaryRepo.List(ctx, workspaceID, filter)
+}
+
+// ListAfter reads straight from the primary repository; listings are not cached.
+func (r *CachedURLRepository) ListAfter(ctx context.Context, afterID int64, limit int32) ([]model.URL, error) {
+	return r.primaryRepo.ListAfter(ctx, afterID, limit)
+}
+
+// SetURLHash updates the primary repository. Redirects do not use the hash, so cached
+// entries stay valid.
+func (r *CachedURLRepository) SetURLHash(ctx context.Context, id int64, hash []byte) error {
+	return r.primaryRepo.SetURLHash(ctx, id, hash)
 }
 
-// GetByShortCode implements the cache-aside pattern.
-func (r *CachedURLRepository) GetByShortCode(ctx context.Context, shortCode string) (*model.URL, error) {
-	cachedURL, err := r.cache.Get(ctx, shortCode)
+// MarkCanonical updates the primary repository; redirects do not depend on it either.
+func (r *CachedURLRepository) MarkCanonical(ctx context.Context) (int64, error) {
+	return r.primaryRepo.MarkCanonical(ctx)
+}
+
+// ClaimExpired updates the primary repository. Cached entries carry the expiry, so redirects
+// stop at it without an eviction.
+func (r *CachedURLRepository) ClaimExpired(ctx context.Context, limit int32) ([]model.URL, error) {
+	return r.primaryRepo.ClaimExpired(ctx, limit)
+}
+
+// evict removes the cache entry of url once the transaction carried by ctx has committed.
+// Evicting earlier would let a concurrent Resolve cache the committed old row again for the
+// full TTL. Failures are logged because the entry still expires with its TTL.
+func (r *CachedURLRepository) evict(ctx context.Context, url *model.URL) {
+	repo.AfterCommit(ctx, func() {
+		log := logger.FromContext(ctx, r.logger)
+		if err := r.cache.Delete(ctx, url.Domain, url.ShortCode); err != nil {
+			r.metrics.cacheResults.WithLabelValues("cached_repository", cacheError).Inc()
+			log.Error().Err(err).Str("domain", url.Domain).Str("short_code", url.ShortCode).Msg("Failed to evict URL from cache")
+		}
+	})
+}
+
+// GetByShortCode reads straight from the primary repository. Cached entries serve the public
+// redirect only and are not checked against the workspace.
+func (r *CachedURLRepository) GetByShortCode(ctx context.Context, workspaceID int64, domain, shortCode string) (*model.URL, error) {
+	return r.primaryRepo.GetByShortCode(ctx, workspaceID, domain, shortCode)
+}
+
+// Resolve implements the cache-aside pattern.
+func (r *CachedURLRepository) Resolve(ctx context.Context, domain, shortCode string) (*model.URL, error) {
+	log := logger.FromContext(ctx, r.logger)
+	cachedURL, err := r.cache.Get(ctx, domain, shortCode)
+	// Entries cached before links had a workspace are treated as misses.
+	if err == nil && cachedURL.WorkspaceID == 0 {
+		err = repo.ErrNotFound
+	}
 	if err == nil {
-		r.logger.Info().Str("short_code", shortCode).Msg("Cache hit")
+		r.metrics.cacheResults.WithLabelValues("cached_repository", cacheHit).Inc()
+		log.Info().Str("short_code", shortCode).Msg("Cache hit")
 		return cachedURL, nil
 	}
 
 	if !errors.Is(err, repo.ErrNotFound) {
-		r.logger.Error().Err(err).Str("short_code", shortCode).Msg("Cache get error, falling back to primary repository")
+		r.metrics.cacheResults.WithLabelValues("cached_repository", cacheError).Inc()
+		log.Error().Err(err).Str("short_code", shortCode).Msg("Cache get error, falling back to primary repository")
 	} else {
-		r.logger.Info().Str("short_code", shortCode).Msg("Cache miss")
+		r.metrics.cacheResults.WithLabelValues("cached_repository", cacheMiss).Inc()
+		log.Info().Str("short_code", shortCode).Msg("Cache miss")
 	}
 
-	dbURL, err := r.primaryRepo.GetByShortCode(ctx, shortCode)
+	dbURL, err := r.primaryRepo.Resolve(ctx, domain, shortCode)
 	if err != nil {
 		return nil, err
 	}
 
 	if err := r.cache.Set(ctx, dbURL, r.ttl); err != nil {
-		r.logger.Error().Err(err).Str("short_code", dbURL.ShortCode).Msg("Failed to set cache after DB fetch")
+		r.metrics.cacheResults.WithLabelValues("cached_repository", cacheError).Inc()
+		log.Error().Err(err).Str("short_code", dbURL.ShortCode).Msg("Failed to set cache after DB fetch")
 	}
 
 	return dbURL, nil
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/rs/zerolog"
+	"testing"
+	"time"
+)
+
+// fakePrimary deletes every link it is asked to; other methods are not used.
+type fakePrimary struct {
+	repo.URLRepository
+}
+
+func (fakePrimary) Delete(_ context.Context, workspaceID int64, domain, shortCode string) (*model.URL, error) {
+	return &model.URL{WorkspaceID: workspaceID, Domain: domain, ShortCode: shortCode}, nil
+}
+
+// fakeCache records the domains and short codes deleted from it.
+type fakeCache struct {
+	deleted []string
+}
+
+func (c *fakeCache) Get(context.Context, string, string) (*model.URL, error) {
+	return nil, repo.ErrNotFound
+}
+
+func (c *fakeCache) Set(context.Context, *model.URL, time.Duration) error { return nil }
+
+func (c *fakeCache) Delete(_ context.Context, domain, shortCode string) error {
+	c.deleted = append(c.deleted, domain+"/"+shortCode)
+	return nil
+}
+
+func TestCachedURLRepositoryEvictsAfterCommit(t *testing.T) {
+	cache := &fakeCache{}
+	logger := zerolog.Nop()
+	r := NewCachedURLRepository(fakePrimary{}, cache, NewMetrics(), &logger)
+
+	txCtx, commit := repo.WithCommitHooks(context.Background())
+	if _, err := r.Delete(txCtx, 1, "b.link", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.deleted) != 0 {
+		t.Fatalf("evicted %v before the commit", cache.deleted)
+	}
+	commit()
+	if len(cache.deleted) != 1 || cache.deleted[0] != "b.link/abc" {
+		t.Fatalf("evicted %v after the commit, want [b.link/abc]", cache.deleted)
+	}
+}
+
+func TestCachedURLRepositoryEvictsAtOnceOutsideTransaction(t *testing.T) {
+	cache := &fakeCache{}
+	logger := zerolog.Nop()
+	r := NewCachedURLRepository(fakePrimary{}, cache, NewMetrics(), &logger)
+
+	if _, err := r.Delete(context.Background(), 1, "", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.deleted) != 1 || cache.deleted[0] != "/abc" {
+		t.Fatalf("evicted %v, want [/abc]", cache.deleted)
+	}
+}
+
+func TestAfterCommitSkippedOnRollback(t *testing.T) {
+	// A rolled back transaction never runs its hooks, so nothing is evicted.
+	txCtx, _ := repo.WithCommitHooks(context.Background())
+	ran := false
+	repo.AfterCommit(txCtx, func() { ran = true })
+	if ran {
+		t.Fatal("hook ran before the commit")
+	}
+}
@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	"github.com/ilindan-dev/shortener/internal/logger"
+	"github.com/ilindan-dev/shortener/pkg/keybuilder"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// Ensures that CachedWorkspaceRepository correctly implements the repo.WorkspaceRepository interface at compile time.
+var _ repo.WorkspaceRepository = (*CachedWorkspaceRepository)(nil)
+
+// unregisteredDomain is cached for hosts that are not registered, so redirects on unknown
+// hosts do not reach the database either.
+const unregisteredDomain = "-"
+
+// CachedWorkspaceRepository is a decorator for a WorkspaceRepository that caches host registrations.
+// Every redirect on a host other than the configured ones looks its registration up.
+type CachedWorkspaceRepository struct {
+	primaryRepo repo.WorkspaceRepository
+	redis       *goredis.Client
+	metrics     *Metrics
+	logger      zerolog.Logger
+	ttl         time.Duration
+}
+
+// NewCachedWorkspaceRepository creates a new instance of the cached repository decorator.
+func NewCachedWorkspaceRepository(
+	primaryRepo repo.WorkspaceRepository,
+	redis *goredis.Client,
+	cfg *config.Config,
+	metrics *Metrics,
+	logger *zerolog.Logger,
+) *CachedWorkspaceRepository {
+	return &CachedWorkspaceRepository{
+		primaryRepo: primaryRepo,
+		redis:       redis,
+		metrics:     metrics,
+		logger:      logger.With().Str("layer", "cached_workspace_repository").Logger(),
+		ttl:         cfg.HTTP.DomainCacheTTL,
+	}
+}
+
+// CreateWorkspace persists the workspace in the primary repository.
+func (r *CachedWorkspaceRepository) CreateWorkspace(ctx context.Context, name string) (*model.Workspace, error) {
+	return r.primaryRepo.CreateWorkspace(ctx, name)
+}
+
+// ListWorkspaces reads straight from the primary repository.
+func (r *CachedWorkspaceRepository) ListWorkspaces(ctx context.Context) ([]model.Workspace, error) {
+	return r.primaryRepo.ListWorkspaces(ctx)
+}
+
+// GetWorkspace reads straight from the primary repository.
+func (r *CachedWorkspaceRepository) GetWorkspace(ctx context.Context, id int64) (*model.Workspace, error) {
+	return r.primaryRepo.GetWorkspace(ctx, id)
+}
+
+// SetReuseExistingLinks updates the primary repository.
+func (r *CachedWorkspaceRepository) SetReuseExistingLinks(ctx context.Context, id int64, reuse bool) error {
+	return r.primaryRepo.SetReuseExistingLinks(ctx, id, reuse)
+}
+
+// AddDomain registers the host in the primary repository and then evicts the cached miss once
+// the change is committed.
+func (r *CachedWorkspaceRepository) AddDomain(ctx context.Context, workspaceID int64, host string) (*model.Domain, error) {
+	domain, err := r.primaryRepo.AddDomain(ctx, workspaceID, host)
+	if err != nil {
+		return nil, err
+	}
+	r.evict(ctx, host)
+	return domain, nil
+}
+
+// GetDomain implements the cache-aside pattern; hosts that are not registered are cached as well.
+func (r *CachedWorkspaceRepository) GetDomain(ctx context.Context, host string) (*model.Domain, error) {
+	log := logger.FromContext(ctx, r.logger)
+	key := keybuilder.DomainCacheKey(host)
+	val, err := r.redis.Get(ctx, key).Result()
+	switch {
+	case err == nil && val == unregisteredDomain:
+		r.metrics.cacheResults.WithLabelValues("cached_workspace_repository", cacheHit).Inc()
+		return nil, repo.ErrNotFound
+	case err == nil:
+		var domain model.Domain
+		if err := json.Unmarshal([]byte(val), &domain); err == nil {
+			r.metrics.cacheResults.WithLabelValues("cached_workspace_repository", cacheHit).Inc()
+			return &domain, nil
+		}
+		r.metrics.cacheResults.WithLabelValues("cached_workspace_repository", cacheError).Inc()
+		log.Error().Str("key", key).Msg("Failed to unmarshal domain from cache, falling back to primary repository")
+	case errors.Is(err, goredis.Nil):
+		r.metrics.cacheResults.WithLabelValues("cached_workspace_repository", cacheMiss).Inc()
+	default:
+		r.metrics.cacheResults.WithLabelValues("cached_workspace_repository", cacheError).Inc()
+		log.Error().Err(err).Str("key", key).Msg("Cache get error, falling back to primary repository")
+	}
+
+	domain, err := r.primaryRepo.GetDomain(ctx, host)
+	if err != nil && !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	val = unregisteredDomain
+	if domain != nil {
+		payload, marshalErr := json.Marshal(domain)
+		if marshalErr != nil {
+			return domain, nil
+		}
+		val = string(payload)
+	}
+	if setErr := r.redis.Set(ctx, key, val, r.ttl).Err(); setErr != nil {
+		r.metrics.cacheResults.WithLabelValues("cached_workspace_repository", cacheError).Inc()
+		log.Error().Err(setErr).Str("key", key).Msg("Failed to set domain in cache")
+	}
+	return domain, err
+}
+
+// ListDomains reads straight from the primary repository; listings are not cached.
+func (r *CachedWorkspaceRepository) ListDomains(ctx context.Context, workspaceID int64) ([]model.Domain, error) {
+	return r.primaryRepo.ListDomains(ctx, workspaceID)
+}
+
+// RemoveDomain removes the host from the primary repository and then evicts its cached
+// registration once the change is committed.
+func (r *CachedWorkspaceRepository) RemoveDomain(ctx context.Context, workspaceID int64, host string) error {
+	if err := r.primaryRepo.RemoveDomain(ctx, workspaceID, host); err != nil {
+		return err
+	}
+	r.evict(ctx, host)
+	return nil
+}
+
+// CreateUser persists the user in the primary repository.
+func (r *CachedWorkspaceRepository) CreateUser(ctx context.Context, email, name string) (*model.User, error) {
+	return r.primaryRepo.CreateUser(ctx, email, name)
+}
+
+// AddMember updates the primary repository.
+func (r *CachedWorkspaceRepository) AddMember(ctx context.Context, workspaceID, userID int64, role string) error {
+	return r.primaryRepo.AddMember(ctx, workspaceID, userID, role)
+}
+
+// GetMemberRole reads straight from the primary repository; roles are not cached.
+func (r *CachedWorkspaceRepository) GetMemberRole(ctx context.Context, workspaceID, userID int64) (string, error) {
+	return r.primaryRepo.GetMemberRole(ctx, workspaceID, userID)
+}
+
+// UpdateMemberRole updates the primary repository.
+func (r *CachedWorkspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID, userID int64, role string) error {
+	return r.primaryRepo.UpdateMemberRole(ctx, workspaceID, userID, role)
+}
+
+// ListMembers reads straight from the primary repository.
+func (r *CachedWorkspaceRepository) ListMembers(ctx context.Context, workspaceID int64) ([]model.Member, error) {
+	return r.primaryRepo.ListMembers(ctx, workspaceID)
+}
+
+// evict removes the cached registration of host once the transaction carried by ctx has
+// committed. Failures are logged because the entry still expires with its TTL.
+func (r *CachedWorkspaceRepository) evict(ctx context.Context, host string) {
+	repo.AfterCommit(ctx, func() {
+		log := logger.FromContext(ctx, r.logger)
+		if err := r.redis.Del(ctx, keybuilder.DomainCacheKey(host)).Err(); err != nil {
+			r.metrics.cacheResults.WithLabelValues("cached_workspace_repository", cacheError).Inc()
+			log.Error().Err(err).Str("host", host).Msg("Failed to evict domain from cache")
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/ilindan-dev/shortener/internal/config"
+	"github.com/ilindan-dev/shortener/internal/domain/model"
+	repo "github.com/ilindan-dev/shortener/internal/domain/repository"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"testing"
+	"time"
+)
+
+// fakeWorkspaces knows a single registered host; other methods are not used.
+type fakeWorkspaces struct {
+	repo.WorkspaceRepository
+}
+
+func (fakeWorkspaces) GetDomain(_ context.Context, host string) (*model.Domain, error) {
+	if host == "go.brand.com" {
+		return &model.Domain{WorkspaceID: 1, Host: host}, nil
+	}
+	return nil, repo.ErrNotFound
+}
+
+func TestCachedWorkspaceRepositoryFallsBackWhenRedisIsDown(t *testing.T) {
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+	logger := zerolog.Nop()
+	cfg := &config.Config{HTTP: config.HTTPConfig{DomainCacheTTL: time.Minute}}
+	r := NewCachedWorkspaceRepository(fakeWorkspaces{}, client, cfg, NewMetrics(), &logger)
+
+	domain, err := r.GetDomain(context.Background(), "go.brand.com")
+	if err != nil || domain.WorkspaceID != 1 {
+		t.Fatalf("GetDomain(go.brand.com) = %+v, %v, want workspace 1", domain, err)
+	}
+	if _, err := r.GetDomain(context.Background(), "other.com"); !errors.Is(err, repo.ErrNotFound) {
+		t.Fatalf("GetDomain(other.com) error = %v, want repo.ErrNotFound", err)
+	}
+}
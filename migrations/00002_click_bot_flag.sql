@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE clicks ADD COLUMN is_bot BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Backfill the flag for clicks recorded before bot detection existed.
+UPDATE clicks
+SET is_bot = TRUE
+WHERE user_agent ~* '(bot|crawl|spider|slurp|curl|wget|python-requests|httpclient|headless)';
+
+
+-- +goose Down
+ALTER TABLE clicks DROP COLUMN IF EXISTS is_bot;
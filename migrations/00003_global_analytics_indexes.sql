@@ -0,0 +1,11 @@
+-- +goose Up
+-- idx_clicks_created_at speeds up account-wide analytics that scan clicks by time only.
+CREATE INDEX idx_clicks_created_at ON clicks(created_at);
+
+-- idx_urls_created_at speeds up counting links created within a time window.
+CREATE INDEX idx_urls_created_at ON urls(created_at);
+
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_urls_created_at;
+DROP INDEX IF EXISTS idx_clicks_created_at;
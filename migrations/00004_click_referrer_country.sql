@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE clicks
+    ADD COLUMN referrer TEXT,
+    ADD COLUMN country VARCHAR(2);
+
+
+-- +goose Down
+ALTER TABLE clicks
+    DROP COLUMN IF EXISTS country,
+    DROP COLUMN IF EXISTS referrer;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- click_count is maintained by CreateClick so thresholds and sorting don't need to count clicks.
+ALTER TABLE urls ADD COLUMN click_count BIGINT NOT NULL DEFAULT 0;
+
+UPDATE urls
+SET click_count = counts.total
+FROM (SELECT url_id, count(*) AS total FROM clicks GROUP BY url_id) AS counts
+WHERE urls.id = counts.url_id;
+
+
+-- +goose Down
+ALTER TABLE urls DROP COLUMN IF EXISTS click_count;
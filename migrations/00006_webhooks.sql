@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE webhook_subscriptions (
+                                       id BIGSERIAL PRIMARY KEY,
+                                       url TEXT NOT NULL,
+                                       secret TEXT NOT NULL,
+                                       events TEXT[] NOT NULL,
+                                       click_threshold BIGINT,
+                                       active BOOLEAN NOT NULL DEFAULT TRUE,
+                                       created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- idx_webhook_subscriptions_click_threshold finds subscribers when a link reaches a click count.
+CREATE INDEX idx_webhook_subscriptions_click_threshold ON webhook_subscriptions(click_threshold) WHERE click_threshold IS NOT NULL;
+
+CREATE TABLE webhook_deliveries (
+                                    id BIGSERIAL PRIMARY KEY,
+                                    subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+                                    event_type TEXT NOT NULL,
+                                    payload JSONB NOT NULL,
+                                    status TEXT NOT NULL DEFAULT 'pending',
+                                    attempts INT NOT NULL DEFAULT 0,
+                                    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    last_status_code INT,
+                                    last_error TEXT,
+                                    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- idx_webhook_deliveries_due lets the dispatcher pick up pending deliveries in order.
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+-- idx_webhook_deliveries_subscription_id serves the delivery history of a subscription.
+CREATE INDEX idx_webhook_deliveries_subscription_id ON webhook_deliveries(subscription_id, id DESC);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
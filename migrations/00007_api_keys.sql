@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE api_keys (
+                          id BIGSERIAL PRIMARY KEY,
+                          name TEXT NOT NULL,
+                          prefix VARCHAR(16) NOT NULL,
+                          key_hash CHAR(64) NOT NULL UNIQUE,
+                          scope TEXT NOT NULL,
+                          request_count BIGINT NOT NULL DEFAULT 0,
+                          last_used_at TIMESTAMPTZ,
+                          created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                          revoked_at TIMESTAMPTZ
+);
+
+-- The UNIQUE constraint on key_hash provides the index used to authenticate requests.
+
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
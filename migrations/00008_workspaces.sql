@@ -0,0 +1,64 @@
+-- +goose Up
+CREATE TABLE workspaces (
+                            id BIGSERIAL PRIMARY KEY,
+                            name TEXT NOT NULL,
+                            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE users (
+                       id BIGSERIAL PRIMARY KEY,
+                       email TEXT NOT NULL UNIQUE,
+                       name TEXT NOT NULL DEFAULT '',
+                       created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE workspace_members (
+                                   workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+                                   user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                   created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                   PRIMARY KEY (workspace_id, user_id)
+);
+
+-- idx_workspace_members_user_id lists the workspaces of a user.
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- Everything created before workspaces existed belongs to the default workspace.
+INSERT INTO workspaces (id, name) VALUES (1, 'Default');
+SELECT setval('workspaces_id_seq', 1);
+
+ALTER TABLE urls ADD COLUMN workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE urls ALTER COLUMN workspace_id DROP DEFAULT;
+
+-- clicks.workspace_id is copied from the link on insert, so workspace-wide analytics don't need a join.
+ALTER TABLE clicks ADD COLUMN workspace_id BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE clicks ALTER COLUMN workspace_id DROP DEFAULT;
+
+ALTER TABLE api_keys ADD COLUMN workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE api_keys ALTER COLUMN workspace_id DROP DEFAULT;
+
+ALTER TABLE webhook_subscriptions ADD COLUMN workspace_id BIGINT NOT NULL DEFAULT 1 REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE webhook_subscriptions ALTER COLUMN workspace_id DROP DEFAULT;
+
+-- Account-wide analytics are now workspace-wide, so the time indexes lead with the workspace.
+DROP INDEX IF EXISTS idx_clicks_created_at;
+DROP INDEX IF EXISTS idx_urls_created_at;
+CREATE INDEX idx_clicks_workspace_id_created_at ON clicks(workspace_id, created_at);
+CREATE INDEX idx_urls_workspace_id_created_at ON urls(workspace_id, created_at);
+CREATE INDEX idx_webhook_subscriptions_workspace_id ON webhook_subscriptions(workspace_id);
+
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_webhook_subscriptions_workspace_id;
+DROP INDEX IF EXISTS idx_urls_workspace_id_created_at;
+DROP INDEX IF EXISTS idx_clicks_workspace_id_created_at;
+CREATE INDEX idx_clicks_created_at ON clicks(created_at);
+CREATE INDEX idx_urls_created_at ON urls(created_at);
+
+ALTER TABLE webhook_subscriptions DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE clicks DROP COLUMN IF EXISTS workspace_id;
+ALTER TABLE urls DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS workspaces;
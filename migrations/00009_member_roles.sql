@@ -0,0 +1,13 @@
+-- +goose Up
+-- Members are owners, editors or viewers of a workspace. Existing members keep full access.
+ALTER TABLE workspace_members ADD COLUMN role TEXT NOT NULL DEFAULT 'owner'
+    CHECK (role IN ('owner', 'editor', 'viewer'));
+ALTER TABLE workspace_members ALTER COLUMN role DROP DEFAULT;
+
+-- A key may act on behalf of a member and then carries the member's role.
+ALTER TABLE api_keys ADD COLUMN user_id BIGINT REFERENCES users(id) ON DELETE CASCADE;
+
+
+-- +goose Down
+ALTER TABLE api_keys DROP COLUMN IF EXISTS user_id;
+ALTER TABLE workspace_members DROP COLUMN IF EXISTS role;
@@ -0,0 +1,40 @@
+-- +goose Up
+-- audit_events has no foreign keys: the trail must outlive the links, users and keys it mentions.
+CREATE TABLE audit_events (
+                              id BIGSERIAL PRIMARY KEY,
+                              workspace_id BIGINT NOT NULL,
+                              actor TEXT NOT NULL,
+                              actor_user_id BIGINT,
+                              actor_api_key_id BIGINT,
+                              action TEXT NOT NULL,
+                              short_code TEXT,
+                              before_value JSONB,
+                              after_value JSONB,
+                              source_ip TEXT NOT NULL DEFAULT '',
+                              request_id TEXT NOT NULL DEFAULT '',
+                              created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- idx_audit_events_workspace_id_id pages through the trail of a workspace, newest first.
+CREATE INDEX idx_audit_events_workspace_id_id ON audit_events(workspace_id, id DESC);
+-- idx_audit_events_workspace_id_short_code answers "who changed this link".
+CREATE INDEX idx_audit_events_workspace_id_short_code ON audit_events(workspace_id, short_code, id DESC);
+
+-- The trail is append-only: rows can be inserted, never changed or removed.
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+
+-- +goose Down
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- url_host extracts the lower-cased host of an absolute URL, e.g. "www.example.com"
+-- for "https://user@WWW.Example.com:8443/pricing". It is IMMUTABLE so it can be indexed.
+-- +goose StatementBegin
+CREATE FUNCTION url_host(url TEXT) RETURNS TEXT AS $$
+SELECT lower(substring(url FROM '^[A-Za-z][A-Za-z0-9+.-]*://(?:[^@/?#]*@)?([^/:?#]+)'));
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+-- +goose StatementEnd
+
+-- The trigram indexes serve substring search (ILIKE '%term%') over destinations and short codes.
+CREATE INDEX idx_urls_original_url_trgm ON urls USING gin (original_url gin_trgm_ops);
+CREATE INDEX idx_urls_short_code_trgm ON urls USING gin (short_code gin_trgm_ops);
+-- idx_urls_workspace_id_host serves the destination domain filter.
+CREATE INDEX idx_urls_workspace_id_host ON urls(workspace_id, url_host(original_url));
+-- idx_urls_workspace_id_click_count serves the link listing sorted by clicks.
+CREATE INDEX idx_urls_workspace_id_click_count ON urls(workspace_id, click_count DESC, id DESC);
+
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_urls_workspace_id_click_count;
+DROP INDEX IF EXISTS idx_urls_workspace_id_host;
+DROP INDEX IF EXISTS idx_urls_short_code_trgm;
+DROP INDEX IF EXISTS idx_urls_original_url_trgm;
+DROP FUNCTION IF EXISTS url_host(TEXT);
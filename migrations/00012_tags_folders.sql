@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE folders (
+                         id BIGSERIAL PRIMARY KEY,
+                         workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+                         name TEXT NOT NULL,
+                         created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                         UNIQUE (workspace_id, name)
+);
+
+-- Deleting a folder keeps its links; they just no longer belong to a folder.
+ALTER TABLE urls ADD COLUMN folder_id BIGINT REFERENCES folders(id) ON DELETE SET NULL;
+CREATE INDEX idx_urls_folder_id ON urls(folder_id);
+
+CREATE TABLE tags (
+                      id BIGSERIAL PRIMARY KEY,
+                      workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+                      name TEXT NOT NULL,
+                      created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                      UNIQUE (workspace_id, name)
+);
+
+CREATE TABLE url_tags (
+                          url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+                          tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+                          PRIMARY KEY (url_id, tag_id)
+);
+
+-- idx_url_tags_tag_id finds the links carrying a tag, for filtering and tag analytics.
+CREATE INDEX idx_url_tags_tag_id ON url_tags(tag_id);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS url_tags;
+DROP TABLE IF EXISTS tags;
+DROP INDEX IF EXISTS idx_urls_folder_id;
+ALTER TABLE urls DROP COLUMN IF EXISTS folder_id;
+DROP TABLE IF EXISTS folders;
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE urls ADD COLUMN title TEXT NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN description TEXT NOT NULL DEFAULT '';
+-- metadata is free-form JSON attached by clients; NULL when none was given.
+ALTER TABLE urls ADD COLUMN metadata JSONB;
+-- created_by names the caller that created the link, e.g. "api_key:42" or "operator:alice";
+-- links created before it was recorded keep an empty value.
+ALTER TABLE urls ADD COLUMN created_by TEXT NOT NULL DEFAULT '';
+
+-- Link search also matches titles and descriptions.
+CREATE INDEX idx_urls_title_trgm ON urls USING gin (title gin_trgm_ops);
+CREATE INDEX idx_urls_description_trgm ON urls USING gin (description gin_trgm_ops);
+
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_urls_description_trgm;
+DROP INDEX IF EXISTS idx_urls_title_trgm;
+ALTER TABLE urls DROP COLUMN IF EXISTS created_by;
+ALTER TABLE urls DROP COLUMN IF EXISTS metadata;
+ALTER TABLE urls DROP COLUMN IF EXISTS description;
+ALTER TABLE urls DROP COLUMN IF EXISTS title;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- url_hash is the SHA-256 of the normalized destination; links with the same hash in a
+-- workspace point to the same place. Existing links are hashed as stored.
+ALTER TABLE urls ADD COLUMN url_hash BYTEA;
+UPDATE urls SET url_hash = sha256(convert_to(original_url, 'UTF8'));
+CREATE INDEX idx_urls_workspace_url_hash ON urls(workspace_id, url_hash);
+
+-- reuse_existing_links makes creating a link return an existing one for the same destination.
+ALTER TABLE workspaces ADD COLUMN reuse_existing_links BOOLEAN NOT NULL DEFAULT false;
+
+
+-- +goose Down
+ALTER TABLE workspaces DROP COLUMN IF EXISTS reuse_existing_links;
+DROP INDEX IF EXISTS idx_urls_workspace_url_hash;
+ALTER TABLE urls DROP COLUMN IF EXISTS url_hash;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- redirect_status is the HTTP status of the link's redirect; NULL follows the configured default.
+ALTER TABLE urls ADD COLUMN redirect_status SMALLINT
+    CHECK (redirect_status IN (301, 302, 307, 308));
+
+
+-- +goose Down
+ALTER TABLE urls DROP COLUMN IF EXISTS redirect_status;
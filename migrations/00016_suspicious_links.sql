@@ -0,0 +1,8 @@
+-- +goose Up
+-- Links flagged as suspicious by an operator are shown on the preview page with a warning
+-- before anyone is redirected.
+ALTER TABLE urls ADD COLUMN suspicious BOOLEAN NOT NULL DEFAULT false;
+
+
+-- +goose Down
+ALTER TABLE urls DROP COLUMN IF EXISTS suspicious;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- The source of a click tells where the visitor found the link, e.g. 'qr' for QR code scans.
+ALTER TABLE clicks ADD COLUMN source TEXT;
+
+
+-- +goose Down
+ALTER TABLE clicks DROP COLUMN IF EXISTS source;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Branded hosts a workspace serves its links on, next to the domains of the configuration.
+CREATE TABLE workspace_domains (
+                                   id BIGSERIAL PRIMARY KEY,
+                                   workspace_id BIGINT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+                                   host TEXT NOT NULL UNIQUE,
+                                   created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_workspace_domains_workspace_id ON workspace_domains(workspace_id);
+
+-- domain is the host a link is served on; '' stands for the host of the base URL.
+-- Short codes only need to be unique within a domain.
+ALTER TABLE urls ADD COLUMN domain TEXT NOT NULL DEFAULT '';
+ALTER TABLE urls DROP CONSTRAINT urls_short_code_key;
+ALTER TABLE urls ADD CONSTRAINT urls_domain_short_code_key UNIQUE (domain, short_code);
+
+
+-- +goose Down
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_domain_short_code_key;
+ALTER TABLE urls ADD CONSTRAINT urls_short_code_key UNIQUE (short_code);
+ALTER TABLE urls DROP COLUMN IF EXISTS domain;
+DROP TABLE IF EXISTS workspace_domains;
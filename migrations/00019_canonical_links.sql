@@ -0,0 +1,28 @@
+-- +goose Up
+-- canonical marks the one link of a workspace on a domain that creating a link with reuse
+-- returns for its destination; the unique index lets concurrent creations settle on it.
+-- The hashes 00014 computed from the stored destinations are not normalized: run
+-- `admin link rehash` once after this migration to rehash them and mark the canonical links again.
+-- Until then, creating a link with reuse also looks the destination up by its hash as given, so
+-- links whose stored destination matches it exactly are still reused.
+ALTER TABLE urls ADD COLUMN canonical BOOLEAN NOT NULL DEFAULT false;
+UPDATE urls u
+SET canonical = true
+WHERE u.url_hash IS NOT NULL
+  AND u.short_code IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1
+    FROM urls o
+    WHERE o.workspace_id = u.workspace_id
+      AND o.domain = u.domain
+      AND o.url_hash = u.url_hash
+      AND o.short_code IS NOT NULL
+      AND o.id < u.id
+);
+CREATE UNIQUE INDEX idx_urls_canonical_url_hash ON urls(workspace_id, domain, url_hash) WHERE canonical;
+
+
+-- +goose Down
+-- Hashes rewritten by `admin link rehash` stay normalized; reuse keeps finding those links.
+DROP INDEX IF EXISTS idx_urls_canonical_url_hash;
+ALTER TABLE urls DROP COLUMN IF EXISTS canonical;
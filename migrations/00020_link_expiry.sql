@@ -0,0 +1,12 @@
+-- +goose Up
+-- Links with expires_at stop redirecting once it has passed. expiry_notified records that
+-- link.expired was queued for the current expiry, so the sweeper announces each expiry once.
+ALTER TABLE urls ADD COLUMN expires_at TIMESTAMPTZ;
+ALTER TABLE urls ADD COLUMN expiry_notified BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX idx_urls_pending_expiry ON urls(expires_at) WHERE expires_at IS NOT NULL AND NOT expiry_notified;
+
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_urls_pending_expiry;
+ALTER TABLE urls DROP COLUMN IF EXISTS expiry_notified;
+ALTER TABLE urls DROP COLUMN IF EXISTS expires_at;
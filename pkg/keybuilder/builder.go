@@ -9,9 +9,52 @@ const (
 	redisPrefix = "shortener"
 	// The entity type we are caching.
 	urlKey = "url"
+	// urlCacheVersion is bumped whenever the cached URL representation gains fields, so entries
+	// written by older releases are never read back without them.
+	urlCacheVersion = "v4"
+	// The entity type for workspace host registrations.
+	domainKey = "domain"
+	// The entity type for live click events.
+	clicksKey = "clicks"
+	// The entity type for rate limiter state.
+	rateLimitKey = "ratelimit"
+	// defaultDomainKey stands for the domain of the base URL, which links store as "".
+	// Host names cannot contain underscores, so it never clashes with a real domain.
+	defaultDomainKey = "_"
 )
 
-// URLCacheKey builds a standardized Redis key for a URL cache entry.
-func URLCacheKey(shortCode string) string {
-	return fmt.Sprintf("%s:%s:%s", redisPrefix, urlKey, shortCode)
+// URLCacheKey builds a standardized Redis key for the URL cache entry of a short code on a domain.
+// The same short code may exist on several domains, each with an entry of its own.
+func URLCacheKey(domain, shortCode string) string {
+	if domain == "" {
+		domain = defaultDomainKey
+	}
+	return fmt.Sprintf("%s:%s:%s:%s:%s", redisPrefix, urlKey, urlCacheVersion, domain, shortCode)
+}
+
+// DomainCacheKey builds the Redis key caching the registration of a workspace host.
+func DomainCacheKey(host string) string {
+	return fmt.Sprintf("%s:%s:%s", redisPrefix, domainKey, host)
+}
+
+// ClickChannelKey builds the Redis pub/sub channel for live click events of a short code on a domain.
+func ClickChannelKey(domain, shortCode string) string {
+	if domain == "" {
+		domain = defaultDomainKey
+	}
+	return fmt.Sprintf("%s:%s:%s:%s:live", redisPrefix, clicksKey, domain, shortCode)
+}
+
+// ClickStreamKey builds the Redis stream key that buffers recent click events of a short code on a domain.
+func ClickStreamKey(domain, shortCode string) string {
+	if domain == "" {
+		domain = defaultDomainKey
+	}
+	return fmt.Sprintf("%s:%s:%s:%s:recent", redisPrefix, clicksKey, domain, shortCode)
+}
+
+// RateLimitKey builds the Redis key holding the rate limiter state of a client within a route group,
+// e.g. scope "ip" and id "203.0.113.7".
+func RateLimitKey(group, scope, id string) string {
+	return fmt.Sprintf("%s:%s:%s:%s:%s", redisPrefix, rateLimitKey, group, scope, id)
 }
@@ -0,0 +1,103 @@
+// Package urlnorm canonicalizes URLs so that addresses of the same resource compare equal.
+package urlnorm
+
+import (
+	"crypto/sha256"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// defaultPorts are the ports implied by a scheme and dropped from the host.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize lowercases the scheme and host, drops a default port and a trailing dot of the host, turns an empty path into "/"
+// and sorts the query parameters by name. Parameters matching one of strip are removed; a
+// pattern ending in "*" matches every name with that prefix, e.g. "utm_*".
+// Percent-encoding and the order of repeated parameters are kept as given.
+func Normalize(rawURL string, strip []string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+	if u.Host != "" && u.Path == "" && u.RawPath == "" {
+		u.Path = "/"
+	}
+
+	if u.RawQuery != "" {
+		u.RawQuery = normalizeQuery(u.RawQuery, strip)
+	}
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// Hash returns the SHA-256 digest of a normalized URL.
+func Hash(normalizedURL string) []byte {
+	sum := sha256.Sum256([]byte(normalizedURL))
+	return sum[:]
+}
+
+type param struct {
+	name string
+	raw  string
+}
+
+func normalizeQuery(rawQuery string, strip []string) string {
+	var params []param
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(raw, "=")
+		if decoded, err := url.QueryUnescape(name); err == nil {
+			name = decoded
+		}
+		if matches(name, strip) {
+			continue
+		}
+		params = append(params, param{name: name, raw: raw})
+	}
+
+	sort.SliceStable(params, func(i, j int) bool {
+		return params[i].name < params[j].name
+	})
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = p.raw
+	}
+	return strings.Join(parts, "&")
+}
+
+func matches(name string, patterns []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package urlnorm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tracking := []string{"utm_*", "fbclid", "GCLID"}
+	tests := []struct {
+		name  string
+		in    string
+		strip []string
+		want  string
+	}{
+		{"already normal", "https://example.com/pricing", nil, "https://example.com/pricing"},
+		{"scheme case", "HTTPS://example.com/", nil, "https://example.com/"},
+		{"host case", "https://WWW.Example.COM/Path", nil, "https://www.example.com/Path"},
+		{"trailing dot of host", "https://example.com./", nil, "https://example.com/"},
+		{"default http port", "http://example.com:80/a", nil, "http://example.com/a"},
+		{"default https port", "https://example.com:443/a", nil, "https://example.com/a"},
+		{"other port kept", "https://example.com:8443/a", nil, "https://example.com:8443/a"},
+		{"http port on https kept", "https://example.com:80/a", nil, "https://example.com:80/a"},
+		{"IPv6 host", "http://[2001:DB8::1]:80/", nil, "http://[2001:db8::1]/"},
+		{"IPv6 host with port", "http://[2001:db8::1]:8080/", nil, "http://[2001:db8::1]:8080/"},
+		{"empty path", "https://example.com", nil, "https://example.com/"},
+		{"empty path with query", "https://example.com?b=2&a=1", nil, "https://example.com/?a=1&b=2"},
+		{"trailing slash of path kept", "https://example.com/docs/", nil, "https://example.com/docs/"},
+		{"path without trailing slash kept", "https://example.com/docs", nil, "https://example.com/docs"},
+		{"query sorted", "https://example.com/?z=1&a=2&m=3", nil, "https://example.com/?a=2&m=3&z=1"},
+		{"repeated parameters keep order", "https://example.com/?b=1&a=2&b=0", nil, "https://example.com/?a=2&b=1&b=0"},
+		{"encoded names sorted decoded", "https://example.com/?%62=1&a=2", nil, "https://example.com/?a=2&%62=1"},
+		{"empty query", "https://example.com/?", nil, "https://example.com/"},
+		{"empty parameters dropped", "https://example.com/?a=1&&b=2&", nil, "https://example.com/?a=1&b=2"},
+		{"fragment kept", "https://example.com/a#Section", nil, "https://example.com/a#Section"},
+		{"percent-encoding kept", "https://example.com/a%2Fb?q=a%20b", nil, "https://example.com/a%2Fb?q=a%20b"},
+		{"tracking prefix stripped", "https://example.com/?utm_source=x&utm_medium=y&id=7", tracking, "https://example.com/?id=7"},
+		{"tracking name stripped", "https://example.com/?fbclid=abc&id=7", tracking, "https://example.com/?id=7"},
+		{"tracking match ignores case", "https://example.com/?UTM_Source=x&gclid=1&id=7", tracking, "https://example.com/?id=7"},
+		{"encoded tracking name stripped", "https://example.com/?utm%5Fsource=x&id=7", tracking, "https://example.com/?id=7"},
+		{"prefix only matches prefix", "https://example.com/?my_utm_source=x", tracking, "https://example.com/?my_utm_source=x"},
+		{"exact name is not a prefix", "https://example.com/?fbclid2=x", tracking, "https://example.com/?fbclid2=x"},
+		{"all parameters stripped", "https://example.com/a?utm_source=x", tracking, "https://example.com/a"},
+		{"nothing stripped without patterns", "https://example.com/?utm_source=x", nil, "https://example.com/?utm_source=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.in, tt.strip)
+			if err != nil {
+				t.Fatalf("Normalize(%q) failed: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			again, err := Normalize(got, tt.strip)
+			if err != nil || again != got {
+				t.Errorf("Normalize is not idempotent: %q became %q (%v)", got, again, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	if _, err := Normalize("http://exa mple.com/%zz", nil); err == nil {
+		t.Error("Normalize of an invalid URL succeeded")
+	}
+}
+
+func TestHash(t *testing.T) {
+	a, _ := Normalize("HTTPS://Example.com:443?b=2&a=1", nil)
+	b, _ := Normalize("https://example.com/?a=1&b=2", nil)
+	if !bytes.Equal(Hash(a), Hash(b)) {
+		t.Errorf("equivalent URLs hash differently: %q and %q", a, b)
+	}
+	if bytes.Equal(Hash(a), Hash("https://example.com/?a=1&b=3")) {
+		t.Error("different URLs hash the same")
+	}
+	if len(Hash(a)) != 32 {
+		t.Errorf("hash has %d bytes, want 32", len(Hash(a)))
+	}
+}
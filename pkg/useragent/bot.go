@@ -0,0 +1,30 @@
+package useragent
+
+import (
+	"strings"
+)
+
+// botMarkers are lowercase substrings that identify crawlers and automated clients.
+// Keep in sync with the backfill expression in migrations/00002_click_bot_flag.sql.
+var botMarkers = []string{
+	"bot",
+	"crawl",
+	"spider",
+	"slurp",
+	"curl",
+	"wget",
+	"python-requests",
+	"httpclient",
+	"headless",
+}
+
+// IsBot reports whether a User-Agent string belongs to a crawler or automated client.
+func IsBot(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package useragent
+
+import (
+	"strings"
+)
+
+// Info is a coarse classification of a User-Agent string.
+type Info struct {
+	Browser string
+	OS      string
+	Device  string
+	IsBot   bool
+}
+
+// rule maps a lowercase User-Agent substring to a display name.
+type rule struct {
+	marker string
+	name   string
+}
+
+// browserRules are checked in order; more specific engines must precede the ones they embed
+// (e.g. Edge and Opera include "chrome", Chrome includes "safari").
+var browserRules = []rule{
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"samsungbrowser", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios", "Firefox"},
+	{"crios", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"msie", "Internet Explorer"},
+	{"trident/", "Internet Explorer"},
+	{"curl/", "curl"},
+	{"wget/", "Wget"},
+}
+
+var osRules = []rule{
+	{"windows", "Windows"},
+	{"iphone", "iOS"},
+	{"ipad", "iOS"},
+	{"android", "Android"},
+	{"mac os x", "macOS"},
+	{"cros", "ChromeOS"},
+	{"linux", "Linux"},
+}
+
+// Parse classifies a User-Agent string by browser, operating system and device type.
+// Unrecognised values are reported as "Other".
+func Parse(userAgent string) Info {
+	ua := strings.ToLower(userAgent)
+
+	info := Info{
+		Browser: match(ua, browserRules),
+		OS:      match(ua, osRules),
+		Device:  "Desktop",
+		IsBot:   IsBot(userAgent),
+	}
+
+	switch {
+	case info.IsBot:
+		info.Device = "Bot"
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet"):
+		info.Device = "Tablet"
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone"):
+		info.Device = "Mobile"
+	}
+
+	return info
+}
+
+// match returns the name of the first rule whose marker occurs in ua.
+func match(ua string, rules []rule) string {
+	for _, r := range rules {
+		if strings.Contains(ua, r.marker) {
+			return r.name
+		}
+	}
+	return "Other"
+}
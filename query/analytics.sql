@@ -4,7 +4,8 @@ SELECT
     date_trunc(sqlc.arg(period)::text, created_at)::date AS key,
     count(*) AS value
 FROM clicks
-WHERE url_id = sqlc.arg(url_id)
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND url_id = sqlc.arg(url_id)
 GROUP BY key
 ORDER BY key DESC;
 
@@ -14,18 +15,188 @@ SELECT
     COALESCE(user_agent, 'Unknown') AS key,
     count(*) as value
 FROM clicks
-WHERE url_id = sqlc.arg(url_id)
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND url_id = sqlc.arg(url_id)
 GROUP BY key
 ORDER BY value DESC;
 
+-- name: GetClicksByPeriodAndSource :many
+-- Aggregates click counts grouped by both a time period aligned in UTC AND click source within [from_time, to_time).
+-- Clicks without a source marker are counted as 'link'.
+SELECT
+    date_trunc(sqlc.arg(period)::text, created_at, 'UTC') AS time_key,
+    COALESCE(source, 'link') AS source_key,
+    count(*) as value
+FROM clicks
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND url_id = sqlc.arg(url_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+GROUP BY time_key, source_key
+ORDER BY time_key DESC, value DESC;
+
 -- name: GetClicksByPeriodAndUserAgent :many
--- Aggregates click counts grouped by both a time period AND User-Agent.
+-- Aggregates click counts grouped by both a time period aligned in UTC AND User-Agent within [from_time, to_time).
 SELECT
-    date_trunc(sqlc.arg(period)::text, created_at)::date AS time_key,
+    date_trunc(sqlc.arg(period)::text, created_at, 'UTC') AS time_key,
     COALESCE(user_agent, 'Unknown') AS ua_key,
     count(*) as value
 FROM clicks
-WHERE url_id = sqlc.arg(url_id)
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND url_id = sqlc.arg(url_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
 GROUP BY time_key, ua_key
 ORDER BY time_key DESC, value DESC;
 
+
+-- name: GetClicksTimeSeries :many
+-- Returns a dense, zero-filled click series for a URL ID within [from_time, to_time),
+-- with one bucket per period (e.g., 'hour', 'day', 'week', 'month') aligned in UTC.
+-- The first and last buckets only count the clicks inside the range.
+WITH buckets AS (
+    SELECT bucket
+    FROM generate_series(
+        date_trunc(sqlc.arg(period)::text, sqlc.arg(from_time)::timestamptz, 'UTC') AT TIME ZONE 'UTC',
+        (sqlc.arg(to_time)::timestamptz AT TIME ZONE 'UTC') - interval '1 microsecond',
+        ('1 ' || sqlc.arg(period)::text)::interval
+    ) AS bucket
+)
+SELECT
+    (b.bucket AT TIME ZONE 'UTC')::timestamptz AS key,
+    count(c.id) AS value
+FROM buckets b
+LEFT JOIN clicks c
+    ON c.workspace_id = sqlc.arg(workspace_id)
+    AND c.url_id = sqlc.arg(url_id)
+    AND c.created_at >= GREATEST(b.bucket AT TIME ZONE 'UTC', sqlc.arg(from_time))
+    AND c.created_at < LEAST((b.bucket + ('1 ' || sqlc.arg(period)::text)::interval) AT TIME ZONE 'UTC', sqlc.arg(to_time))
+GROUP BY b.bucket
+ORDER BY b.bucket;
+
+-- name: CountClicksInRange :one
+-- Counts clicks for a given URL ID within the half-open interval [from_time, to_time).
+SELECT count(*)
+FROM clicks
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND url_id = sqlc.arg(url_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time);
+
+-- name: GetTopURLs :many
+-- Ranks the URLs of a workspace by total or unique (distinct IP) clicks within [from_time, to_time).
+SELECT
+    u.id,
+    u.original_url,
+    u.short_code,
+    u.created_at,
+    u.domain,
+    count(c.id) AS total_clicks,
+    count(DISTINCT c.ip_address) AS unique_clicks
+FROM clicks c
+JOIN urls u ON u.id = c.url_id
+WHERE c.workspace_id = sqlc.arg(workspace_id)
+  AND c.created_at >= sqlc.arg(from_time)
+  AND c.created_at < sqlc.arg(to_time)
+GROUP BY u.id
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'unique' THEN count(DISTINCT c.ip_address) ELSE count(c.id) END DESC,
+    u.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetGlobalClicksTimeSeries :many
+-- Returns a dense, zero-filled click series across all URLs of a workspace within [from_time, to_time),
+-- bucketed like GetClicksTimeSeries.
+WITH buckets AS (
+    SELECT bucket
+    FROM generate_series(
+        date_trunc(sqlc.arg(period)::text, sqlc.arg(from_time)::timestamptz, 'UTC') AT TIME ZONE 'UTC',
+        (sqlc.arg(to_time)::timestamptz AT TIME ZONE 'UTC') - interval '1 microsecond',
+        ('1 ' || sqlc.arg(period)::text)::interval
+    ) AS bucket
+)
+SELECT
+    (b.bucket AT TIME ZONE 'UTC')::timestamptz AS key,
+    count(c.id) AS value
+FROM buckets b
+LEFT JOIN clicks c
+    ON c.workspace_id = sqlc.arg(workspace_id)
+    AND c.created_at >= GREATEST(b.bucket AT TIME ZONE 'UTC', sqlc.arg(from_time))
+    AND c.created_at < LEAST((b.bucket + ('1 ' || sqlc.arg(period)::text)::interval) AT TIME ZONE 'UTC', sqlc.arg(to_time))
+GROUP BY b.bucket
+ORDER BY b.bucket;
+
+-- name: GetGlobalClickTotals :one
+-- Counts all clicks and distinct visitor IPs across all URLs of a workspace within [from_time, to_time).
+SELECT
+    count(*) AS total_clicks,
+    count(DISTINCT ip_address) AS unique_visitors
+FROM clicks
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time);
+
+-- name: GetURLTotals :one
+-- Counts URLs of a workspace created within [from_time, to_time) alongside its overall number of URLs.
+SELECT
+    count(*) FILTER (WHERE created_at >= sqlc.arg(from_time) AND created_at < sqlc.arg(to_time)) AS created_in_range,
+    count(*) AS total
+FROM urls
+WHERE workspace_id = sqlc.arg(workspace_id);
+
+-- name: GetTagClicksTimeSeries :many
+-- Returns a dense, zero-filled click series across the URLs carrying a tag within [from_time, to_time),
+-- bucketed like GetClicksTimeSeries.
+WITH buckets AS (
+    SELECT bucket
+    FROM generate_series(
+        date_trunc(sqlc.arg(period)::text, sqlc.arg(from_time)::timestamptz, 'UTC') AT TIME ZONE 'UTC',
+        (sqlc.arg(to_time)::timestamptz AT TIME ZONE 'UTC') - interval '1 microsecond',
+        ('1 ' || sqlc.arg(period)::text)::interval
+    ) AS bucket
+)
+SELECT
+    (b.bucket AT TIME ZONE 'UTC')::timestamptz AS key,
+    count(c.id) AS value
+FROM buckets b
+LEFT JOIN clicks c
+    ON c.workspace_id = sqlc.arg(workspace_id)
+    AND c.url_id IN (SELECT url_id FROM url_tags WHERE tag_id = sqlc.arg(tag_id))
+    AND c.created_at >= GREATEST(b.bucket AT TIME ZONE 'UTC', sqlc.arg(from_time))
+    AND c.created_at < LEAST((b.bucket + ('1 ' || sqlc.arg(period)::text)::interval) AT TIME ZONE 'UTC', sqlc.arg(to_time))
+GROUP BY b.bucket
+ORDER BY b.bucket;
+
+-- name: GetTagClickTotals :one
+-- Counts clicks and distinct visitor IPs across the URLs carrying a tag within [from_time, to_time).
+SELECT
+    count(*) AS total_clicks,
+    count(DISTINCT ip_address) AS unique_visitors
+FROM clicks
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND url_id IN (SELECT url_id FROM url_tags WHERE tag_id = sqlc.arg(tag_id))
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time);
+
+-- name: GetTopURLsByTag :many
+-- Ranks the URLs carrying a tag by total or unique (distinct IP) clicks within [from_time, to_time).
+SELECT
+    u.id,
+    u.original_url,
+    u.short_code,
+    u.created_at,
+    u.domain,
+    count(c.id) AS total_clicks,
+    count(DISTINCT c.ip_address) AS unique_clicks
+FROM clicks c
+JOIN urls u ON u.id = c.url_id
+JOIN url_tags ut ON ut.url_id = u.id
+WHERE c.workspace_id = sqlc.arg(workspace_id)
+  AND ut.tag_id = sqlc.arg(tag_id)
+  AND c.created_at >= sqlc.arg(from_time)
+  AND c.created_at < sqlc.arg(to_time)
+GROUP BY u.id
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'unique' THEN count(DISTINCT c.ip_address) ELSE count(c.id) END DESC,
+    u.id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,42 @@
+-- name: CreateAPIKey :one
+-- Stores a new API key. Only the SHA-256 hash of the key is persisted.
+INSERT INTO api_keys (workspace_id, user_id, name, prefix, key_hash, scope)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetActiveAPIKeyByHash :one
+-- Retrieves a key that has not been revoked by the hash of its secret, together with
+-- the current role of its user; member_role is NULL for keys without a user.
+SELECT k.*, m.role AS member_role
+FROM api_keys k
+LEFT JOIN workspace_members m ON m.workspace_id = k.workspace_id AND m.user_id = k.user_id
+WHERE k.key_hash = $1
+  AND k.revoked_at IS NULL;
+
+-- name: GetAPIKey :one
+-- Retrieves a key by its ID, including revoked ones.
+SELECT *
+FROM api_keys
+WHERE id = $1;
+
+-- name: ListAPIKeys :many
+-- Retrieves API keys, including revoked ones: those of a workspace, or all of them
+-- when workspace_id is NULL.
+SELECT *
+FROM api_keys
+WHERE sqlc.narg(workspace_id)::bigint IS NULL OR workspace_id = sqlc.narg(workspace_id)::bigint
+ORDER BY id;
+
+-- name: RecordAPIKeyUsage :exec
+-- Adds a batch of requests to the usage counters of a key.
+UPDATE api_keys
+SET request_count = request_count + sqlc.arg(requests)::bigint,
+    last_used_at = GREATEST(last_used_at, sqlc.arg(last_used_at)::timestamptz)
+WHERE id = sqlc.arg(id);
+
+-- name: RevokeAPIKey :execrows
+-- Revokes a key; it is rejected from the next request on.
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE id = $1
+  AND revoked_at IS NULL;
@@ -0,0 +1,18 @@
+-- name: CreateAuditEvent :exec
+-- Appends an event to the audit trail.
+INSERT INTO audit_events (workspace_id, actor, actor_user_id, actor_api_key_id, action, short_code, before_value, after_value, source_ip, request_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: ListAuditEvents :many
+-- Lists the audit trail of a workspace, newest first, paginated by ID.
+SELECT *
+FROM audit_events
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(short_code)::text IS NULL OR short_code = sqlc.narg(short_code))
+  AND (sqlc.narg(actor)::text IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(before_id)::bigint IS NULL OR id < sqlc.narg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,30 @@
+-- name: CreateFolder :one
+-- Creates a folder in a workspace; folder names are unique per workspace.
+INSERT INTO folders (workspace_id, name)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: ListFolders :many
+-- Retrieves the folders of a workspace with the number of links in each.
+SELECT f.*, count(u.id) AS link_count
+FROM folders f
+LEFT JOIN urls u ON u.folder_id = f.id
+WHERE f.workspace_id = $1
+GROUP BY f.id
+ORDER BY f.name;
+
+-- name: DeleteFolder :many
+-- Deletes a folder of a workspace; its links are kept outside of any folder. Returns the name of
+-- the folder with the short codes and domains of the links it held, whose folder_id is only
+-- cleared at the end of the statement. A folder without links yields a single row with NULL
+-- link columns; no row is returned when the folder does not exist.
+WITH deleted AS (
+    DELETE FROM folders
+    WHERE workspace_id = $1
+      AND id = $2
+    RETURNING id, name
+)
+SELECT d.name, u.short_code, u.domain
+FROM deleted d
+LEFT JOIN urls u ON u.folder_id = d.id
+ORDER BY u.id;
@@ -0,0 +1,59 @@
+-- name: CreateTags :exec
+-- Creates the named tags of a workspace that do not exist yet.
+INSERT INTO tags (workspace_id, name)
+SELECT sqlc.arg(workspace_id)::bigint, unnest(sqlc.arg(names)::text[])
+ON CONFLICT (workspace_id, name) DO NOTHING;
+
+-- name: DeleteURLTags :exec
+-- Removes every tag from a URL.
+DELETE FROM url_tags
+WHERE url_id = $1;
+
+-- name: AddURLTags :exec
+-- Attaches the named tags of a workspace to a URL.
+INSERT INTO url_tags (url_id, tag_id)
+SELECT sqlc.arg(url_id)::bigint, id
+FROM tags
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND name = ANY(sqlc.arg(names)::text[]);
+
+-- name: ListTagsByURLIDs :many
+-- Retrieves the tag names of the given URLs of a workspace.
+SELECT ut.url_id, t.name
+FROM url_tags ut
+JOIN tags t ON t.id = ut.tag_id
+WHERE t.workspace_id = sqlc.arg(workspace_id)
+  AND ut.url_id = ANY(sqlc.arg(url_ids)::bigint[])
+ORDER BY ut.url_id, t.name;
+
+-- name: ListTags :many
+-- Retrieves the tags of a workspace with the number of links carrying each.
+SELECT t.*, count(ut.url_id) AS link_count
+FROM tags t
+LEFT JOIN url_tags ut ON ut.tag_id = t.id
+WHERE t.workspace_id = $1
+GROUP BY t.id
+ORDER BY t.name;
+
+-- name: GetTagByName :one
+-- Retrieves a tag of a workspace by its name with the number of links carrying it.
+SELECT t.*, (SELECT count(*) FROM url_tags ut WHERE ut.tag_id = t.id) AS link_count
+FROM tags t
+WHERE t.workspace_id = $1
+  AND t.name = $2;
+
+-- name: DeleteTag :many
+-- Deletes a tag of a workspace, which removes it from its links, and returns the short codes and
+-- domains of those links as they were before. A tag without links yields a single row of NULLs;
+-- no row is returned when the tag does not exist.
+WITH deleted AS (
+    DELETE FROM tags
+    WHERE workspace_id = $1
+      AND name = $2
+    RETURNING id
+)
+SELECT u.short_code, u.domain
+FROM deleted d
+LEFT JOIN url_tags ut ON ut.tag_id = d.id
+LEFT JOIN urls u ON u.id = ut.url_id
+ORDER BY u.id;
@@ -1,29 +1,253 @@
 -- name: CreateURL :one
--- Inserts a new URL record with the original URL.
-INSERT INTO urls (original_url)
-VALUES ($1)
+-- Inserts a new URL record with the original URL, its hash, its domain, its details and its expiry into a workspace.
+-- A canonical record is not inserted, and no row is returned, when the workspace already has a
+-- canonical record with the same hash on the domain.
+INSERT INTO urls (workspace_id, original_url, title, description, metadata, created_by, url_hash, redirect_status, domain, canonical, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+ON CONFLICT (workspace_id, domain, url_hash) WHERE canonical DO NOTHING
 RETURNING *;
 
 -- name: UpdateURLShortCode :exec
 -- Updates a URL record with its generated short code.
 UPDATE urls
 SET short_code = $2
-WHERE id = $1;
+WHERE id = $1
+  AND workspace_id = $3;
+
+-- name: UpdateURL :one
+-- Changes the destination and details of a URL identified by its short code and domain within a workspace.
+-- A NULL argument keeps the current value; metadata is only written when set_metadata is true,
+-- so it can be cleared with NULL; the same holds for redirect_status and set_redirect_status, and for
+-- expires_at and set_expires_at. A new expiry is announced again once it passes.
+-- url_hash is given together with original_url; a URL whose hash changes is no longer canonical.
+UPDATE urls
+SET original_url = COALESCE(sqlc.narg(original_url), original_url),
+    url_hash = COALESCE(sqlc.narg(url_hash), url_hash),
+    canonical = canonical AND COALESCE(sqlc.narg(url_hash) = url_hash, true),
+    title = COALESCE(sqlc.narg(title), title),
+    description = COALESCE(sqlc.narg(description), description),
+    metadata = CASE WHEN sqlc.arg(set_metadata)::boolean THEN sqlc.narg(metadata)::jsonb ELSE metadata END,
+    redirect_status = CASE WHEN sqlc.arg(set_redirect_status)::boolean THEN sqlc.narg(redirect_status)::smallint ELSE redirect_status END,
+    expires_at = CASE WHEN sqlc.arg(set_expires_at)::boolean THEN sqlc.narg(expires_at)::timestamptz ELSE expires_at END,
+    expiry_notified = expiry_notified AND NOT sqlc.arg(set_expires_at)::boolean
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND short_code = sqlc.arg(short_code)
+  AND domain = sqlc.arg(domain)
+RETURNING *;
+
+-- name: UpdateURLFolder :one
+-- Moves a URL of a workspace on a domain into a folder of the same workspace, or out of any folder
+-- when folder_id is NULL. No row is updated when the folder belongs to another workspace.
+UPDATE urls
+SET folder_id = sqlc.narg(folder_id)
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND short_code = sqlc.arg(short_code)
+  AND domain = sqlc.arg(domain)
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR EXISTS (
+       SELECT 1
+       FROM folders
+       WHERE id = sqlc.narg(folder_id)
+         AND workspace_id = sqlc.arg(workspace_id)))
+RETURNING *;
+
+-- name: DeleteURLByShortCode :one
+-- Deletes a URL of a workspace on a domain (and, by cascade, its clicks) and returns the deleted record.
+DELETE FROM urls
+WHERE workspace_id = $1
+  AND short_code = $2
+  AND domain = $3
+RETURNING *;
 
 -- name: GetURLByShortCode :one
--- Retrieves a URL record by its unique short code.
+-- Retrieves a URL record of a workspace by its short code on a domain.
+SELECT *
+FROM urls
+WHERE workspace_id = $1
+  AND short_code = $2
+  AND domain = $3;
+
+-- name: GetURLByHash :one
+-- Retrieves the oldest link of a workspace on a domain whose normalized destination has the given hash.
+SELECT *
+FROM urls
+WHERE workspace_id = $1
+  AND url_hash = $2
+  AND domain = $3
+  AND short_code IS NOT NULL
+ORDER BY id
+LIMIT 1;
+
+-- name: ResolveURLByShortCode :one
+-- Retrieves a URL record by its short code on a domain regardless of its workspace.
+-- Only the public redirect may use it.
+SELECT *
+FROM urls
+WHERE domain = $1
+  AND short_code = $2;
+
+-- name: SetURLSuspicious :one
+-- Flags or unflags a URL on a domain as suspicious regardless of its workspace.
+-- Only operators may use it.
+UPDATE urls
+SET suspicious = $3
+WHERE domain = $1
+  AND short_code = $2
+RETURNING *;
+
+-- name: ListURLsAfterID :many
+-- Retrieves the URLs of all workspaces with an ID above the given one, in ID order.
+-- Only maintenance by operators may use it.
 SELECT *
 FROM urls
-WHERE short_code = $1;
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: SetURLHash :exec
+-- Replaces the destination hash of a URL regardless of its workspace; the URL is no longer canonical.
+-- Only maintenance by operators may use it.
+UPDATE urls
+SET url_hash = $2,
+    canonical = false
+WHERE id = $1;
+
+-- name: MarkCanonicalURLs :execrows
+-- Marks the oldest URL with a short code of every workspace, domain and hash without a
+-- canonical URL as canonical.
+UPDATE urls u
+SET canonical = true
+WHERE u.url_hash IS NOT NULL
+  AND u.short_code IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1
+    FROM urls o
+    WHERE o.workspace_id = u.workspace_id
+      AND o.domain = u.domain
+      AND o.url_hash = u.url_hash
+      AND (o.canonical OR (o.short_code IS NOT NULL AND o.id < u.id))
+);
+
+-- name: CreateClick :one
+-- Inserts a new click record for analytics and returns the URL's updated click count.
+-- The click inherits the workspace of its URL.
+WITH inserted AS (
+    INSERT INTO clicks (url_id, workspace_id, user_agent, ip_address, is_bot, referrer, country, source)
+    VALUES ($1, (SELECT workspace_id FROM urls WHERE id = $1), $2, $3, $4, $5, $6, $7)
+)
+UPDATE urls
+SET click_count = click_count + 1
+WHERE id = $1
+RETURNING click_count;
+
+-- name: GetRecentClicksByURLID :many
+-- Retrieves the most recent click records for a given URL, capped by a limit.
+SELECT *
+FROM clicks
+WHERE workspace_id = $1
+  AND url_id = $2
+ORDER BY created_at DESC, id DESC
+LIMIT $3;
 
--- name: CreateClick :exec
--- Inserts a new click record for analytics.
-INSERT INTO clicks (url_id, user_agent, ip_address)
-VALUES ($1, $2, $3);
+-- name: CountClicksByURLID :one
+-- Counts all click records for a given URL.
+SELECT count(*)
+FROM clicks
+WHERE workspace_id = $1
+  AND url_id = $2;
 
--- name: GetClicksByURLID :many
--- Retrieves all click records for a given URL, ordered by the most recent.
+-- name: ListClicks :many
+-- Lists click records for a given URL using keyset pagination on (created_at, id).
+-- Every filter is optional; a NULL argument disables it. user_agent matches a substring,
+-- with LIKE wildcards escaped by the caller.
 SELECT *
 FROM clicks
-WHERE url_id = $1
-ORDER BY created_at DESC;
\ No newline at end of file
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND url_id = sqlc.arg(url_id)
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(is_bot)::boolean IS NULL OR is_bot = sqlc.narg(is_bot))
+  AND (sqlc.narg(user_agent)::text IS NULL OR user_agent ILIKE '%' || sqlc.narg(user_agent) || '%' ESCAPE '\')
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListURLsByCreatedAt :many
+-- Lists the links of a workspace, newest first, using keyset pagination on (created_at, id).
+-- Every filter is optional; a NULL argument disables it. The domain filter matches the host
+-- and its subdomains; search is a substring match over the destination, short code, title
+-- and description, with LIKE wildcards escaped by the caller; tag keeps the links carrying
+-- the named tag; suspicious keeps flagged (true) or unflagged (false) links.
+SELECT *
+FROM urls
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND short_code IS NOT NULL
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(domain)::text IS NULL
+       OR url_host(original_url) = sqlc.narg(domain)
+       OR right(url_host(original_url), length(sqlc.narg(domain)) + 1) = '.' || sqlc.narg(domain))
+  AND (sqlc.narg(search)::text IS NULL
+       OR original_url ILIKE '%' || sqlc.narg(search) || '%' ESCAPE '\'
+       OR short_code ILIKE '%' || sqlc.narg(search) || '%' ESCAPE '\'
+       OR title ILIKE '%' || sqlc.narg(search) || '%' ESCAPE '\'
+       OR description ILIKE '%' || sqlc.narg(search) || '%' ESCAPE '\')
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR folder_id = sqlc.narg(folder_id))
+  AND (sqlc.narg(tag)::text IS NULL OR EXISTS (
+       SELECT 1
+       FROM url_tags ut
+       JOIN tags t ON t.id = ut.tag_id
+       WHERE ut.url_id = urls.id
+         AND t.name = sqlc.narg(tag)))
+  AND (sqlc.narg(suspicious)::boolean IS NULL OR suspicious = sqlc.narg(suspicious))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListURLsByClickCount :many
+-- Lists the links of a workspace, most clicked first, using keyset pagination on (click_count, id).
+-- The filters are the same as in ListURLsByCreatedAt.
+SELECT *
+FROM urls
+WHERE workspace_id = sqlc.arg(workspace_id)
+  AND short_code IS NOT NULL
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(domain)::text IS NULL
+       OR url_host(original_url) = sqlc.narg(domain)
+       OR right(url_host(original_url), length(sqlc.narg(domain)) + 1) = '.' || sqlc.narg(domain))
+  AND (sqlc.narg(search)::text IS NULL
+       OR original_url ILIKE '%' || sqlc.narg(search) || '%' ESCAPE '\'
+       OR short_code ILIKE '%' || sqlc.narg(search) || '%' ESCAPE '\'
+       OR title ILIKE '%' || sqlc.narg(search) || '%' ESCAPE '\'
+       OR description ILIKE '%' || sqlc.narg(search) || '%' ESCAPE '\')
+  AND (sqlc.narg(folder_id)::bigint IS NULL OR folder_id = sqlc.narg(folder_id))
+  AND (sqlc.narg(tag)::text IS NULL OR EXISTS (
+       SELECT 1
+       FROM url_tags ut
+       JOIN tags t ON t.id = ut.tag_id
+       WHERE ut.url_id = urls.id
+         AND t.name = sqlc.narg(tag)))
+  AND (sqlc.narg(suspicious)::boolean IS NULL OR suspicious = sqlc.narg(suspicious))
+  AND (sqlc.narg(cursor_click_count)::bigint IS NULL
+       OR (click_count, id) < (sqlc.narg(cursor_click_count), sqlc.narg(cursor_id)::bigint))
+ORDER BY click_count DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ClaimExpiredURLs :many
+-- Marks up to batch_size URLs of any workspace whose expiry has passed and was not announced yet
+-- as announced, and returns them. URLs claimed by a concurrent sweep are skipped.
+UPDATE urls
+SET expiry_notified = true
+WHERE id IN (
+    SELECT id
+    FROM urls
+    WHERE expires_at <= now()
+      AND NOT expiry_notified
+      AND short_code IS NOT NULL
+    ORDER BY expires_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
@@ -0,0 +1,93 @@
+-- name: CreateWebhookSubscription :one
+-- Registers a new webhook endpoint for a set of event types.
+INSERT INTO webhook_subscriptions (workspace_id, url, secret, events, click_threshold)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListWebhookSubscriptions :many
+-- Retrieves all webhook subscriptions of a workspace.
+SELECT *
+FROM webhook_subscriptions
+WHERE workspace_id = $1
+ORDER BY id;
+
+-- name: GetWebhookSubscription :one
+-- Retrieves a webhook subscription of a workspace by its ID.
+SELECT *
+FROM webhook_subscriptions
+WHERE workspace_id = $1
+  AND id = $2;
+
+-- name: DeleteWebhookSubscription :execrows
+-- Deletes a webhook subscription together with its delivery history.
+DELETE FROM webhook_subscriptions
+WHERE workspace_id = $1
+  AND id = $2;
+
+-- name: EnqueueWebhookDeliveries :execrows
+-- Creates a pending delivery for every active subscription of the workspace listening to the event type.
+INSERT INTO webhook_deliveries (subscription_id, event_type, payload)
+SELECT id, sqlc.arg(event_type)::text, sqlc.arg(payload)::jsonb
+FROM webhook_subscriptions
+WHERE active
+  AND workspace_id = sqlc.arg(workspace_id)
+  AND sqlc.arg(event_type)::text = ANY(events);
+
+-- name: EnqueueClickThresholdDeliveries :execrows
+-- Creates a pending delivery for every active subscription of the workspace whose click threshold was just reached.
+INSERT INTO webhook_deliveries (subscription_id, event_type, payload)
+SELECT id, 'link.click_threshold', sqlc.arg(payload)::jsonb
+FROM webhook_subscriptions
+WHERE active
+  AND workspace_id = sqlc.arg(workspace_id)
+  AND 'link.click_threshold' = ANY(events)
+  AND click_threshold = sqlc.arg(click_count)::bigint;
+
+-- name: ClaimDueWebhookDeliveries :many
+-- Leases a batch of due deliveries to the caller. Concurrent dispatchers skip each other's rows,
+-- and a lease that is never resolved (e.g. the process crashed) becomes due again once it expires.
+UPDATE webhook_deliveries d
+SET next_attempt_at = NOW() + sqlc.arg(lease_seconds)::int * interval '1 second',
+    attempts = d.attempts + 1,
+    updated_at = NOW()
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE status = 'pending'
+      AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+  )
+RETURNING d.id, d.subscription_id, d.event_type, d.payload, d.attempts, d.created_at, s.url, s.secret;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+-- Records a successful delivery attempt.
+UPDATE webhook_deliveries
+SET status = 'succeeded',
+    last_status_code = $2,
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+-- Records a failed delivery attempt and either schedules a retry or dead-letters the delivery.
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status),
+    last_status_code = sqlc.narg(last_status_code),
+    last_error = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id);
+
+-- name: ListWebhookDeliveries :many
+-- Lists the delivery history of a subscription, newest first, paginated by ID.
+SELECT *
+FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(before_id)::bigint IS NULL OR id < sqlc.narg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,81 @@
+-- name: CreateWorkspace :one
+-- Creates a new workspace.
+INSERT INTO workspaces (name)
+VALUES ($1)
+RETURNING *;
+
+-- name: ListWorkspaces :many
+-- Retrieves all workspaces.
+SELECT *
+FROM workspaces
+ORDER BY id;
+
+-- name: GetWorkspace :one
+-- Retrieves a workspace by its ID.
+SELECT *
+FROM workspaces
+WHERE id = $1;
+
+-- name: UpdateWorkspaceReuseExistingLinks :execrows
+-- Changes whether creating a link reuses an existing link to the same destination.
+UPDATE workspaces
+SET reuse_existing_links = $2
+WHERE id = $1;
+
+-- name: CreateUser :one
+-- Creates a new user; the email is unique across all workspaces.
+INSERT INTO users (email, name)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: AddWorkspaceMember :exec
+-- Adds a user to a workspace with a role.
+INSERT INTO workspace_members (workspace_id, user_id, role)
+VALUES ($1, $2, $3);
+
+-- name: GetWorkspaceMemberRole :one
+-- Retrieves the role of a user in a workspace.
+SELECT role
+FROM workspace_members
+WHERE workspace_id = $1
+  AND user_id = $2;
+
+-- name: UpdateWorkspaceMemberRole :execrows
+-- Changes the role of a member.
+UPDATE workspace_members
+SET role = $3
+WHERE workspace_id = $1
+  AND user_id = $2;
+
+-- name: ListWorkspaceMembers :many
+-- Retrieves the users belonging to a workspace with their roles.
+SELECT u.*, m.role
+FROM users u
+JOIN workspace_members m ON m.user_id = u.id
+WHERE m.workspace_id = $1
+ORDER BY u.id;
+
+-- name: AddWorkspaceDomain :one
+-- Registers a host on which a workspace serves its links; a host belongs to one workspace.
+INSERT INTO workspace_domains (workspace_id, host)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetWorkspaceDomain :one
+-- Retrieves the registration of a host regardless of its workspace.
+SELECT *
+FROM workspace_domains
+WHERE host = $1;
+
+-- name: ListWorkspaceDomains :many
+-- Retrieves the hosts registered for a workspace.
+SELECT *
+FROM workspace_domains
+WHERE workspace_id = $1
+ORDER BY host;
+
+-- name: DeleteWorkspaceDomain :execrows
+-- Removes a host from a workspace.
+DELETE FROM workspace_domains
+WHERE workspace_id = $1
+  AND host = $2;